@@ -0,0 +1,636 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package proviantv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ProviantServiceClient is the client API for ProviantService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProviantServiceClient interface {
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error)
+	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error)
+	UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*Product, error)
+	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListStock(ctx context.Context, in *ListStockRequest, opts ...grpc.CallOption) (*ListStockResponse, error)
+	AddStock(ctx context.Context, in *AddStockRequest, opts ...grpc.CallOption) (*StockLot, error)
+	ConsumeStock(ctx context.Context, in *ConsumeStockRequest, opts ...grpc.CallOption) (*Consumption, error)
+	DeleteStock(ctx context.Context, in *DeleteStockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// WatchStock streams stock changes of account until client cancels the call
+	WatchStock(ctx context.Context, in *WatchStockRequest, opts ...grpc.CallOption) (ProviantService_WatchStockClient, error)
+	GetShoppingList(ctx context.Context, in *GetShoppingListRequest, opts ...grpc.CallOption) (*ShoppingList, error)
+	AddShoppingListItem(ctx context.Context, in *AddShoppingListItemRequest, opts ...grpc.CallOption) (*ShoppingListItem, error)
+	UpdateShoppingListItem(ctx context.Context, in *UpdateShoppingListItemRequest, opts ...grpc.CallOption) (*ShoppingListItem, error)
+	DeleteShoppingListItem(ctx context.Context, in *DeleteShoppingListItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CheckShoppingListItem(ctx context.Context, in *CheckShoppingListItemRequest, opts ...grpc.CallOption) (*ShoppingListItem, error)
+}
+
+type proviantServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProviantServiceClient(cc grpc.ClientConnInterface) ProviantServiceClient {
+	return &proviantServiceClient{cc}
+}
+
+func (c *proviantServiceClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/GetProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/ListProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/CreateProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/UpdateProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/DeleteProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) ListStock(ctx context.Context, in *ListStockRequest, opts ...grpc.CallOption) (*ListStockResponse, error) {
+	out := new(ListStockResponse)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/ListStock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) AddStock(ctx context.Context, in *AddStockRequest, opts ...grpc.CallOption) (*StockLot, error) {
+	out := new(StockLot)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/AddStock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) ConsumeStock(ctx context.Context, in *ConsumeStockRequest, opts ...grpc.CallOption) (*Consumption, error) {
+	out := new(Consumption)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/ConsumeStock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) DeleteStock(ctx context.Context, in *DeleteStockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/DeleteStock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) WatchStock(ctx context.Context, in *WatchStockRequest, opts ...grpc.CallOption) (ProviantService_WatchStockClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ProviantService_ServiceDesc.Streams[0], "/proviant.v1.ProviantService/WatchStock", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &proviantServiceWatchStockClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ProviantService_WatchStockClient interface {
+	Recv() (*StockChange, error)
+	grpc.ClientStream
+}
+
+type proviantServiceWatchStockClient struct {
+	grpc.ClientStream
+}
+
+func (x *proviantServiceWatchStockClient) Recv() (*StockChange, error) {
+	m := new(StockChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *proviantServiceClient) GetShoppingList(ctx context.Context, in *GetShoppingListRequest, opts ...grpc.CallOption) (*ShoppingList, error) {
+	out := new(ShoppingList)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/GetShoppingList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) AddShoppingListItem(ctx context.Context, in *AddShoppingListItemRequest, opts ...grpc.CallOption) (*ShoppingListItem, error) {
+	out := new(ShoppingListItem)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/AddShoppingListItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) UpdateShoppingListItem(ctx context.Context, in *UpdateShoppingListItemRequest, opts ...grpc.CallOption) (*ShoppingListItem, error) {
+	out := new(ShoppingListItem)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/UpdateShoppingListItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) DeleteShoppingListItem(ctx context.Context, in *DeleteShoppingListItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/DeleteShoppingListItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proviantServiceClient) CheckShoppingListItem(ctx context.Context, in *CheckShoppingListItemRequest, opts ...grpc.CallOption) (*ShoppingListItem, error) {
+	out := new(ShoppingListItem)
+	err := c.cc.Invoke(ctx, "/proviant.v1.ProviantService/CheckShoppingListItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProviantServiceServer is the server API for ProviantService service.
+// All implementations must embed UnimplementedProviantServiceServer
+// for forward compatibility
+type ProviantServiceServer interface {
+	GetProduct(context.Context, *GetProductRequest) (*Product, error)
+	ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	CreateProduct(context.Context, *CreateProductRequest) (*Product, error)
+	UpdateProduct(context.Context, *UpdateProductRequest) (*Product, error)
+	DeleteProduct(context.Context, *DeleteProductRequest) (*emptypb.Empty, error)
+	ListStock(context.Context, *ListStockRequest) (*ListStockResponse, error)
+	AddStock(context.Context, *AddStockRequest) (*StockLot, error)
+	ConsumeStock(context.Context, *ConsumeStockRequest) (*Consumption, error)
+	DeleteStock(context.Context, *DeleteStockRequest) (*emptypb.Empty, error)
+	// WatchStock streams stock changes of account until client cancels the call
+	WatchStock(*WatchStockRequest, ProviantService_WatchStockServer) error
+	GetShoppingList(context.Context, *GetShoppingListRequest) (*ShoppingList, error)
+	AddShoppingListItem(context.Context, *AddShoppingListItemRequest) (*ShoppingListItem, error)
+	UpdateShoppingListItem(context.Context, *UpdateShoppingListItemRequest) (*ShoppingListItem, error)
+	DeleteShoppingListItem(context.Context, *DeleteShoppingListItemRequest) (*emptypb.Empty, error)
+	CheckShoppingListItem(context.Context, *CheckShoppingListItemRequest) (*ShoppingListItem, error)
+	mustEmbedUnimplementedProviantServiceServer()
+}
+
+// UnimplementedProviantServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedProviantServiceServer struct {
+}
+
+func (UnimplementedProviantServiceServer) GetProduct(context.Context, *GetProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
+}
+func (UnimplementedProviantServiceServer) ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProducts not implemented")
+}
+func (UnimplementedProviantServiceServer) CreateProduct(context.Context, *CreateProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProduct not implemented")
+}
+func (UnimplementedProviantServiceServer) UpdateProduct(context.Context, *UpdateProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProduct not implemented")
+}
+func (UnimplementedProviantServiceServer) DeleteProduct(context.Context, *DeleteProductRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProduct not implemented")
+}
+func (UnimplementedProviantServiceServer) ListStock(context.Context, *ListStockRequest) (*ListStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStock not implemented")
+}
+func (UnimplementedProviantServiceServer) AddStock(context.Context, *AddStockRequest) (*StockLot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddStock not implemented")
+}
+func (UnimplementedProviantServiceServer) ConsumeStock(context.Context, *ConsumeStockRequest) (*Consumption, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsumeStock not implemented")
+}
+func (UnimplementedProviantServiceServer) DeleteStock(context.Context, *DeleteStockRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteStock not implemented")
+}
+func (UnimplementedProviantServiceServer) WatchStock(*WatchStockRequest, ProviantService_WatchStockServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStock not implemented")
+}
+func (UnimplementedProviantServiceServer) GetShoppingList(context.Context, *GetShoppingListRequest) (*ShoppingList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShoppingList not implemented")
+}
+func (UnimplementedProviantServiceServer) AddShoppingListItem(context.Context, *AddShoppingListItemRequest) (*ShoppingListItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddShoppingListItem not implemented")
+}
+func (UnimplementedProviantServiceServer) UpdateShoppingListItem(context.Context, *UpdateShoppingListItemRequest) (*ShoppingListItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShoppingListItem not implemented")
+}
+func (UnimplementedProviantServiceServer) DeleteShoppingListItem(context.Context, *DeleteShoppingListItemRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteShoppingListItem not implemented")
+}
+func (UnimplementedProviantServiceServer) CheckShoppingListItem(context.Context, *CheckShoppingListItemRequest) (*ShoppingListItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckShoppingListItem not implemented")
+}
+func (UnimplementedProviantServiceServer) mustEmbedUnimplementedProviantServiceServer() {}
+
+// UnsafeProviantServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProviantServiceServer will
+// result in compilation errors.
+type UnsafeProviantServiceServer interface {
+	mustEmbedUnimplementedProviantServiceServer()
+}
+
+func RegisterProviantServiceServer(s grpc.ServiceRegistrar, srv ProviantServiceServer) {
+	s.RegisterService(&ProviantService_ServiceDesc, srv)
+}
+
+func _ProviantService_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/GetProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_ListProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).ListProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/ListProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).ListProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_CreateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).CreateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/CreateProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).CreateProduct(ctx, req.(*CreateProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_UpdateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).UpdateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/UpdateProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).UpdateProduct(ctx, req.(*UpdateProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_DeleteProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).DeleteProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/DeleteProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).DeleteProduct(ctx, req.(*DeleteProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_ListStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).ListStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/ListStock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).ListStock(ctx, req.(*ListStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_AddStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).AddStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/AddStock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).AddStock(ctx, req.(*AddStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_ConsumeStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsumeStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).ConsumeStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/ConsumeStock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).ConsumeStock(ctx, req.(*ConsumeStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_DeleteStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).DeleteStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/DeleteStock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).DeleteStock(ctx, req.(*DeleteStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_WatchStock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProviantServiceServer).WatchStock(m, &proviantServiceWatchStockServer{stream})
+}
+
+type ProviantService_WatchStockServer interface {
+	Send(*StockChange) error
+	grpc.ServerStream
+}
+
+type proviantServiceWatchStockServer struct {
+	grpc.ServerStream
+}
+
+func (x *proviantServiceWatchStockServer) Send(m *StockChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ProviantService_GetShoppingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShoppingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).GetShoppingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/GetShoppingList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).GetShoppingList(ctx, req.(*GetShoppingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_AddShoppingListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddShoppingListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).AddShoppingListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/AddShoppingListItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).AddShoppingListItem(ctx, req.(*AddShoppingListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_UpdateShoppingListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShoppingListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).UpdateShoppingListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/UpdateShoppingListItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).UpdateShoppingListItem(ctx, req.(*UpdateShoppingListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_DeleteShoppingListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteShoppingListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).DeleteShoppingListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/DeleteShoppingListItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).DeleteShoppingListItem(ctx, req.(*DeleteShoppingListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviantService_CheckShoppingListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckShoppingListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviantServiceServer).CheckShoppingListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proviant.v1.ProviantService/CheckShoppingListItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviantServiceServer).CheckShoppingListItem(ctx, req.(*CheckShoppingListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProviantService_ServiceDesc is the grpc.ServiceDesc for ProviantService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProviantService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proviant.v1.ProviantService",
+	HandlerType: (*ProviantServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProduct",
+			Handler:    _ProviantService_GetProduct_Handler,
+		},
+		{
+			MethodName: "ListProducts",
+			Handler:    _ProviantService_ListProducts_Handler,
+		},
+		{
+			MethodName: "CreateProduct",
+			Handler:    _ProviantService_CreateProduct_Handler,
+		},
+		{
+			MethodName: "UpdateProduct",
+			Handler:    _ProviantService_UpdateProduct_Handler,
+		},
+		{
+			MethodName: "DeleteProduct",
+			Handler:    _ProviantService_DeleteProduct_Handler,
+		},
+		{
+			MethodName: "ListStock",
+			Handler:    _ProviantService_ListStock_Handler,
+		},
+		{
+			MethodName: "AddStock",
+			Handler:    _ProviantService_AddStock_Handler,
+		},
+		{
+			MethodName: "ConsumeStock",
+			Handler:    _ProviantService_ConsumeStock_Handler,
+		},
+		{
+			MethodName: "DeleteStock",
+			Handler:    _ProviantService_DeleteStock_Handler,
+		},
+		{
+			MethodName: "GetShoppingList",
+			Handler:    _ProviantService_GetShoppingList_Handler,
+		},
+		{
+			MethodName: "AddShoppingListItem",
+			Handler:    _ProviantService_AddShoppingListItem_Handler,
+		},
+		{
+			MethodName: "UpdateShoppingListItem",
+			Handler:    _ProviantService_UpdateShoppingListItem_Handler,
+		},
+		{
+			MethodName: "DeleteShoppingListItem",
+			Handler:    _ProviantService_DeleteShoppingListItem_Handler,
+		},
+		{
+			MethodName: "CheckShoppingListItem",
+			Handler:    _ProviantService_CheckShoppingListItem_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStock",
+			Handler:       _ProviantService_WatchStock_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proviant/v1/proviant.proto",
+}
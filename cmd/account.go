@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/proviant-io/core/internal/pkg/service"
+	"os"
+	"strconv"
+)
+
+const accountCommandUsage = "usage: app account <export|erase> <account_id>"
+
+// runAccountCommand handles privacy requests from the command line, result is printed as json to stdout
+func runAccountCommand(args []string, accountService *service.AccountService) error {
+
+	if len(args) != 3 || args[0] != "account" {
+		return fmt.Errorf(accountCommandUsage)
+	}
+
+	accountId, err := strconv.Atoi(args[2])
+	if err != nil {
+		return fmt.Errorf("account id is not a number: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	switch args[1] {
+	case "export":
+		return encoder.Encode(accountService.Export(accountId))
+	case "erase":
+		report := accountService.Erase(accountId)
+
+		err = encoder.Encode(report)
+		if err != nil {
+			return err
+		}
+
+		if !report.Verified {
+			return fmt.Errorf("erasure of account %d is not verified, see report above", accountId)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf(accountCommandUsage)
+	}
+}
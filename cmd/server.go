@@ -6,8 +6,10 @@ import (
 	"github.com/proviant-io/core/internal/config"
 	"github.com/proviant-io/core/internal/db"
 	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/grpc"
 	"github.com/proviant-io/core/internal/http"
 	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
 	"github.com/proviant-io/core/internal/pkg/category"
 	"github.com/proviant-io/core/internal/pkg/list"
 	"github.com/proviant-io/core/internal/pkg/product"
@@ -15,7 +17,6 @@ import (
 	"github.com/proviant-io/core/internal/pkg/service"
 	"github.com/proviant-io/core/internal/pkg/stock"
 	"github.com/spf13/viper"
-	"log"
 	"os"
 )
 
@@ -25,10 +26,12 @@ var (
 
 func main() {
 
+	log := logger.Default()
+
 	err := viper.BindEnv("config")
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("cannot bind config env", "error", err)
 	}
 
 	configPath := viper.GetString("config")
@@ -39,16 +42,28 @@ func main() {
 
 	f, err := os.Open(configPath)
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("cannot open config", "path", configPath, "error", err)
 	}
 	defer f.Close()
 
 	cfg, err := config.NewConfig(f)
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("cannot parse config", "path", configPath, "error", err)
+	}
+
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		log.Fatal("invalid log config", "error", err)
 	}
 
-	log.Println(cfg)
+	log, err = logger.New(os.Stderr, level, cfg.Log.Format)
+	if err != nil {
+		log.Fatal("invalid log config", "error", err)
+	}
+
+	logger.SetDefault(log)
+
+	log.Info("config loaded", "config", cfg.Redacted())
 
 	realApm := apm.NewApm(cfg.APM)
 
@@ -58,66 +73,111 @@ func main() {
 	case config.DbDriverSqlite:
 		d, err = db.NewSQLite(cfg.Db.Dsn)
 		if err != nil {
-			log.Fatalln(err)
+			log.Fatal("cannot open database", "driver", cfg.Db.Driver, "error", err)
 		}
 	case config.DbDriverMysql:
 		d, err = db.NewMySQL(cfg.Db.Dsn)
 		if err != nil {
-			log.Fatalln(err)
+			log.Fatal("cannot open database", "driver", cfg.Db.Driver, "error", err)
 		}
 	default:
-		log.Fatalln(fmt.Sprintf("unsupported db driver: %s", cfg.Db.Driver))
+		log.Fatal("unsupported db driver", "driver", cfg.Db.Driver)
+	}
+
+	d.Connection().Logger = logger.NewGormLogger(log)
+
+	if tracer, ok := realApm.(apm.Tracer); ok {
+		err = tracer.TraceDB(d.Connection())
+		if err != nil {
+			log.Fatal("cannot trace database", "error", err)
+		}
 	}
 
 	productRepo, err := product.Setup(d)
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("migration failed", "error", err)
 	}
 
 	stockRepo, err := stock.Setup(d)
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("migration failed", "error", err)
 	}
 
 	categoryRepo, err := category.Setup(d)
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("migration failed", "error", err)
 	}
 
 	listRepo, err := list.Setup(d)
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("migration failed", "error", err)
 	}
 
 	productCategoryRepo, err := product_category.Setup(d)
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("migration failed", "error", err)
 	}
 
-	i, err := di.NewDI(d, cfg, realApm, Version)
+	i, err := di.NewDI(d, cfg, realApm, log, Version)
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("cannot setup dependencies", "error", err)
 	}
 
 	relationService := service.NewRelationService(productRepo, listRepo, categoryRepo, stockRepo, productCategoryRepo, i, *cfg)
 
+	accountService := service.NewAccountService(productRepo, listRepo, categoryRepo, stockRepo, productCategoryRepo, i)
+
+	if len(os.Args) > 1 {
+		err = runAccountCommand(os.Args[1:], accountService)
+
+		if err != nil {
+			log.Fatal("account command failed", "error", err)
+		}
+
+		return
+	}
+
+	i.Webhook.Start(relationService.EmitExpiringStock, i.Health)
+
+	relationService.StartMediaCollector()
+
+	if observer, ok := realApm.(apm.Observer); ok {
+		observer.Observe(relationService.Stats)
+	}
+
 	l := i18n.NewFileLocalizer()
 
-	server := http.NewServer(productRepo, listRepo, categoryRepo, productCategoryRepo, stockRepo, relationService, l, i)
+	server := http.NewServer(productRepo, listRepo, categoryRepo, productCategoryRepo, stockRepo, relationService, accountService, l, i)
+
+	if cfg.Grpc.Port != 0 {
+		grpcServer := grpc.NewServer(productRepo, listRepo, categoryRepo, productCategoryRepo, stockRepo, relationService, l, i)
+
+		grpcHostPort := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Grpc.Port)
+
+		log.Info("starting grpc server", "address", grpcHostPort)
+
+		go func() {
+			err := grpcServer.Run(grpcHostPort)
+
+			if err != nil {
+				log.Fatal("grpc server stopped", "error", err)
+			}
+		}()
+	}
 
 	hostPort := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 
-	log.Printf("starting server@%s\n", hostPort)
+	log.Info("starting server", "address", hostPort, "version", Version)
 
 	err = server.Run(hostPort)
 
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("server stopped", "error", err)
 	}
 }
@@ -0,0 +1,56 @@
+package apm
+
+import "sync"
+
+// Counts passes domain events to vendor which counts them, events counted inside of transaction are held back
+// until Flush, so rolled back changes are never counted
+type Counts struct {
+	counter Counter
+	parent  *Counts
+
+	mu      sync.Mutex
+	pending []count
+}
+
+type count struct {
+	metric string
+	value  float64
+}
+
+// NewCounts returns counts of vendor, events are dropped when vendor does not count them
+func NewCounts(apm Apm) *Counts {
+	counter, _ := apm.(Counter)
+	return &Counts{counter: counter}
+}
+
+func (c *Counts) Count(metric string, value float64) {
+
+	if c.parent != nil {
+		c.mu.Lock()
+		c.pending = append(c.pending, count{metric: metric, value: value})
+		c.mu.Unlock()
+		return
+	}
+
+	if c.counter != nil {
+		c.counter.Count(metric, value)
+	}
+}
+
+// Buffered returns counts which hold events back until Flush, it is used for changes made in transaction
+func (c *Counts) Buffered() *Counts {
+	return &Counts{parent: c}
+}
+
+// Flush passes held events to parent counts, it is called once transaction is committed
+func (c *Counts) Flush() {
+
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, event := range pending {
+		c.parent.Count(event.metric, event.value)
+	}
+}
@@ -0,0 +1,40 @@
+package apm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type countingApm struct {
+	NoopApm
+	counted map[string]float64
+}
+
+func (a *countingApm) Count(metric string, value float64) {
+	a.counted[metric] += value
+}
+
+func TestCountsAfterCommit(t *testing.T) {
+
+	vendor := &countingApm{counted: map[string]float64{}}
+	counts := NewCounts(vendor)
+
+	counts.Count(MetricStockAdded, 2)
+	assert.Equal(t, float64(2), vendor.counted[MetricStockAdded])
+
+	tx := counts.Buffered()
+	tx.Count(MetricStockAdded, 3)
+	tx.Count(MetricStockConsumed, 1)
+	assert.Equal(t, float64(2), vendor.counted[MetricStockAdded])
+	assert.Equal(t, float64(0), vendor.counted[MetricStockConsumed])
+
+	tx.Flush()
+	assert.Equal(t, float64(5), vendor.counted[MetricStockAdded])
+	assert.Equal(t, float64(1), vendor.counted[MetricStockConsumed])
+
+	// events of rolled back transaction are dropped with it
+	counts.Buffered().Count(MetricStockAdded, 10)
+	assert.Equal(t, float64(5), vendor.counted[MetricStockAdded])
+
+	NewCounts(&NoopApm{}).Count(MetricStockAdded, 1)
+}
@@ -0,0 +1,18 @@
+package apm
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+// routeTemplate returns full template of matched route, the same pattern is registered under several prefixes
+func routeTemplate(r *http.Request, pattern string) string {
+
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return pattern
+}
@@ -0,0 +1,32 @@
+package apm
+
+import "net/http"
+
+// domain events counted by vendors which implement Counter
+const (
+	MetricStockAdded    = "stock_added"
+	MetricStockConsumed = "stock_consumed"
+)
+
+// Counter is implemented by vendors which count domain events, value is e.g. quantity of added stock
+type Counter interface {
+	Count(metric string, value float64)
+}
+
+// Stats is state of storage, it is sampled whenever metrics are collected
+type Stats struct {
+	ProductsPerAccount     map[int]int64
+	ExpiringLotsPerAccount map[int]int64
+	StorageBytes           int64
+}
+
+// Observer is implemented by vendors which sample state of storage, e.g. on every prometheus scrape
+type Observer interface {
+	Observe(stats func() Stats)
+}
+
+// Exporter is implemented by vendors which serve collected metrics themselves
+type Exporter interface {
+	MetricsPath() string
+	MetricsHandler() http.Handler
+}
@@ -3,7 +3,7 @@ package apm
 import (
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/proviant-io/core/internal/config"
-	"log"
+	"github.com/proviant-io/core/internal/logger"
 	"net/http"
 )
 
@@ -12,8 +12,13 @@ type Apm interface {
 }
 
 func NewApm(cfg config.APM) Apm{
-	if cfg.Vendor == "newrelic"{
+	switch cfg.Vendor {
+	case config.ApmVendorNewRelic:
 		return newNewRelic(cfg.ApplicationName, cfg.LicenseKey)
+	case config.ApmVendorPrometheus:
+		return newPrometheus(cfg.MetricsPath)
+	case config.ApmVendorOpenTelemetry:
+		return newOpenTelemetry(cfg.ApplicationName, cfg.OtlpEndpoint, cfg.OtlpInsecure)
 	}
 	return newNoop()
 }
@@ -45,7 +50,7 @@ func newNewRelic(name, key string) Apm {
 	)
 
 	if err != nil {
-		log.Printf("error in apm setup: %s", err.Error())
+		logger.Default().Error("error in apm setup", "error", err)
 		return nil
 	}
 
@@ -0,0 +1,193 @@
+package apm
+
+import (
+	"context"
+	"fmt"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+const DefaultOtlpEndpoint = "localhost:4318"
+
+const tracerName = "github.com/proviant-io/core"
+
+// key span of query is kept under while it runs
+const gormSpanKey = "apm:span"
+
+// OpenTelemetryApm traces requests, service methods, queries and storage calls, spans are exported over otlp
+type OpenTelemetryApm struct {
+	provider   trace.TracerProvider
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func newOpenTelemetry(name, endpoint string, insecure bool) Apm {
+
+	if endpoint == "" {
+		endpoint = DefaultOtlpEndpoint
+	}
+
+	if name == "" {
+		name = "proviant"
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+
+	if err != nil {
+		logger.Default().Error("error in apm setup", "error", err)
+		return nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", name)))
+
+	if err != nil {
+		logger.Default().Error("error in apm setup", "error", err)
+		return nil
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	return newOpenTelemetryWithProvider(provider)
+}
+
+func newOpenTelemetryWithProvider(provider trace.TracerProvider) *OpenTelemetryApm {
+	return &OpenTelemetryApm{
+		provider:   provider,
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+}
+
+func (o *OpenTelemetryApm) WrapHandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) (string, func(http.ResponseWriter, *http.Request)) {
+	return pattern, func(w http.ResponseWriter, r *http.Request) {
+
+		route := routeTemplate(r, pattern)
+
+		// span continues trace of caller when request carries its context
+		ctx := o.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := o.tracer.Start(ctx, fmt.Sprintf("%s %s", r.Method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.target", r.URL.RequestURI()),
+			),
+		)
+		defer span.End()
+
+		recorder := utils.NewStatusWriter(w)
+
+		handler(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.status_code", recorder.Status))
+
+		if recorder.Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.Status))
+		}
+	}
+}
+
+func (o *OpenTelemetryApm) Start(ctx context.Context, name string) (context.Context, func()) {
+
+	ctx, span := o.tracer.Start(ctx, name)
+
+	return ctx, func() {
+		span.End()
+	}
+}
+
+func (o *OpenTelemetryApm) TraceDB(c *gorm.DB) error {
+
+	callbacks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", c.Callback().Create().Before("gorm:create").Register, c.Callback().Create().After("gorm:create").Register},
+		{"query", c.Callback().Query().Before("gorm:query").Register, c.Callback().Query().After("gorm:query").Register},
+		{"update", c.Callback().Update().Before("gorm:update").Register, c.Callback().Update().After("gorm:update").Register},
+		{"delete", c.Callback().Delete().Before("gorm:delete").Register, c.Callback().Delete().After("gorm:delete").Register},
+		{"row", c.Callback().Row().Before("gorm:row").Register, c.Callback().Row().After("gorm:row").Register},
+		{"raw", c.Callback().Raw().Before("gorm:raw").Register, c.Callback().Raw().After("gorm:raw").Register},
+	}
+
+	for _, cb := range callbacks {
+
+		err := cb.before("apm:before_"+cb.operation, o.beforeQuery(cb.operation))
+		if err != nil {
+			return err
+		}
+
+		err = cb.after("apm:after_"+cb.operation, o.afterQuery)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *OpenTelemetryApm) beforeQuery(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+
+		ctx := tx.Statement.Context
+
+		// background work is not traced, otherwise every poll would start trace of its own
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+
+		_, span := o.tracer.Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.operation", operation)),
+		)
+
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (o *OpenTelemetryApm) afterQuery(tx *gorm.DB) {
+
+	value, ok := tx.InstanceGet(gormSpanKey)
+
+	if !ok {
+		return
+	}
+
+	span, ok := value.(trace.Span)
+
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("db.statement", tx.Statement.SQL.String()),
+		attribute.String("db.sql.table", tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.RowsAffected),
+	)
+
+	if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+
+	span.End()
+}
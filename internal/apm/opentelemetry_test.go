@@ -0,0 +1,82 @@
+package apm
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOpenTelemetryApm(t *testing.T) {
+
+	recorder := tracetest.NewSpanRecorder()
+	o := newOpenTelemetryWithProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	d, err := db.NewSQLite(":memory:")
+	assert.NoError(t, err)
+	assert.NoError(t, o.TraceDB(d.Connection()))
+
+	router := mux.NewRouter()
+	router.HandleFunc(o.WrapHandleFunc("/product/{id}/", func(w http.ResponseWriter, r *http.Request) {
+		ctx, end := o.Start(r.Context(), "RelationService.GetProduct")
+		defer end()
+
+		var count int64
+		db.WithContext(d, ctx).Connection().Raw("select 1").Scan(&count)
+
+		// queries outside of traced request are not spans
+		d.Connection().Raw("select 1").Scan(&count)
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/product/7/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+
+	query, method, request := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "gorm.row", query.Name())
+	assert.Equal(t, "RelationService.GetProduct", method.Name())
+	assert.Equal(t, "GET /product/{id}/", request.Name())
+
+	// trace of caller is continued
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", request.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent().SpanID().String())
+	assert.Equal(t, request.SpanContext().SpanID(), method.Parent().SpanID())
+	assert.Equal(t, method.SpanContext().SpanID(), query.Parent().SpanID())
+}
+
+func TestOpenTelemetryExport(t *testing.T) {
+
+	var exported int32
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			atomic.AddInt32(&exported, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	o := newOpenTelemetry("proviant-test", strings.TrimPrefix(collector.URL, "http://"), true).(*OpenTelemetryApm)
+
+	_, end := o.Start(context.Background(), "RelationService.GetProduct")
+	end()
+
+	provider := o.provider.(*sdktrace.TracerProvider)
+	assert.NoError(t, provider.Shutdown(context.Background()))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&exported))
+}
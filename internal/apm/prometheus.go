@@ -0,0 +1,141 @@
+package apm
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/proviant-io/core/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const DefaultMetricsPath = "/metrics"
+
+const metricsNamespace = "proviant"
+
+// PrometheusApm collects metrics of requests and domain, they are scraped from MetricsPath
+type PrometheusApm struct {
+	registry *prometheus.Registry
+	path     string
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	counters map[string]prometheus.Counter
+}
+
+func newPrometheus(path string) Apm {
+
+	if path == "" {
+		path = DefaultMetricsPath
+	}
+
+	p := &PrometheusApm{
+		registry: prometheus.NewRegistry(),
+		path:     path,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Number of handled requests by route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of handled requests by route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		counters: map[string]prometheus.Counter{
+			MetricStockAdded: prometheus.NewCounter(prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "stock_added_total",
+				Help:      "Quantity of added stock.",
+			}),
+			MetricStockConsumed: prometheus.NewCounter(prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "stock_consumed_total",
+				Help:      "Quantity of consumed stock.",
+			}),
+		},
+	}
+
+	p.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		p.requests,
+		p.latency,
+	)
+
+	for _, c := range p.counters {
+		p.registry.MustRegister(c)
+	}
+
+	return p
+}
+
+func (p *PrometheusApm) WrapHandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) (string, func(http.ResponseWriter, *http.Request)) {
+	return pattern, func(w http.ResponseWriter, r *http.Request) {
+
+		route := routeTemplate(r, pattern)
+		recorder := utils.NewStatusWriter(w)
+		start := time.Now()
+
+		handler(recorder, r)
+
+		p.latency.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		p.requests.WithLabelValues(r.Method, route, strconv.Itoa(recorder.Status)).Inc()
+	}
+}
+
+func (p *PrometheusApm) Count(metric string, value float64) {
+	if c, ok := p.counters[metric]; ok {
+		c.Add(value)
+	}
+}
+
+func (p *PrometheusApm) Observe(stats func() Stats) {
+	p.registry.MustRegister(newStatsCollector(stats))
+}
+
+func (p *PrometheusApm) MetricsPath() string {
+	return p.path
+}
+
+func (p *PrometheusApm) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+// statsCollector samples storage on every scrape, so gauges are never stale
+type statsCollector struct {
+	stats        func() Stats
+	products     *prometheus.Desc
+	expiringLots *prometheus.Desc
+	storageBytes *prometheus.Desc
+}
+
+func newStatsCollector(stats func() Stats) *statsCollector {
+	return &statsCollector{
+		stats:        stats,
+		products:     prometheus.NewDesc(metricsNamespace+"_products", "Number of products by account.", []string{"account_id"}, nil),
+		expiringLots: prometheus.NewDesc(metricsNamespace+"_expiring_lots", "Number of stock lots which are expired or expire soon by account.", []string{"account_id"}, nil),
+		storageBytes: prometheus.NewDesc(metricsNamespace+"_storage_bytes", "Size of stored user content, images with their variants and attachments.", nil, nil),
+	}
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.products
+	ch <- c.expiringLots
+	ch <- c.storageBytes
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+
+	stats := c.stats()
+
+	for accountId, count := range stats.ProductsPerAccount {
+		ch <- prometheus.MustNewConstMetric(c.products, prometheus.GaugeValue, float64(count), strconv.Itoa(accountId))
+	}
+
+	for accountId, count := range stats.ExpiringLotsPerAccount {
+		ch <- prometheus.MustNewConstMetric(c.expiringLots, prometheus.GaugeValue, float64(count), strconv.Itoa(accountId))
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.storageBytes, prometheus.GaugeValue, float64(stats.StorageBytes))
+}
@@ -0,0 +1,45 @@
+package apm
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPrometheusApm(t *testing.T) {
+
+	p := newPrometheus("").(*PrometheusApm)
+
+	router := mux.NewRouter()
+	router.HandleFunc(p.WrapHandleFunc("/product/{id}/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	router.Handle(p.MetricsPath(), p.MetricsHandler())
+
+	p.Observe(func() Stats {
+		return Stats{
+			ProductsPerAccount:     map[int]int64{1: 3},
+			ExpiringLotsPerAccount: map[int]int64{1: 2},
+			StorageBytes:           1024,
+		}
+	})
+	p.Count(MetricStockAdded, 5)
+	p.Count("unknown", 1)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/product/7/", nil))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DefaultMetricsPath, nil))
+
+	body, _ := ioutil.ReadAll(recorder.Body)
+
+	assert.Contains(t, string(body), `proviant_http_requests_total{method="GET",route="/product/{id}/",status="404"} 1`)
+	assert.Contains(t, string(body), `proviant_http_request_duration_seconds_count{method="GET",route="/product/{id}/"} 1`)
+	assert.Contains(t, string(body), `proviant_stock_added_total 5`)
+	assert.Contains(t, string(body), `proviant_products{account_id="1"} 3`)
+	assert.Contains(t, string(body), `proviant_expiring_lots{account_id="1"} 2`)
+	assert.Contains(t, string(body), `proviant_storage_bytes 1024`)
+}
@@ -0,0 +1,14 @@
+package apm
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// Tracer is implemented by vendors which trace requests
+type Tracer interface {
+	// Start begins span which is child of span in ctx, returned function ends it
+	Start(ctx context.Context, name string) (context.Context, func())
+	// TraceDB makes queries run with traced context child spans
+	TraceDB(c *gorm.DB) error
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"github.com/proviant-io/core/internal/logger"
 	"gopkg.in/yaml.v3"
 	"io"
+	"strings"
 )
 
 type DB struct {
@@ -13,10 +15,17 @@ type DB struct {
 type UserContent struct {
 	Mode     string `yaml:"mode"`
 	Location string `yaml:"location"`
+	// MediaGraceHours is how long media nobody refers to is kept before it is deleted, 24 hours by default
+	MediaGraceHours int `yaml:"media_grace_hours"`
+	// SigningKey enables signed urls which give access to images without account, e.g. for img tags
+	SigningKey string `yaml:"signing_key"`
+	// SignedUrlExpiryMinutes is how long signed url is valid, 60 minutes by default
+	SignedUrlExpiryMinutes int `yaml:"signed_url_expiry_minutes"`
 }
 
 type API struct {
 	GCS GCS
+	S3  S3
 }
 
 type GCS struct {
@@ -25,6 +34,20 @@ type GCS struct {
 	ProjectId          string `yaml:"project_id"`
 }
 
+// S3 configures any S3 compatible storage, e.g. AWS S3 or MinIO. Credentials may be left empty,
+// then they are taken from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, ~/.aws/credentials or instance role.
+type S3 struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	BucketName      string `yaml:"bucket_name"`
+	AccessKeyId     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	UseSSL          bool   `yaml:"use_ssl"`
+	PathStyle       bool   `yaml:"path_style"`
+	// images are served by redirect to presigned url when set, 0 proxies them through server
+	PresignExpiryMinutes int `yaml:"presign_expiry_minutes"`
+}
+
 type Server struct {
 	Port int    `yaml:"port"`
 	Host string `yaml:"host"`
@@ -37,12 +60,64 @@ type Config struct {
 	UserContent UserContent `yaml:"user_content"`
 	API         API         `yaml:"api"`
 	APM         APM         `yaml:"apm"`
+	Admin       Admin       `yaml:"admin"`
+	RateLimit   RateLimit   `yaml:"rate_limit"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	OpenApi     OpenApi     `yaml:"openapi"`
+	Grpc        Grpc        `yaml:"grpc"`
+	Log         Log         `yaml:"log"`
 }
 
 type APM struct {
 	Vendor          string `yaml:"vendor"`
 	LicenseKey      string `yaml:"license_key"`
 	ApplicationName string `yaml:"application_name"`
+	// MetricsPath is where prometheus scrapes metrics from, /metrics by default
+	MetricsPath string `yaml:"metrics_path"`
+	// OtlpEndpoint is host:port of collector opentelemetry traces are sent to over http, localhost:4318 by default
+	OtlpEndpoint string `yaml:"otlp_endpoint"`
+	OtlpInsecure bool   `yaml:"otlp_insecure"`
+}
+
+// Admin protects maintenance endpoints, they are disabled while token is empty
+type Admin struct {
+	Token string `yaml:"token"`
+}
+
+type RateLimit struct {
+	Enabled           bool   `yaml:"enabled"`
+	Backend           string `yaml:"backend"`
+	TrustForwardedFor bool   `yaml:"trust_forwarded_for"`
+	Read              Limit  `yaml:"read"`
+	Write             Limit  `yaml:"write"`
+	Image             Limit  `yaml:"image"`
+}
+
+// Limit is token bucket budget, rate is amount of requests per second
+type Limit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// Idempotency controls how long responses of requests sent with Idempotency-Key are kept, 24 hours by default
+type Idempotency struct {
+	RetentionHours int `yaml:"retention_hours"`
+}
+
+// OpenApi enables checks of api v1 requests and responses against specification, it is meant for development
+type OpenApi struct {
+	Validate bool `yaml:"validate"`
+}
+
+// Grpc serves api on its own port next to http server, it is disabled while port is 0
+type Grpc struct {
+	Port int `yaml:"port"`
+}
+
+// Log configures logger, level is debug, info (default), warn or error, format is text (default) or json
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
 }
 
 const DbDriverSqlite = "sqlite"
@@ -55,6 +130,62 @@ const UserContentModeLocal = "local"
 const UserContentModeS3 = "s3"
 const UserContentModeGCS = "gcs"
 
+const ApmVendorNewRelic = "newrelic"
+const ApmVendorPrometheus = "prometheus"
+const ApmVendorOpenTelemetry = "opentelemetry"
+
+// Redacted returns copy of config which is safe to log, secrets are replaced
+func (c Config) Redacted() Config {
+
+	c.Db.Dsn = redactDsn(c.Db.Dsn)
+	c.UserContent.SigningKey = redact(c.UserContent.SigningKey)
+	c.API.S3.AccessKeyId = redact(c.API.S3.AccessKeyId)
+	c.API.S3.SecretAccessKey = redact(c.API.S3.SecretAccessKey)
+	c.APM.LicenseKey = redact(c.APM.LicenseKey)
+	c.Admin.Token = redact(c.Admin.Token)
+
+	return c
+}
+
+func redact(secret string) string {
+
+	if secret == "" {
+		return ""
+	}
+
+	return logger.Redacted
+}
+
+// redactDsn hides password of user:password@tcp(host)/db dsn and sensitive query params, sqlite path is kept
+func redactDsn(dsn string) string {
+
+	if q := strings.Index(dsn, "?"); q != -1 {
+		params := strings.Split(dsn[q+1:], "&")
+
+		for i, param := range params {
+			if eq := strings.Index(param, "="); eq != -1 && logger.Sensitive(param[:eq]) {
+				params[i] = param[:eq+1] + logger.Redacted
+			}
+		}
+
+		dsn = dsn[:q+1] + strings.Join(params, "&")
+	}
+
+	at := strings.LastIndex(dsn, "@")
+
+	if at == -1 {
+		return dsn
+	}
+
+	colon := strings.Index(dsn[:at], ":")
+
+	if colon == -1 {
+		return dsn
+	}
+
+	return dsn[:colon+1] + logger.Redacted + dsn[at:]
+}
+
 func NewConfig(r io.Reader) (*Config, error) {
 
 	cfg := &Config{}
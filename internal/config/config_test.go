@@ -25,10 +25,20 @@ api:
     json_credential_path: "/app/gcs-creds.json"
     bucket_name: "bucket-name"
     project_id: "project-id"
+  s3:
+    endpoint: "localhost:9000"
+    region: "eu-central-1"
+    bucket_name: "proviant"
+    access_key_id: "key"
+    secret_access_key: "secret"
+    path_style: true
+    presign_expiry_minutes: 15
 apm:
   vendor: "newrelic"
   license_key: "1234"
   application_name: "proviant/core"
+admin:
+  token: "secret"
 `
 
 	reader := strings.NewReader(content)
@@ -57,13 +67,56 @@ apm:
 				ProjectId:          "project-id",
 				JsonCredentialPath: "/app/gcs-creds.json",
 			},
+			S3: S3{
+				Endpoint:             "localhost:9000",
+				Region:               "eu-central-1",
+				BucketName:           "proviant",
+				AccessKeyId:          "key",
+				SecretAccessKey:      "secret",
+				PathStyle:            true,
+				PresignExpiryMinutes: 15,
+			},
 		},
 		APM: APM{
 			Vendor:          "newrelic",
 			LicenseKey:      "1234",
 			ApplicationName: "proviant/core",
 		},
+		Admin: Admin{
+			Token: "secret",
+		},
 	}
 
 	assert.Equal(t, expected, *actual)
 }
+
+func TestRedacted(t *testing.T) {
+
+	cfg := Config{
+		Db: DB{
+			Driver: "mysql",
+			Dsn:    "root:proviant@tcp(db:3306)/proviant?parseTime=true",
+		},
+		API: API{
+			S3: S3{
+				Endpoint:        "localhost:9000",
+				SecretAccessKey: "secret",
+			},
+		},
+		Admin: Admin{
+			Token: "token",
+		},
+	}
+
+	redacted := cfg.Redacted()
+
+	assert.Equal(t, "root:[redacted]@tcp(db:3306)/proviant?parseTime=true", redacted.Db.Dsn)
+	assert.Equal(t, "localhost:9000", redacted.API.S3.Endpoint)
+	assert.Equal(t, "[redacted]", redacted.API.S3.SecretAccessKey)
+	assert.Equal(t, "", redacted.API.S3.AccessKeyId)
+	assert.Equal(t, "[redacted]", redacted.Admin.Token)
+	assert.Equal(t, "token", cfg.Admin.Token)
+
+	assert.Equal(t, "/path/to/file.sqlite", redactDsn("/path/to/file.sqlite"))
+	assert.Equal(t, "file:db.sqlite?_auth&_auth_pwd=[redacted]&cache=shared", redactDsn("file:db.sqlite?_auth&_auth_pwd=p@ss&cache=shared"))
+}
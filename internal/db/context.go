@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// Ctx is connection whose queries belong to context, e.g. to span of traced request
+type Ctx struct {
+	d   DB
+	ctx context.Context
+}
+
+func (c *Ctx) Connection() *gorm.DB {
+	return c.d.Connection().WithContext(c.ctx)
+}
+
+// WithContext returns connection which runs queries of d with ctx
+func WithContext(d DB, ctx context.Context) DB {
+	return &Ctx{d: d, ctx: ctx}
+}
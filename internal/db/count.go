@@ -0,0 +1,22 @@
+package db
+
+import "gorm.io/gorm"
+
+// CountPerAccount groups rows matched by query by account, accounts without rows are missing
+func CountPerAccount(query *gorm.DB) map[int]int64 {
+
+	var rows []struct {
+		AccountId int
+		Count     int64
+	}
+
+	query.Select("account_id, count(*) as count").Group("account_id").Scan(&rows)
+
+	counts := map[int]int64{}
+
+	for _, row := range rows {
+		counts[row.AccountId] = row.Count
+	}
+
+	return counts
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gorm.io/gorm"
+	"sync"
+)
+
+// migrated models per connection pool, sessions of connection share it
+var migrated = struct {
+	sync.Mutex
+	models map[*sql.DB][]interface{}
+}{models: map[*sql.DB][]interface{}{}}
+
+// AutoMigrate migrates schema of model and remembers it, so Migrated can tell later whether schema is still in place
+func AutoMigrate(d DB, model interface{}) error {
+
+	c := d.Connection()
+
+	err := c.AutoMigrate(model)
+	if err != nil {
+		return err
+	}
+
+	pool, err := c.DB()
+	if err != nil {
+		return err
+	}
+
+	migrated.Lock()
+	defer migrated.Unlock()
+
+	migrated.models[pool] = append(migrated.models[pool], model)
+
+	return nil
+}
+
+// Migrated checks that table and every column of migrated models exist, e.g. database was not restored from
+// backup made by older version. Catalog is asked for every column, so it is meant to run once at startup.
+func Migrated(ctx context.Context, d DB) error {
+
+	c := d.Connection().WithContext(ctx)
+
+	pool, err := c.DB()
+	if err != nil {
+		return err
+	}
+
+	migrated.Lock()
+	models := migrated.models[pool]
+	migrated.Unlock()
+
+	if len(models) == 0 {
+		return fmt.Errorf("schema is not migrated")
+	}
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: c}
+
+		err := stmt.Parse(model)
+		if err != nil {
+			return err
+		}
+
+		if !c.Migrator().HasTable(model) {
+			return fmt.Errorf("table %s is missing", stmt.Schema.Table)
+		}
+
+		// catalog is asked column by column, schema cached by connection may be stale
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !c.Migrator().HasColumn(model, field.DBName) {
+				return fmt.Errorf("column %s.%s is missing", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Ping checks that database answers
+func Ping(ctx context.Context, d DB) error {
+
+	sqlDB, err := d.Connection().DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
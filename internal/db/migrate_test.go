@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type migrateModel struct {
+	gorm.Model
+	Title string
+	Notes string
+}
+
+func TestMigrated(t *testing.T) {
+
+	d, err := NewSQLite(":memory:")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	assert.NoError(t, Ping(ctx, d))
+	assert.EqualError(t, Migrated(ctx, d), "schema is not migrated")
+
+	assert.NoError(t, AutoMigrate(d, &migrateModel{}))
+	assert.NoError(t, Migrated(ctx, WithContext(d, ctx)))
+
+	assert.NoError(t, d.Connection().Migrator().DropColumn(&migrateModel{}, "Notes"))
+	assert.EqualError(t, Migrated(ctx, d), "column migrate_models.notes is missing")
+
+	assert.NoError(t, d.Connection().Migrator().DropTable(&migrateModel{}))
+	assert.EqualError(t, Migrated(ctx, d), "table migrate_models is missing")
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"gorm.io/gorm"
+)
+
+// Tx is connection bound to transaction, repositories which work through it take part in transaction
+type Tx struct {
+	c *gorm.DB
+}
+
+func (d *Tx) Connection() *gorm.DB {
+	return d.c
+}
+
+// Transaction runs f in transaction, it is committed when f returns nil and rolled back otherwise
+func Transaction(d DB, f func(tx DB) error) error {
+	return d.Connection().Transaction(func(c *gorm.DB) error {
+		return f(&Tx{c: c})
+	})
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"gorm.io/gorm"
+)
+
+// VersionColumn is incremented on every update, it backs ETag / If-Match optimistic locking
+const VersionColumn = "version"
+
+// UpdateVersioned updates row only while its version equals expected one (0 skips the check) and bumps the version
+// in the same statement. It returns false when no row matched, which means row was changed concurrently.
+func UpdateVersioned(c *gorm.DB, model interface{}, id, accountId, expected int, values map[string]interface{}) bool {
+
+	q := c.Model(model).Where("id = ? and account_id = ?", id, accountId)
+
+	if expected != 0 {
+		q = q.Where(VersionColumn+" = ?", expected)
+	}
+
+	values[VersionColumn] = gorm.Expr(VersionColumn + " + 1")
+
+	result := q.Updates(values)
+
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// DeleteVersioned permanently deletes row only while its version equals expected one (0 skips the check)
+func DeleteVersioned(c *gorm.DB, model interface{}, id, accountId, expected int) bool {
+
+	q := c.Unscoped().Where("id = ? and account_id = ?", id, accountId)
+
+	if expected != 0 {
+		q = q.Where(VersionColumn+" = ?", expected)
+	}
+
+	result := q.Delete(model)
+
+	return result.Error == nil && result.RowsAffected == 1
+}
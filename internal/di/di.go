@@ -7,29 +7,60 @@ import (
 	"github.com/proviant-io/core/internal/apm"
 	"github.com/proviant-io/core/internal/config"
 	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/health"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/pkg/attachment"
+	"github.com/proviant-io/core/internal/pkg/audit"
 	"github.com/proviant-io/core/internal/pkg/consumption"
+	"github.com/proviant-io/core/internal/pkg/gallery"
+	"github.com/proviant-io/core/internal/pkg/idempotency"
 	"github.com/proviant-io/core/internal/pkg/image"
+	"github.com/proviant-io/core/internal/pkg/media"
 	"github.com/proviant-io/core/internal/pkg/shopping"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/proviant-io/core/internal/pkg/webhook"
+	"github.com/proviant-io/core/internal/ratelimit"
 	"os"
+	"time"
 )
 
 type DI struct {
+	DB           db.DB
 	Cfg          *config.Config
 	Version      string
 	ImageSaver   image.Saver
 	Apm          apm.Apm
 	ShoppingList *shopping.ListRepository
 	ShoppingListItem *shopping.ItemRepository
+	ShoppingListEvent *shopping.EventRepository
 	ConsumptionLog *consumption.LogRepository
+	Audit          *audit.Repository
+	RateLimiter    ratelimit.Limiter
+	Webhook        *webhook.Dispatcher
+	Idempotency    *idempotency.Repository
+	StockWatcher   *stock.Watcher
+	Media          *media.Repository
+	MediaSigner    *media.Signer
+	Gallery        *gallery.Repository
+	Attachment     *attachment.Repository
+	Logger         *logger.Logger
+	Health         *health.Registry
+	Counts         *apm.Counts
+	// SchemaErr is result of schema check made once everything was migrated, catalog is too slow to ask per probe
+	SchemaErr error
 }
 
-func NewDI(d db.DB, cfg *config.Config, apm apm.Apm, version string) (*DI, error) {
+func NewDI(d db.DB, cfg *config.Config, a apm.Apm, l *logger.Logger, version string) (*DI, error) {
 
 	pool := &DI{}
 
+	pool.DB = d
 	pool.Cfg = cfg
 	pool.Version = version
-	pool.Apm = apm
+	pool.Apm = a
+	pool.Logger = l
+	pool.Health = health.NewRegistry()
+	pool.Counts = apm.NewCounts(a)
 
 	shoppingListRepo, err := shopping.ListSetup(d)
 
@@ -47,6 +78,14 @@ func NewDI(d db.DB, cfg *config.Config, apm apm.Apm, version string) (*DI, error
 
 	pool.ShoppingListItem = shoppingListItemRepo
 
+	shoppingListEventRepo, err := shopping.EventSetup(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool.ShoppingListEvent = shoppingListEventRepo
+
 	consumptionLogRepo, err := consumption.LogSetup(d)
 
 	if err != nil {
@@ -55,6 +94,77 @@ func NewDI(d db.DB, cfg *config.Config, apm apm.Apm, version string) (*DI, error
 
 	pool.ConsumptionLog = consumptionLogRepo
 
+	auditRepo, err := audit.Setup(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Audit = auditRepo
+
+	webhookSubscriptionRepo, err := webhook.SubscriptionSetup(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	webhookDeliveryRepo, err := webhook.DeliverySetup(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Webhook = webhook.NewDispatcher(webhookSubscriptionRepo, webhookDeliveryRepo)
+
+	idempotencyRepo, err := idempotency.Setup(d, time.Duration(cfg.Idempotency.RetentionHours)*time.Hour)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Idempotency = idempotencyRepo
+
+	mediaRepo, err := media.Setup(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Media = mediaRepo
+	pool.MediaSigner = media.NewSigner(cfg.UserContent.SigningKey, time.Duration(cfg.UserContent.SignedUrlExpiryMinutes)*time.Minute)
+
+	galleryRepo, err := gallery.Setup(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Gallery = galleryRepo
+
+	attachmentRepo, err := attachment.Setup(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Attachment = attachmentRepo
+
+	pool.StockWatcher = stock.NewWatcher()
+
+	if cfg.RateLimit.Enabled {
+		switch cfg.RateLimit.Backend {
+		case ratelimit.BackendMemory, "":
+			pool.RateLimiter = ratelimit.NewMemoryLimiter()
+		case ratelimit.BackendDb:
+			pool.RateLimiter, err = ratelimit.NewDbLimiter(d)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rate limit backend: %s", cfg.RateLimit.Backend)
+		}
+	}
+
 	switch cfg.UserContent.Mode {
 	case config.UserContentModeLocal:
 		pool.ImageSaver = image.NewLocalSaver(cfg.UserContent.Location)
@@ -75,9 +185,88 @@ func NewDI(d db.DB, cfg *config.Config, apm apm.Apm, version string) (*DI, error
 		}
 		pool.ImageSaver = image.NewGcsSaver(client, cfg.API.GCS.BucketName, cfg.API.GCS.ProjectId, cfg.UserContent.Location)
 
+	case config.UserContentModeS3:
+
+		if cfg.API.S3.Endpoint == "" || cfg.API.S3.BucketName == "" {
+			return nil, fmt.Errorf("endpoint and bucket name for S3 required")
+		}
+
+		saver, err := image.NewS3Saver(image.S3Options{
+			Endpoint:        cfg.API.S3.Endpoint,
+			Region:          cfg.API.S3.Region,
+			BucketName:      cfg.API.S3.BucketName,
+			AccessKeyId:     cfg.API.S3.AccessKeyId,
+			SecretAccessKey: cfg.API.S3.SecretAccessKey,
+			UseSSL:          cfg.API.S3.UseSSL,
+			PathStyle:       cfg.API.S3.PathStyle,
+			PresignExpiry:   time.Duration(cfg.API.S3.PresignExpiryMinutes) * time.Minute,
+		}, cfg.UserContent.Location)
+
+		if err != nil {
+			return nil, err
+		}
+		pool.ImageSaver = saver
+
 	default:
 		return nil, fmt.Errorf("unsupported user content saver: %s", cfg.UserContent.Mode)
 	}
 
+	// repositories of relation service are set up before pool, so every model is migrated by now
+	pool.SchemaErr = db.Migrated(context.Background(), d)
+
 	return pool, nil
 }
+
+// WithDB returns copy of pool which stores everything through d, e.g. inside of transaction.
+// Stock changes, counted domain events and wake up of webhook worker are held back until pool is flushed.
+func (i *DI) WithDB(d db.DB) *DI {
+
+	pool := *i
+
+	pool.DB = d
+	pool.ShoppingList = i.ShoppingList.WithDB(d)
+	pool.ShoppingListItem = i.ShoppingListItem.WithDB(d)
+	pool.ShoppingListEvent = i.ShoppingListEvent.WithDB(d)
+	pool.ConsumptionLog = i.ConsumptionLog.WithDB(d)
+	pool.Audit = i.Audit.WithDB(d)
+	pool.Webhook = i.Webhook.WithDB(d)
+	pool.Media = i.Media.WithDB(d)
+	pool.Gallery = i.Gallery.WithDB(d)
+	pool.Attachment = i.Attachment.WithDB(d)
+	pool.StockWatcher = i.StockWatcher.Buffered()
+	pool.Counts = i.Counts.Buffered()
+
+	return &pool
+}
+
+// Flush announces what pool returned by WithDB held back, it is called once transaction is committed
+func (i *DI) Flush() {
+	i.StockWatcher.Flush()
+	i.Counts.Flush()
+	i.Webhook.Flush()
+}
+
+// WithContext returns copy of pool whose queries and storage calls belong to ctx, e.g. to span of traced request.
+// Shopping list events keep their subscribers, so they are not bound.
+func (i *DI) WithContext(ctx context.Context) *DI {
+
+	d := db.WithContext(i.DB, ctx)
+
+	pool := *i
+
+	pool.DB = d
+	pool.ShoppingList = i.ShoppingList.WithDB(d)
+	pool.ShoppingListItem = i.ShoppingListItem.WithDB(d)
+	pool.ConsumptionLog = i.ConsumptionLog.WithDB(d)
+	pool.Audit = i.Audit.WithDB(d)
+	pool.Webhook = i.Webhook.WithDB(d)
+	pool.Media = i.Media.WithDB(d)
+	pool.Gallery = i.Gallery.WithDB(d)
+	pool.Attachment = i.Attachment.WithDB(d)
+
+	if tracer, ok := i.Apm.(apm.Tracer); ok {
+		pool.ImageSaver = &tracedSaver{saver: i.ImageSaver, tracer: tracer, ctx: ctx}
+	}
+
+	return &pool
+}
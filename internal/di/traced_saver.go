@@ -0,0 +1,80 @@
+package di
+
+import (
+	"bytes"
+	"context"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"io"
+)
+
+// tracedSaver makes every storage call child span of span in ctx
+type tracedSaver struct {
+	saver  image.Saver
+	tracer apm.Tracer
+	ctx    context.Context
+}
+
+func (t *tracedSaver) SaveBase64(base64 string) (string, error) {
+	_, end := t.tracer.Start(t.ctx, "image.SaveBase64")
+	defer end()
+
+	return t.saver.SaveBase64(base64)
+}
+
+func (t *tracedSaver) Save(r io.Reader, mimeType string) (string, error) {
+	_, end := t.tracer.Start(t.ctx, "image.Save")
+	defer end()
+
+	return t.saver.Save(r, mimeType)
+}
+
+func (t *tracedSaver) SaveFile(r io.Reader, ext, mimeType string) (string, error) {
+	_, end := t.tracer.Start(t.ctx, "image.SaveFile")
+	defer end()
+
+	return t.saver.SaveFile(r, ext, mimeType)
+}
+
+func (t *tracedSaver) DeleteFile(fileName string) error {
+	_, end := t.tracer.Start(t.ctx, "image.DeleteFile")
+	defer end()
+
+	return t.saver.DeleteFile(fileName)
+}
+
+func (t *tracedSaver) GetImage(filename string) (*bytes.Buffer, string, error) {
+	_, end := t.tracer.Start(t.ctx, "image.GetImage")
+	defer end()
+
+	return t.saver.GetImage(filename)
+}
+
+func (t *tracedSaver) Exists(fileName string) (bool, error) {
+	_, end := t.tracer.Start(t.ctx, "image.Exists")
+	defer end()
+
+	return t.saver.Exists(fileName)
+}
+
+func (t *tracedSaver) StoredSize(fileName string) (int64, error) {
+	_, end := t.tracer.Start(t.ctx, "image.StoredSize")
+	defer end()
+
+	return t.saver.StoredSize(fileName)
+}
+
+// SignedURL keeps presigned urls working, savers without them never sign
+func (t *tracedSaver) SignedURL(fileName string) (string, error) {
+
+	signer, ok := t.saver.(image.URLSigner)
+
+	if !ok {
+		return "", nil
+	}
+
+	_, end := t.tracer.Start(t.ctx, "image.SignedURL")
+	defer end()
+
+	return signer.SignedURL(fileName)
+}
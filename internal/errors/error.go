@@ -8,6 +8,16 @@ import (
 type CustomError struct {
 	message i18n.Message
 	code int
+	fields []FieldError
+	v1Code int
+}
+
+// FieldError points at request field which failed validation
+type FieldError struct {
+	Field   string
+	Message i18n.Message
+	// V1Code is status api v1 answers with when field is the first invalid one, 0 is bad request
+	V1Code int
 }
 
 func (e *CustomError) Error() string{
@@ -22,6 +32,26 @@ func (e *CustomError) Code() int{
 	return e.code
 }
 
+func (e *CustomError) Fields() []FieldError {
+	return e.fields
+}
+
+// WithV1Code keeps status api v1 answered with before error got more precise code, so v1 clients do not break
+func (e *CustomError) WithV1Code(code int) *CustomError {
+	e.v1Code = code
+	return e
+}
+
+// V1Code is status of error in api v1
+func (e *CustomError) V1Code() int {
+
+	if e.v1Code != 0 {
+		return e.v1Code
+	}
+
+	return e.code
+}
+
 func NewErrNotFound(message i18n.Message) *CustomError {
 	return &CustomError{message: message, code: 404}
 }
@@ -30,6 +60,42 @@ func NewErrBadRequest(message i18n.Message) *CustomError {
 	return &CustomError{message: message, code: 400}
 }
 
+func NewErrUnauthorized(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 401}
+}
+
+func NewErrForbidden(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 403}
+}
+
+func NewErrConflict(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 409}
+}
+
+func NewErrPreconditionFailed(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 412}
+}
+
+func NewErrUnprocessableEntity(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 422}
+}
+
+func NewErrValidation(fields ...FieldError) *CustomError {
+	return &CustomError{message: i18n.NewMessage("request validation failed"), code: 422, fields: fields}
+}
+
+func NewErrPayloadTooLarge(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 413}
+}
+
+func NewErrUnsupportedMediaType(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 415}
+}
+
+func NewErrTooManyRequests(message i18n.Message) *CustomError {
+	return &CustomError{message: message, code: 429}
+}
+
 func NewInternalServer(message i18n.Message) *CustomError {
 	return &CustomError{message: message, code: 500}
 }
\ No newline at end of file
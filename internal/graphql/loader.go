@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"sync"
+)
+
+// Loader batches lookups by id. Executor resolves the whole level of query before it calls thunks,
+// so the first thunk fetches every id requested on that level with a single query.
+type Loader struct {
+	fetch func(ids []int) map[int]interface{}
+
+	mu      sync.Mutex
+	pending []int
+	results map[int]interface{}
+}
+
+// Load queues id and returns thunk which resolves to fetched value, nil when nothing was found
+func (l *Loader) Load(id int) func() (interface{}, error) {
+
+	l.mu.Lock()
+	if _, ok := l.results[id]; !ok {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			l.flush()
+		}
+
+		return l.results[id], nil
+	}
+}
+
+func (l *Loader) flush() {
+
+	seen := map[int]bool{}
+	var ids []int
+
+	for _, id := range l.pending {
+		if _, ok := l.results[id]; ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	l.pending = nil
+
+	if len(ids) == 0 {
+		return
+	}
+
+	fetched := l.fetch(ids)
+
+	// missing ids are remembered too, so they are not fetched again
+	for _, id := range ids {
+		l.results[id] = fetched[id]
+	}
+}
+
+func NewLoader(fetch func(ids []int) map[int]interface{}) *Loader {
+	return &Loader{
+		fetch:   fetch,
+		results: map[int]interface{}{},
+	}
+}
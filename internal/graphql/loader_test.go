@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoaderBatchesQueuedIds(t *testing.T) {
+
+	var calls [][]int
+
+	loader := NewLoader(func(ids []int) map[int]interface{} {
+		calls = append(calls, ids)
+
+		result := map[int]interface{}{}
+		for _, id := range ids {
+			if id != 3 {
+				result[id] = id * 10
+			}
+		}
+		return result
+	})
+
+	first := loader.Load(1)
+	second := loader.Load(2)
+	duplicate := loader.Load(1)
+	missing := loader.Load(3)
+
+	value, err := second()
+	assert.NoError(t, err)
+	assert.Equal(t, 20, value)
+
+	value, _ = first()
+	assert.Equal(t, 10, value)
+
+	value, _ = duplicate()
+	assert.Equal(t, 10, value)
+
+	value, _ = missing()
+	assert.Nil(t, value)
+
+	assert.Equal(t, [][]int{{1, 2, 3}}, calls)
+
+	// fetched and missing ids are not requested again
+	cached := loader.Load(3)
+	next := loader.Load(4)
+
+	_, _ = cached()
+	value, _ = next()
+	assert.Equal(t, 40, value)
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {4}}, calls)
+}
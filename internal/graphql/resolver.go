@@ -0,0 +1,329 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/consumption"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/shopping"
+	"github.com/proviant-io/core/internal/pkg/stock"
+)
+
+type scopeKey struct{}
+
+// scope keeps everything resolvers need to know about request
+type scope struct {
+	accountId int
+	userId    int
+	locale    i18n.Locale
+	loaders   loaders
+}
+
+func requestScope(p graphql.ResolveParams) *scope {
+	return p.Context.Value(scopeKey{}).(*scope)
+}
+
+type loaders struct {
+	products             *Loader
+	lists                *Loader
+	productsByList       *Loader
+	categoriesByProduct  *Loader
+	stockByProduct       *Loader
+	consumptionByProduct *Loader
+	itemsByList          *Loader
+}
+
+func (s *Schema) newLoaders(accountId int) loaders {
+	return loaders{
+		products: NewLoader(func(ids []int) map[int]interface{} {
+			result := map[int]interface{}{}
+			for _, model := range s.productRepo.GetByIds(ids, accountId) {
+				result[model.Id] = product.ModelToDTO(model)
+			}
+			return result
+		}),
+		lists: NewLoader(func(ids []int) map[int]interface{} {
+			result := map[int]interface{}{}
+			for _, model := range s.listRepo.GetByIds(ids, accountId) {
+				result[model.Id] = list.ModelToDTO(model)
+			}
+			return result
+		}),
+		productsByList: NewLoader(func(ids []int) map[int]interface{} {
+			grouped := map[int][]product.DTO{}
+			for _, model := range s.productRepo.GetByListIds(ids, accountId) {
+				grouped[model.ListId] = append(grouped[model.ListId], product.ModelToDTO(model))
+			}
+			return withEmpty(ids, func(id int) interface{} {
+				if dtos, ok := grouped[id]; ok {
+					return dtos
+				}
+				return []product.DTO{}
+			})
+		}),
+		categoriesByProduct: NewLoader(func(ids []int) map[int]interface{} {
+			links := s.productCategoryRepo.GetByProductIds(ids, accountId)
+
+			var categoryIds []int
+			for _, link := range links {
+				categoryIds = append(categoryIds, link.CategoryId)
+			}
+
+			categories := map[int]category.DTO{}
+			if len(categoryIds) > 0 {
+				for _, model := range s.categoryRepo.GetByIds(categoryIds, accountId) {
+					categories[model.Id] = category.ModelToDTO(model)
+				}
+			}
+
+			grouped := map[int][]category.DTO{}
+			for _, link := range links {
+				if c, ok := categories[link.CategoryId]; ok {
+					grouped[link.ProductId] = append(grouped[link.ProductId], c)
+				}
+			}
+
+			return withEmpty(ids, func(id int) interface{} {
+				if dtos, ok := grouped[id]; ok {
+					return dtos
+				}
+				return []category.DTO{}
+			})
+		}),
+		stockByProduct: NewLoader(func(ids []int) map[int]interface{} {
+			grouped := map[int][]stock.DTO{}
+			for _, model := range s.stockRepo.GetAllByProductIds(ids, accountId) {
+				grouped[model.ProductId] = append(grouped[model.ProductId], stock.ModelToDTO(model))
+			}
+			return withEmpty(ids, func(id int) interface{} {
+				if dtos, ok := grouped[id]; ok {
+					return dtos
+				}
+				return []stock.DTO{}
+			})
+		}),
+		consumptionByProduct: NewLoader(func(ids []int) map[int]interface{} {
+			grouped := map[int][]consumption.DTO{}
+			for _, model := range s.di.ConsumptionLog.GetAllByProductIds(ids, accountId) {
+				grouped[model.ProductId] = append(grouped[model.ProductId], consumption.ModelToDTO(model))
+			}
+			return withEmpty(ids, func(id int) interface{} {
+				if dtos, ok := grouped[id]; ok {
+					return dtos
+				}
+				return []consumption.DTO{}
+			})
+		}),
+		itemsByList: NewLoader(func(ids []int) map[int]interface{} {
+			grouped := map[int][]shopping.ItemDTO{}
+			for _, model := range s.di.ShoppingListItem.GetAllByLists(ids, accountId) {
+				grouped[model.ListId] = append(grouped[model.ListId], shopping.ItemToDTO(model))
+			}
+			return withEmpty(ids, func(id int) interface{} {
+				if dtos, ok := grouped[id]; ok {
+					return dtos
+				}
+				return []shopping.ItemDTO{}
+			})
+		}),
+	}
+}
+
+// withEmpty makes sure every id has value, so lists of entities without children resolve to empty ones
+func withEmpty(ids []int, value func(id int) interface{}) map[int]interface{} {
+
+	result := map[int]interface{}{}
+
+	for _, id := range ids {
+		result[id] = value(id)
+	}
+
+	return result
+}
+
+func (s *Schema) resolveProduct(p graphql.ResolveParams) (interface{}, error) {
+
+	model, err := s.productRepo.Get(p.Args["id"].(int), requestScope(p).accountId)
+
+	if err != nil {
+		return nil, s.error(p, *err)
+	}
+
+	return product.ModelToDTO(model), nil
+}
+
+func (s *Schema) resolveProducts(p graphql.ResolveParams) (interface{}, error) {
+
+	accountId := requestScope(p).accountId
+
+	query := &product.Query{}
+	query.List, _ = p.Args["list"].(int)
+
+	categoryFilter, _ := p.Args["category"].(int)
+
+	var inCategory map[int]bool
+
+	if categoryFilter > 0 {
+		inCategory = map[int]bool{}
+		for _, link := range s.productCategoryRepo.GetByCategoryId(categoryFilter, accountId) {
+			inCategory[link.ProductId] = true
+		}
+	}
+
+	dtos := []product.DTO{}
+
+	for _, model := range s.productRepo.GetAll(query, accountId) {
+		if inCategory != nil && !inCategory[model.Id] {
+			continue
+		}
+		dtos = append(dtos, product.ModelToDTO(model))
+	}
+
+	return dtos, nil
+}
+
+func (s *Schema) resolveList(p graphql.ResolveParams) (interface{}, error) {
+
+	model, err := s.listRepo.Get(p.Args["id"].(int), requestScope(p).accountId)
+
+	if err != nil {
+		return nil, s.error(p, *err)
+	}
+
+	return list.ModelToDTO(model), nil
+}
+
+func (s *Schema) resolveLists(p graphql.ResolveParams) (interface{}, error) {
+
+	dtos := []list.DTO{}
+
+	for _, model := range s.listRepo.GetAll(requestScope(p).accountId) {
+		dtos = append(dtos, list.ModelToDTO(model))
+	}
+
+	return dtos, nil
+}
+
+func (s *Schema) resolveCategory(p graphql.ResolveParams) (interface{}, error) {
+
+	model, err := s.categoryRepo.Get(p.Args["id"].(int), requestScope(p).accountId)
+
+	if err != nil {
+		return nil, s.error(p, *err)
+	}
+
+	return category.ModelToDTO(model), nil
+}
+
+func (s *Schema) resolveCategories(p graphql.ResolveParams) (interface{}, error) {
+
+	dtos := []category.DTO{}
+
+	for _, model := range s.categoryRepo.GetAll(requestScope(p).accountId) {
+		dtos = append(dtos, category.ModelToDTO(model))
+	}
+
+	return dtos, nil
+}
+
+func (s *Schema) resolveShoppingList(p graphql.ResolveParams) (interface{}, error) {
+
+	model, err := s.di.ShoppingList.Get(p.Args["id"].(int), requestScope(p).accountId)
+
+	if err != nil {
+		return nil, s.error(p, *err)
+	}
+
+	return shopping.ListToDTO(model), nil
+}
+
+func (s *Schema) resolveShoppingLists(p graphql.ResolveParams) (interface{}, error) {
+
+	dtos := []shopping.ListDTO{}
+
+	for _, model := range s.di.ShoppingList.GetAll(requestScope(p).accountId) {
+		dtos = append(dtos, shopping.ListToDTO(model))
+	}
+
+	return dtos, nil
+}
+
+type addStockArgs struct {
+	ProductId int `json:"product_id"`
+	Quantity  int `json:"quantity" validate:"min=1"`
+	Expire    int `json:"expire" validate:"nonnegative"`
+}
+
+func (s *Schema) addStock(p graphql.ResolveParams) (interface{}, error) {
+
+	sc := requestScope(p)
+
+	args := addStockArgs{}
+	args.ProductId, _ = p.Args["product_id"].(int)
+	args.Quantity, _ = p.Args["quantity"].(int)
+	args.Expire, _ = p.Args["expire"].(int)
+
+	if err := s.validate(p, args); err != nil {
+		return nil, err
+	}
+
+	model, err := s.relationService.WithContext(p.Context).AddStock(stock.DTO{
+		ProductId: args.ProductId,
+		Quantity:  uint(args.Quantity),
+		Expire:    args.Expire,
+	}, sc.accountId, sc.userId)
+
+	if err != nil {
+		return nil, s.error(p, *err)
+	}
+
+	return stock.ModelToDTO(model), nil
+}
+
+type consumeStockArgs struct {
+	ProductId int `json:"product_id"`
+	Quantity  int `json:"quantity" validate:"min=1"`
+}
+
+func (s *Schema) consumeStock(p graphql.ResolveParams) (interface{}, error) {
+
+	sc := requestScope(p)
+
+	args := consumeStockArgs{}
+	args.ProductId, _ = p.Args["product_id"].(int)
+	args.Quantity, _ = p.Args["quantity"].(int)
+
+	if err := s.validate(p, args); err != nil {
+		return nil, err
+	}
+
+	err, consumed := s.relationService.WithContext(p.Context).ConsumeStock(stock.ConsumeDTO{
+		ProductId: args.ProductId,
+		Quantity:  uint(args.Quantity),
+	}, sc.accountId, sc.userId)
+
+	if err != nil {
+		return nil, s.error(p, *err)
+	}
+
+	return consumed, nil
+}
+
+func (s *Schema) checkShoppingListItem(p graphql.ResolveParams) (interface{}, error) {
+
+	sc := requestScope(p)
+
+	id, _ := p.Args["id"].(int)
+	checked, _ := p.Args["checked"].(bool)
+	version, _ := p.Args["version"].(int)
+
+	item, err := s.relationService.WithContext(p.Context).UpdateCheckedShoppingListItem(id, version, checked, sc.accountId, sc.userId)
+
+	if err != nil {
+		return nil, s.error(p, *err)
+	}
+
+	return item, nil
+}
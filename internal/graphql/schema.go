@@ -0,0 +1,431 @@
+package graphql
+
+import (
+	"context"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/consumption"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/product_category"
+	"github.com/proviant-io/core/internal/pkg/service"
+	"github.com/proviant-io/core/internal/pkg/shopping"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/proviant-io/core/internal/validation"
+	"github.com/shopspring/decimal"
+)
+
+// Request is body of GraphQL request sent over http
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Schema struct {
+	schema graphql.Schema
+
+	productRepo         *product.Repository
+	listRepo            *list.Repository
+	categoryRepo        *category.Repository
+	productCategoryRepo *product_category.Repository
+	stockRepo           *stock.Repository
+	relationService     *service.RelationService
+	di                  *di.DI
+	l                   i18n.Localizer
+	validator           *validation.Validator
+}
+
+// Do executes request on behalf of account, loaders live as long as request does
+func (s *Schema) Do(ctx context.Context, r Request, accountId, userId int, locale i18n.Locale) *graphql.Result {
+
+	ctx = context.WithValue(ctx, scopeKey{}, &scope{
+		accountId: accountId,
+		userId:    userId,
+		locale:    locale,
+		loaders:   s.newLoaders(accountId),
+	})
+
+	return graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  r.Query,
+		VariableValues: r.Variables,
+		OperationName:  r.OperationName,
+		Context:        ctx,
+	})
+}
+
+// Error is resolver error translated to locale of request, status and invalid fields are passed in extensions
+type Error struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e Error) Error() string {
+	return e.message
+}
+
+func (e Error) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+func (s *Schema) error(p graphql.ResolveParams, err errors.CustomError) error {
+
+	locale := requestScope(p).locale
+
+	extensions := map[string]interface{}{
+		"status": err.Code(),
+	}
+
+	if len(err.Fields()) > 0 {
+		var fields []map[string]string
+
+		for _, field := range err.Fields() {
+			fields = append(fields, map[string]string{
+				"field":  field.Field,
+				"detail": s.l.T(field.Message, locale),
+			})
+		}
+
+		extensions["fields"] = fields
+	}
+
+	return Error{
+		message:    s.l.T(err.Message(), locale),
+		extensions: extensions,
+	}
+}
+
+// validate checks arguments of mutation with the same rules REST handlers use
+func (s *Schema) validate(p graphql.ResolveParams, args interface{}) error {
+
+	fields := s.validator.Validate(args, requestScope(p).accountId)
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return s.error(p, *errors.NewErrValidation(fields...))
+}
+
+var decimalType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Decimal",
+	Description: "decimal number encoded as string to keep precision",
+	Serialize: func(value interface{}) interface{} {
+		if d, ok := value.(decimal.Decimal); ok {
+			return d.String()
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			if d, err := decimal.NewFromString(s); err == nil {
+				return d
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		switch v := value.(type) {
+		case *ast.StringValue:
+			if d, err := decimal.NewFromString(v.Value); err == nil {
+				return d
+			}
+		case *ast.IntValue:
+			if d, err := decimal.NewFromString(v.Value); err == nil {
+				return d
+			}
+		case *ast.FloatValue:
+			if d, err := decimal.NewFromString(v.Value); err == nil {
+				return d
+			}
+		}
+		return nil
+	},
+})
+
+func nonNullList(t graphql.Type) graphql.Type {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+func (s *Schema) build() error {
+
+	var productType *graphql.Object
+
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"id":      {Type: graphql.NewNonNull(graphql.Int)},
+			"title":   {Type: graphql.NewNonNull(graphql.String)},
+			"version": {Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	listType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "List",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":      {Type: graphql.NewNonNull(graphql.Int)},
+				"title":   {Type: graphql.NewNonNull(graphql.String)},
+				"version": {Type: graphql.NewNonNull(graphql.Int)},
+				"products": {
+					Type: nonNullList(productType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return requestScope(p).loaders.productsByList.Load(p.Source.(list.DTO).Id), nil
+					},
+				},
+			}
+		}),
+	})
+
+	stockLotType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "StockLot",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":         {Type: graphql.NewNonNull(graphql.Int)},
+				"product_id": {Type: graphql.NewNonNull(graphql.Int)},
+				"quantity":   {Type: graphql.NewNonNull(graphql.Int)},
+				"expire":     {Type: graphql.NewNonNull(graphql.Int), Description: "unix timestamp, 0 when lot does not expire"},
+				"version":    {Type: graphql.NewNonNull(graphql.Int)},
+				"product": {
+					Type: productType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return requestScope(p).loaders.products.Load(p.Source.(stock.DTO).ProductId), nil
+					},
+				},
+			}
+		}),
+	})
+
+	consumptionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ConsumptionLog",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          {Type: graphql.NewNonNull(graphql.Int)},
+				"product_id":  {Type: graphql.NewNonNull(graphql.Int)},
+				"quantity":    {Type: graphql.NewNonNull(graphql.Int)},
+				"consumed_at": {Type: graphql.NewNonNull(graphql.Int), Description: "unix timestamp"},
+				"user_id":     {Type: graphql.NewNonNull(graphql.Int)},
+				"product": {
+					Type: productType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return requestScope(p).loaders.products.Load(p.Source.(consumption.DTO).ProductId), nil
+					},
+				},
+			}
+		}),
+	})
+
+	productType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":          {Type: graphql.NewNonNull(graphql.Int)},
+			"title":       {Type: graphql.NewNonNull(graphql.String)},
+			"description": {Type: graphql.NewNonNull(graphql.String)},
+			"link":        {Type: graphql.NewNonNull(graphql.String)},
+			"image":       {Type: graphql.NewNonNull(graphql.String)},
+			"barcode":     {Type: graphql.NewNonNull(graphql.String)},
+			"list_id":     {Type: graphql.NewNonNull(graphql.Int)},
+			"stock":       {Type: graphql.NewNonNull(graphql.Int), Description: "total quantity of all lots"},
+			"price":       {Type: graphql.NewNonNull(decimalType)},
+			"version":     {Type: graphql.NewNonNull(graphql.Int)},
+			"list": {
+				Type: listType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return requestScope(p).loaders.lists.Load(p.Source.(product.DTO).ListId), nil
+				},
+			},
+			"categories": {
+				Type: nonNullList(categoryType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return requestScope(p).loaders.categoriesByProduct.Load(p.Source.(product.DTO).Id), nil
+				},
+			},
+			"category_ids": {
+				Type: nonNullList(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					thunk := requestScope(p).loaders.categoriesByProduct.Load(p.Source.(product.DTO).Id)
+
+					return func() (interface{}, error) {
+						categories, err := thunk()
+
+						ids := []int{}
+						for _, c := range categories.([]category.DTO) {
+							ids = append(ids, c.Id)
+						}
+
+						return ids, err
+					}, nil
+				},
+			},
+			"stock_lots": {
+				Type: nonNullList(stockLotType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return requestScope(p).loaders.stockByProduct.Load(p.Source.(product.DTO).Id), nil
+				},
+			},
+			"consumption_log": {
+				Type: nonNullList(consumptionType),
+				Args: graphql.FieldConfigArgument{
+					"limit": {Type: graphql.Int, Description: "amount of the latest entries, all by default"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					thunk := requestScope(p).loaders.consumptionByProduct.Load(p.Source.(product.DTO).Id)
+					limit, _ := p.Args["limit"].(int)
+
+					return func() (interface{}, error) {
+						entries, err := thunk()
+						log := entries.([]consumption.DTO)
+
+						if limit > 0 && limit < len(log) {
+							log = log[:limit]
+						}
+
+						return log, err
+					}, nil
+				},
+			},
+		},
+	})
+
+	itemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ShoppingListItem",
+		Fields: graphql.Fields{
+			"id":         {Type: graphql.NewNonNull(graphql.Int)},
+			"list_id":    {Type: graphql.NewNonNull(graphql.Int)},
+			"title":      {Type: graphql.NewNonNull(graphql.String)},
+			"comment":    {Type: graphql.NewNonNull(graphql.String)},
+			"quantity":   {Type: graphql.NewNonNull(graphql.Int)},
+			"checked":    {Type: graphql.NewNonNull(graphql.Boolean)},
+			"due_date":   {Type: graphql.NewNonNull(graphql.Int)},
+			"checked_at": {Type: graphql.NewNonNull(graphql.Int)},
+			"updated_at": {Type: graphql.NewNonNull(graphql.Int)},
+			"price":      {Type: graphql.NewNonNull(decimalType)},
+			"product_id": {Type: graphql.NewNonNull(graphql.Int), Description: "0 when item is not linked to product"},
+			"version":    {Type: graphql.NewNonNull(graphql.Int)},
+			"product": {
+				Type: productType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					productId := p.Source.(shopping.ItemDTO).ProductId
+
+					if productId == 0 {
+						return nil, nil
+					}
+
+					return requestScope(p).loaders.products.Load(productId), nil
+				},
+			},
+		},
+	})
+
+	shoppingListType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ShoppingList",
+		Fields: graphql.Fields{
+			"id":    {Type: graphql.NewNonNull(graphql.Int)},
+			"title": {Type: graphql.NewNonNull(graphql.String)},
+			"items": {
+				Type: nonNullList(itemType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return requestScope(p).loaders.itemsByList.Load(p.Source.(shopping.ListDTO).Id), nil
+				},
+			},
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": {Type: graphql.NewNonNull(graphql.Int)},
+	}
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product":        {Type: productType, Args: idArgs, Resolve: s.resolveProduct},
+			"products":       {Type: nonNullList(productType), Args: graphql.FieldConfigArgument{"list": {Type: graphql.Int}, "category": {Type: graphql.Int}}, Resolve: s.resolveProducts},
+			"list":           {Type: listType, Args: idArgs, Resolve: s.resolveList},
+			"lists":          {Type: nonNullList(listType), Resolve: s.resolveLists},
+			"category":       {Type: categoryType, Args: idArgs, Resolve: s.resolveCategory},
+			"categories":     {Type: nonNullList(categoryType), Resolve: s.resolveCategories},
+			"shopping_list":  {Type: shoppingListType, Args: idArgs, Resolve: s.resolveShoppingList},
+			"shopping_lists": {Type: nonNullList(shoppingListType), Resolve: s.resolveShoppingLists},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"add_stock": {
+				Type: graphql.NewNonNull(stockLotType),
+				Args: graphql.FieldConfigArgument{
+					"product_id": {Type: graphql.NewNonNull(graphql.Int)},
+					"quantity":   {Type: graphql.NewNonNull(graphql.Int)},
+					"expire":     {Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: s.addStock,
+			},
+			"consume_stock": {
+				Type: graphql.NewNonNull(consumptionType),
+				Args: graphql.FieldConfigArgument{
+					"product_id": {Type: graphql.NewNonNull(graphql.Int)},
+					"quantity":   {Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: s.consumeStock,
+			},
+			"check_shopping_list_item": {
+				Type: graphql.NewNonNull(itemType),
+				Args: graphql.FieldConfigArgument{
+					"id":      {Type: graphql.NewNonNull(graphql.Int)},
+					"checked": {Type: graphql.Boolean, DefaultValue: true},
+					"version": {Type: graphql.Int, DefaultValue: 0, Description: "fails when item was changed since, any version by default"},
+				},
+				Resolve: s.checkShoppingListItem,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	s.schema = schema
+
+	return nil
+}
+
+func NewSchema(productRepo *product.Repository,
+	listRepo *list.Repository,
+	categoryRepo *category.Repository,
+	productCategoryRepo *product_category.Repository,
+	stockRepo *stock.Repository,
+	relationService *service.RelationService,
+	l i18n.Localizer,
+	i *di.DI) (*Schema, error) {
+
+	s := &Schema{
+		productRepo:         productRepo,
+		listRepo:            listRepo,
+		categoryRepo:        categoryRepo,
+		productCategoryRepo: productCategoryRepo,
+		stockRepo:           stockRepo,
+		relationService:     relationService,
+		di:                  i,
+		l:                   l,
+		validator:           validation.New(),
+	}
+
+	err := s.build()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,175 @@
+package grpc
+
+import (
+	"context"
+	proviantv1 "github.com/proviant-io/core/api/proviant/v1"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/utils"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func productToProto(dto product.DTO) *proviantv1.Product {
+	return &proviantv1.Product{
+		Id:          int64(dto.Id),
+		Version:     int64(dto.Version),
+		Title:       dto.Title,
+		Description: dto.Description,
+		Link:        dto.Link,
+		Image:       dto.Image,
+		Barcode:     dto.Barcode,
+		CategoryIds: idsToProto(dto.CategoryIds),
+		ListId:      int64(dto.ListId),
+		Stock:       uint64(dto.Stock),
+		Price:       dto.Price.String(),
+	}
+}
+
+func idsToProto(ids []int) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, int64(id))
+	}
+	return result
+}
+
+func idsFromProto(ids []int64) []int {
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, int(id))
+	}
+	return result
+}
+
+// parsePrice treats empty price as zero, like omitted price of api v1
+func (s *Server) parsePrice(c caller, price string) (decimal.Decimal, error) {
+
+	if price == "" {
+		return decimal.Zero, nil
+	}
+
+	value, err := decimal.NewFromString(price)
+
+	if err != nil {
+		return decimal.Zero, s.error(c, *errors.NewErrBadRequest(i18n.NewMessage("parse payload error: %v", err.Error())))
+	}
+
+	return value, nil
+}
+
+func (s *Server) GetProduct(ctx context.Context, req *proviantv1.GetProductRequest) (*proviantv1.Product, error) {
+
+	c := callerFrom(ctx)
+
+	dto, err := s.relationService.WithContext(ctx).GetProduct(int(req.Id), c.accountId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	return productToProto(dto), nil
+}
+
+func (s *Server) ListProducts(ctx context.Context, req *proviantv1.ListProductsRequest) (*proviantv1.ListProductsResponse, error) {
+
+	c := callerFrom(ctx)
+
+	query := &product.Query{
+		List:     int(req.ListId),
+		Category: int(req.CategoryId),
+	}
+
+	response := &proviantv1.ListProductsResponse{}
+
+	for _, dto := range s.relationService.WithContext(ctx).GetAllProducts(query, c.accountId) {
+		response.Products = append(response.Products, productToProto(dto))
+	}
+
+	return response, nil
+}
+
+func (s *Server) CreateProduct(ctx context.Context, req *proviantv1.CreateProductRequest) (*proviantv1.Product, error) {
+
+	c := callerFrom(ctx)
+
+	price, err := s.parsePrice(c, req.Price)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dto := product.CreateDTO{
+		Title:       utils.ClearString(req.Title),
+		Description: req.Description,
+		Link:        req.Link,
+		ImageBase64: req.ImageBase64,
+		Barcode:     req.Barcode,
+		CategoryIds: idsFromProto(req.CategoryIds),
+		ListId:      int(req.ListId),
+		Price:       price,
+	}
+
+	if err := s.validate(c, dto); err != nil {
+		return nil, err
+	}
+
+	created, customErr := s.relationService.WithContext(ctx).CreateProduct(dto, c.accountId, c.userId)
+
+	if customErr != nil {
+		return nil, s.error(c, *customErr)
+	}
+
+	return productToProto(created), nil
+}
+
+func (s *Server) UpdateProduct(ctx context.Context, req *proviantv1.UpdateProductRequest) (*proviantv1.Product, error) {
+
+	c := callerFrom(ctx)
+
+	price, err := s.parsePrice(c, req.Price)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dto := product.UpdateDTO{
+		Id:          int(req.Id),
+		Version:     int(req.Version),
+		Title:       utils.ClearString(req.Title),
+		Description: req.Description,
+		Link:        req.Link,
+		Image:       req.Image,
+		ImageBase64: req.ImageBase64,
+		Barcode:     req.Barcode,
+		CategoryIds: idsFromProto(req.CategoryIds),
+		ListId:      int(req.ListId),
+		Price:       price,
+	}
+
+	if err := s.validate(c, dto); err != nil {
+		return nil, err
+	}
+
+	updated, customErr := s.relationService.WithContext(ctx).UpdateProduct(dto, c.accountId, c.userId)
+
+	if customErr != nil {
+		return nil, s.error(c, *customErr)
+	}
+
+	return productToProto(updated), nil
+}
+
+func (s *Server) DeleteProduct(ctx context.Context, req *proviantv1.DeleteProductRequest) (*emptypb.Empty, error) {
+
+	c := callerFrom(ctx)
+
+	err := s.relationService.WithContext(ctx).DeleteProduct(int(req.Id), int(req.Version), c.accountId, c.userId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
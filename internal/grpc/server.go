@@ -0,0 +1,273 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	proviantv1 "github.com/proviant-io/core/api/proviant/v1"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/product_category"
+	"github.com/proviant-io/core/internal/pkg/service"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/proviant-io/core/internal/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+)
+
+// metadata keys, grpc lowercases them, so they match AccountId, UserId and User-Locale headers of api v1
+const (
+	metadataAccountId = "accountid"
+	metadataUserId    = "userid"
+	metadataLocale    = "user-locale"
+)
+
+type callerKey struct{}
+
+// caller is who the call is made on behalf of
+type caller struct {
+	accountId int
+	userId    int
+	locale    i18n.Locale
+}
+
+type Server struct {
+	proviantv1.UnimplementedProviantServiceServer
+
+	productRepo         *product.Repository
+	listRepo            *list.Repository
+	categoryRepo        *category.Repository
+	productCategoryRepo *product_category.Repository
+	stockRepo           *stock.Repository
+	relationService     *service.RelationService
+	l                   i18n.Localizer
+	di                  *di.DI
+	validator           *validation.Validator
+	server              *grpc.Server
+}
+
+func (s *Server) Run(hostPort string) error {
+
+	listener, err := net.Listen("tcp", hostPort)
+
+	if err != nil {
+		return err
+	}
+
+	return s.server.Serve(listener)
+}
+
+func (s *Server) Stop() {
+	s.server.GracefulStop()
+}
+
+// identify reads caller from metadata the same way api v1 reads headers, missing ids mean 0
+func identify(ctx context.Context) (caller, error) {
+
+	c := caller{locale: i18n.En}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+
+	if !ok {
+		return c, nil
+	}
+
+	var err error
+
+	if c.accountId, err = metadataInt(md, metadataAccountId); err != nil {
+		return c, err
+	}
+
+	if c.userId, err = metadataInt(md, metadataUserId); err != nil {
+		return c, err
+	}
+
+	if values := md.Get(metadataLocale); len(values) > 0 {
+		c.locale = i18n.LocaleFromString(values[0])
+	}
+
+	return c, nil
+}
+
+func metadataInt(md metadata.MD, key string) (int, error) {
+
+	values := md.Get(key)
+
+	if len(values) == 0 || values[0] == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(values[0])
+
+	if err != nil {
+		return 0, fmt.Errorf("%s metadata is not a number: %v", key, err)
+	}
+
+	return value, nil
+}
+
+func callerFrom(ctx context.Context) caller {
+	return ctx.Value(callerKey{}).(caller)
+}
+
+func (s *Server) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	c, err := identify(ctx)
+
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return handler(context.WithValue(ctx, callerKey{}, c), req)
+}
+
+func (s *Server) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	c, err := identify(ss.Context())
+
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return handler(srv, &callerStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), callerKey{}, c)})
+}
+
+// unaryRecover answers panic of handler with Internal status, grpc does not recover handlers and panic
+// would take down the whole process, rest api included
+func (s *Server) unaryRecover(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = s.recovered(info.FullMethod, r)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func (s *Server) streamRecover(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = s.recovered(info.FullMethod, r)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func (s *Server) recovered(method string, r interface{}) error {
+	s.di.Logger.Error("grpc: handler panicked", "method", method, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// callerStream replaces stream context, so handlers get caller the same way unary ones do
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
+
+var httpToCode = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// error converts error of api v1 into grpc status, invalid fields are attached as BadRequest details
+func (s *Server) error(c caller, err errors.CustomError) error {
+
+	code, ok := httpToCode[err.Code()]
+
+	if !ok {
+		code = codes.Unknown
+	}
+
+	st := status.New(code, s.l.T(err.Message(), c.locale))
+
+	if len(err.Fields()) == 0 {
+		return st.Err()
+	}
+
+	details := &errdetails.BadRequest{}
+
+	for _, field := range err.Fields() {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: s.l.T(field.Message, c.locale),
+		})
+	}
+
+	withDetails, detailsErr := st.WithDetails(details)
+
+	if detailsErr != nil {
+		s.di.Logger.Warn("grpc: cannot attach field violations", "error", detailsErr)
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+func (s *Server) validate(c caller, dto interface{}) error {
+
+	fields := s.validator.Validate(dto, c.accountId)
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return s.error(c, *errors.NewErrValidation(fields...))
+}
+
+func NewServer(productRepo *product.Repository,
+	listRepo *list.Repository,
+	categoryRepo *category.Repository,
+	productCategoryRepo *product_category.Repository,
+	stockRepo *stock.Repository,
+	relationService *service.RelationService,
+	l i18n.Localizer,
+	i *di.DI) *Server {
+
+	s := &Server{
+		productRepo:         productRepo,
+		listRepo:            listRepo,
+		categoryRepo:        categoryRepo,
+		productCategoryRepo: productCategoryRepo,
+		stockRepo:           stockRepo,
+		relationService:     relationService,
+		l:                   l,
+		di:                  i,
+		validator:           validation.New(),
+	}
+
+	s.validator.RegisterReferences(listRepo, categoryRepo, productRepo, i.Media)
+
+	// recovery goes first, so it covers every interceptor after it
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryRecover, s.unaryAuth),
+		grpc.ChainStreamInterceptor(s.streamRecover, s.streamAuth),
+	)
+
+	proviantv1.RegisterProviantServiceServer(s.server, s)
+
+	return s
+}
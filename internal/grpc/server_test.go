@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	proviantv1 "github.com/proviant-io/core/api/proviant/v1"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/config"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/product_category"
+	"github.com/proviant-io/core/internal/pkg/service"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"path/filepath"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *Server {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "grpc.sqlite"))
+	assert.NoError(t, err)
+
+	cfg := &config.Config{
+		UserContent: config.UserContent{Mode: config.UserContentModeLocal, Location: t.TempDir()},
+	}
+
+	productRepo, err := product.Setup(d)
+	assert.NoError(t, err)
+
+	stockRepo, err := stock.Setup(d)
+	assert.NoError(t, err)
+
+	categoryRepo, err := category.Setup(d)
+	assert.NoError(t, err)
+
+	listRepo, err := list.Setup(d)
+	assert.NoError(t, err)
+
+	productCategoryRepo, err := product_category.Setup(d)
+	assert.NoError(t, err)
+
+	i, err := di.NewDI(d, cfg, &apm.NoopApm{}, logger.Default(), "test")
+	assert.NoError(t, err)
+
+	relationService := service.NewRelationService(productRepo, listRepo, categoryRepo, stockRepo, productCategoryRepo, i, *cfg)
+
+	return NewServer(productRepo, listRepo, categoryRepo, productCategoryRepo, stockRepo, relationService, i18n.NewFileLocalizer(), i)
+}
+
+func TestIdentify(t *testing.T) {
+
+	c, err := identify(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, caller{locale: i18n.En}, c)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("AccountId", "7", "UserId", "3", "User-Locale", "ru"))
+
+	c, err = identify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, caller{accountId: 7, userId: 3, locale: i18n.Ru}, c)
+
+	_, err = identify(metadata.NewIncomingContext(context.Background(), metadata.Pairs("accountid", "seven")))
+
+	assert.Error(t, err)
+}
+
+func TestError(t *testing.T) {
+
+	s := &Server{l: i18n.NewFileLocalizer()}
+	c := caller{locale: i18n.En}
+
+	st := status.Convert(s.error(c, *errors.NewErrNotFound(i18n.NewMessage("product with id %d not found", 5))))
+
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "product with id 5 not found", st.Message())
+	assert.Empty(t, st.Details())
+
+	st = status.Convert(s.error(c, *errors.NewErrValidation(errors.FieldError{
+		Field:   "quantity",
+		Message: i18n.NewMessage("%s should not be 0", "quantity"),
+	})))
+
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	details := st.Details()
+
+	assert.Len(t, details, 1)
+
+	violations := details[0].(*errdetails.BadRequest).FieldViolations
+
+	assert.Equal(t, "quantity", violations[0].Field)
+	assert.Equal(t, "quantity should not be 0", violations[0].Description)
+}
+
+func TestRecover(t *testing.T) {
+
+	s := &Server{di: &di.DI{Logger: logger.Default()}}
+
+	_, err := s.unaryRecover(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("slice bounds out of range")
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	err = s.streamRecover(nil, nil, &grpc.StreamServerInfo{FullMethod: "/test/Stream"}, func(srv interface{}, ss grpc.ServerStream) error {
+		panic("slice bounds out of range")
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestCreateProductRejectsMalformedImage(t *testing.T) {
+
+	s := newTestServer(t)
+
+	fridge := s.relationService.CreateList(list.DTO{Title: "Fridge"}, 1, 1)
+	ctx := context.WithValue(context.Background(), callerKey{}, caller{accountId: 1, userId: 1, locale: i18n.En})
+
+	for _, malformed := range []string{"abc", "data:image/png;base64"} {
+		_, err := s.CreateProduct(ctx, &proviantv1.CreateProductRequest{Title: "Milk", ListId: int64(fridge.Id), ImageBase64: malformed})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), malformed)
+	}
+}
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+	proviantv1 "github.com/proviant-io/core/api/proviant/v1"
+	"github.com/proviant-io/core/internal/pkg/shopping"
+	"github.com/proviant-io/core/internal/utils"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func itemToProto(dto shopping.ItemDTO) *proviantv1.ShoppingListItem {
+	return &proviantv1.ShoppingListItem{
+		Id:        int64(dto.Id),
+		Version:   int64(dto.Version),
+		ListId:    int64(dto.ListId),
+		Title:     dto.Title,
+		Comment:   dto.Comment,
+		Quantity:  int64(dto.Quantity),
+		Checked:   dto.Checked,
+		DueDate:   int64(dto.DueDate),
+		CheckedAt: int64(dto.CheckedAt),
+		UpdatedAt: int64(dto.UpdatedAt),
+		Price:     dto.Price.String(),
+		ProductId: int64(dto.ProductId),
+	}
+}
+
+func (s *Server) GetShoppingList(ctx context.Context, req *proviantv1.GetShoppingListRequest) (*proviantv1.ShoppingList, error) {
+
+	c := callerFrom(ctx)
+
+	dto, err := s.relationService.WithContext(ctx).GetShoppingList(int(req.Id), c.accountId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	response := &proviantv1.ShoppingList{
+		Id:    int64(dto.Id),
+		Title: dto.Title,
+	}
+
+	for _, item := range dto.Items {
+		response.Items = append(response.Items, itemToProto(item))
+	}
+
+	return response, nil
+}
+
+func (s *Server) AddShoppingListItem(ctx context.Context, req *proviantv1.AddShoppingListItemRequest) (*proviantv1.ShoppingListItem, error) {
+
+	c := callerFrom(ctx)
+
+	price, err := s.parsePrice(c, req.Price)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dto := shopping.ItemDTO{
+		Title:     utils.ClearString(req.Title),
+		Comment:   req.Comment,
+		Quantity:  int(req.Quantity),
+		DueDate:   int(req.DueDate),
+		Price:     price,
+		ProductId: int(req.ProductId),
+	}
+
+	if err := s.validate(c, dto); err != nil {
+		return nil, err
+	}
+
+	created, customErr := s.relationService.WithContext(ctx).AddShoppingListItem(int(req.ListId), dto, c.accountId, c.userId)
+
+	if customErr != nil {
+		return nil, s.error(c, *customErr)
+	}
+
+	return itemToProto(created), nil
+}
+
+func (s *Server) UpdateShoppingListItem(ctx context.Context, req *proviantv1.UpdateShoppingListItemRequest) (*proviantv1.ShoppingListItem, error) {
+
+	c := callerFrom(ctx)
+
+	price, err := s.parsePrice(c, req.Price)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dto := shopping.ItemDTO{
+		Id:        int(req.Id),
+		Version:   int(req.Version),
+		Title:     utils.ClearString(req.Title),
+		Comment:   req.Comment,
+		Quantity:  int(req.Quantity),
+		DueDate:   int(req.DueDate),
+		Price:     price,
+		ProductId: int(req.ProductId),
+	}
+
+	if err := s.validate(c, dto); err != nil {
+		return nil, err
+	}
+
+	updated, customErr := s.relationService.WithContext(ctx).UpdateShoppingListItem(int(req.ListId), dto, c.accountId, c.userId)
+
+	if customErr != nil {
+		return nil, s.error(c, *customErr)
+	}
+
+	return itemToProto(updated), nil
+}
+
+func (s *Server) DeleteShoppingListItem(ctx context.Context, req *proviantv1.DeleteShoppingListItemRequest) (*emptypb.Empty, error) {
+
+	c := callerFrom(ctx)
+
+	err := s.relationService.WithContext(ctx).DeleteShoppingListItem(int(req.Id), int(req.Version), c.accountId, c.userId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) CheckShoppingListItem(ctx context.Context, req *proviantv1.CheckShoppingListItemRequest) (*proviantv1.ShoppingListItem, error) {
+
+	c := callerFrom(ctx)
+
+	item, err := s.relationService.WithContext(ctx).UpdateCheckedShoppingListItem(int(req.Id), int(req.Version), req.Checked, c.accountId, c.userId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	return itemToProto(item), nil
+}
@@ -0,0 +1,176 @@
+package grpc
+
+import (
+	"context"
+	proviantv1 "github.com/proviant-io/core/api/proviant/v1"
+	"github.com/proviant-io/core/internal/pkg/consumption"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var changeTypes = map[string]proviantv1.StockChange_Type{
+	stock.ChangeAdded:    proviantv1.StockChange_TYPE_ADDED,
+	stock.ChangeConsumed: proviantv1.StockChange_TYPE_CONSUMED,
+	stock.ChangeUpdated:  proviantv1.StockChange_TYPE_UPDATED,
+	stock.ChangeDeleted:  proviantv1.StockChange_TYPE_DELETED,
+}
+
+func stockToProto(dto stock.DTO) *proviantv1.StockLot {
+	return &proviantv1.StockLot{
+		Id:        int64(dto.Id),
+		Version:   int64(dto.Version),
+		ProductId: int64(dto.ProductId),
+		Quantity:  uint64(dto.Quantity),
+		Expire:    int64(dto.Expire),
+	}
+}
+
+func consumptionToProto(dto consumption.DTO) *proviantv1.Consumption {
+	return &proviantv1.Consumption{
+		Id:         int64(dto.Id),
+		ProductId:  int64(dto.ProductId),
+		Quantity:   uint64(dto.Quantity),
+		ConsumedAt: dto.ConsumedAt,
+		UserId:     int64(dto.UserId),
+	}
+}
+
+func changeToProto(change stock.Change) *proviantv1.StockChange {
+	return &proviantv1.StockChange{
+		Type:      changeTypes[change.Type],
+		ProductId: int64(change.ProductId),
+		LotId:     int64(change.LotId),
+		Quantity:  uint64(change.Quantity),
+		Stock:     uint64(change.Stock),
+		ChangedAt: change.ChangedAt,
+	}
+}
+
+func (s *Server) ListStock(ctx context.Context, req *proviantv1.ListStockRequest) (*proviantv1.ListStockResponse, error) {
+
+	c := callerFrom(ctx)
+
+	_, err := s.productRepo.Get(int(req.ProductId), c.accountId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	response := &proviantv1.ListStockResponse{}
+
+	for _, model := range s.stockRepo.GetAllByProductId(int(req.ProductId), c.accountId) {
+		response.Lots = append(response.Lots, stockToProto(stock.ModelToDTO(model)))
+	}
+
+	return response, nil
+}
+
+func (s *Server) AddStock(ctx context.Context, req *proviantv1.AddStockRequest) (*proviantv1.StockLot, error) {
+
+	c := callerFrom(ctx)
+
+	dto := stock.DTO{
+		ProductId: int(req.ProductId),
+		Quantity:  uint(req.Quantity),
+		Expire:    int(req.Expire),
+	}
+
+	if err := s.validate(c, dto); err != nil {
+		return nil, err
+	}
+
+	model, err := s.relationService.WithContext(ctx).AddStock(dto, c.accountId, c.userId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	return stockToProto(stock.ModelToDTO(model)), nil
+}
+
+func (s *Server) ConsumeStock(ctx context.Context, req *proviantv1.ConsumeStockRequest) (*proviantv1.Consumption, error) {
+
+	c := callerFrom(ctx)
+
+	dto := stock.ConsumeDTO{
+		ProductId: int(req.ProductId),
+		Quantity:  uint(req.Quantity),
+	}
+
+	if err := s.validate(c, dto); err != nil {
+		return nil, err
+	}
+
+	err, consumed := s.relationService.WithContext(ctx).ConsumeStock(dto, c.accountId, c.userId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	return consumptionToProto(consumed), nil
+}
+
+func (s *Server) DeleteStock(ctx context.Context, req *proviantv1.DeleteStockRequest) (*emptypb.Empty, error) {
+
+	c := callerFrom(ctx)
+
+	err := s.relationService.WithContext(ctx).DeleteStock(int(req.Id), int(req.Version), c.accountId, c.userId)
+
+	if err != nil {
+		return nil, s.error(c, *err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// WatchStock sends changes until client goes away, subscribers which fall behind are told to watch again
+func (s *Server) WatchStock(req *proviantv1.WatchStockRequest, stream proviantv1.ProviantService_WatchStockServer) error {
+
+	c := callerFrom(stream.Context())
+
+	if req.ProductId != 0 {
+		_, err := s.productRepo.Get(int(req.ProductId), c.accountId)
+
+		if err != nil {
+			return s.error(c, *err)
+		}
+	}
+
+	changes, cancel := s.di.StockWatcher.Subscribe(c.accountId)
+	defer cancel()
+
+	// headers are flushed right away, so client knows it is subscribed before first change
+	err := stream.SendHeader(nil)
+
+	if err != nil {
+		return err
+	}
+
+	return watch(stream, changes, int(req.ProductId))
+}
+
+func watch(stream grpc.ServerStream, changes <-chan stock.Change, productId int) error {
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case change, ok := <-changes:
+			if !ok {
+				return status.Error(codes.Aborted, "stock watch fell behind, load stock and watch again")
+			}
+
+			if productId != 0 && change.ProductId != productId {
+				continue
+			}
+
+			err := stream.SendMsg(changeToProto(change))
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
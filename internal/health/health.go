@@ -0,0 +1,187 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Timeout bounds every check, so probe answers before orchestrator gives up on it
+const Timeout = 5 * time.Second
+
+// Check tells whether dependency works, e.g. database answers ping
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is down when any of its checks is down
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// Run runs checks concurrently, check which does not finish in Timeout is down
+func Run(ctx context.Context, checks []Check) Report {
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make([]Result, len(checks)),
+	}
+
+	wg := sync.WaitGroup{}
+
+	for i, check := range checks {
+		wg.Add(1)
+
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Checks[i] = run(ctx, check)
+		}(i, check)
+	}
+
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+func run(ctx context.Context, check Check) Result {
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", Timeout)
+	}
+
+	result := Result{
+		Name:       check.Name,
+		Status:     StatusUp,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Registry keeps heartbeats of background workers
+type Registry struct {
+	mu      sync.Mutex
+	workers map[string]*Worker
+	now     func() time.Time
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		workers: map[string]*Worker{},
+		now:     time.Now,
+	}
+}
+
+// Worker registers background worker which is stuck when it does not beat for longer than stuckAfter
+func (r *Registry) Worker(name string, stuckAfter time.Duration) *Worker {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w := &Worker{
+		name:       name,
+		stuckAfter: stuckAfter,
+		last:       r.now(),
+		now:        r.now,
+	}
+
+	r.workers[name] = w
+
+	return w
+}
+
+// Checks returns check of every registered worker, ordered by name
+func (r *Registry) Checks() []Check {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	checks := make([]Check, 0, len(r.workers))
+
+	for _, w := range r.workers {
+		checks = append(checks, Check{Name: "worker." + w.name, Run: w.check})
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	return checks
+}
+
+type Worker struct {
+	name       string
+	stuckAfter time.Duration
+	now        func() time.Time
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Beat is called by worker on every round of its loop
+func (w *Worker) Beat() {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.last = w.now()
+}
+
+func (w *Worker) check(context.Context) error {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	since := w.now().Sub(w.last)
+
+	if since > w.stuckAfter {
+		return fmt.Errorf("no heartbeat for %s", since.Round(time.Second))
+	}
+
+	return nil
+}
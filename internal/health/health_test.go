@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+
+	report := Run(context.Background(), []Check{
+		{Name: "database", Run: func(ctx context.Context) error { return nil }},
+		{Name: "storage", Run: func(ctx context.Context) error { return fmt.Errorf("bucket does not exist") }},
+	})
+
+	assert.False(t, report.Up())
+	assert.Equal(t, "database", report.Checks[0].Name)
+	assert.Equal(t, StatusUp, report.Checks[0].Status)
+	assert.Equal(t, StatusDown, report.Checks[1].Status)
+	assert.Equal(t, "bucket does not exist", report.Checks[1].Error)
+
+	report = Run(context.Background(), []Check{})
+	assert.True(t, report.Up())
+}
+
+func TestWorker(t *testing.T) {
+
+	now := time.Unix(1600000000, 0)
+
+	r := NewRegistry()
+	r.now = func() time.Time { return now }
+
+	w := r.Worker("webhook", time.Minute)
+
+	now = now.Add(30 * time.Second)
+	report := Run(context.Background(), r.Checks())
+	assert.True(t, report.Up())
+	assert.Equal(t, "worker.webhook", report.Checks[0].Name)
+
+	now = now.Add(2 * time.Minute)
+	report = Run(context.Background(), r.Checks())
+	assert.False(t, report.Up())
+	assert.Equal(t, "no heartbeat for 2m30s", report.Checks[0].Error)
+
+	w.Beat()
+	report = Run(context.Background(), r.Checks())
+	assert.True(t, report.Up())
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"bytes"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/idempotency"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"github.com/proviant-io/core/internal/utils"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength  = 191
+	// body has to be read to be fingerprinted, it could not be bigger than the biggest upload,
+	// image sent base64 encoded in json with some room for the rest of payload
+	idempotencyMaxBody = image.MaxSize/3*4 + 1024*1024
+	// bigger bodies are spooled to temporary file instead of memory
+	idempotencyMemoryBody = 1024 * 1024
+)
+
+// responseRecorder passes response to client and keeps a copy of it
+type responseRecorder struct {
+	*utils.StatusWriter
+	body bytes.Buffer
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{StatusWriter: utils.NewStatusWriter(w)}
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.body.Write(b)
+	return rec.StatusWriter.Write(b)
+}
+
+// idempotencyMiddleware executes POST request with Idempotency-Key only once,
+// retries within retention window get response of the original request
+func (s *Server) idempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		key := r.Header.Get(idempotencyKeyHeader)
+
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		accountId := s.accountId(r)
+		locale := s.getLocale(r)
+
+		if len(key) > idempotencyKeyMaxLength {
+			s.handleBadRequest(w, locale, "idempotency key should not be longer than %d characters", idempotencyKeyMaxLength)
+			return
+		}
+
+		digest := idempotency.NewDigest(r.Method, r.URL.Path)
+
+		body, size, err := spoolBody(io.TeeReader(io.LimitReader(r.Body, idempotencyMaxBody+1), digest))
+
+		if err != nil {
+			s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+			return
+		}
+
+		defer body.Close()
+
+		if size > idempotencyMaxBody {
+			s.handleError(w, locale, *errors.NewErrPayloadTooLarge(i18n.NewMessage("request should not be bigger than %d bytes", idempotencyMaxBody)))
+			return
+		}
+
+		r.Body = body
+
+		fingerprint := digest.Fingerprint()
+
+		record, begun := s.di.Idempotency.Begin(key, fingerprint, accountId)
+
+		if !begun {
+			s.replayIdempotent(w, locale, record, fingerprint)
+			return
+		}
+
+		rec := newResponseRecorder(w)
+
+		// retries are answered with conflict for as long as request is being executed, however long it takes
+		heartbeat := time.NewTicker(idempotency.HeartbeatInterval)
+		done := make(chan struct{})
+
+		go func() {
+			for {
+				select {
+				case <-heartbeat.C:
+					s.di.Idempotency.Touch(record)
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		defer func() {
+			heartbeat.Stop()
+			close(done)
+
+			if p := recover(); p != nil {
+				s.di.Idempotency.Release(record)
+				panic(p)
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		// server errors are not final, client should be able to retry them
+		if rec.Status >= http.StatusInternalServerError {
+			s.di.Idempotency.Release(record)
+			return
+		}
+
+		record.Status = rec.Status
+		record.ContentType = rec.Header().Get("Content-Type")
+		record.ETag = rec.Header().Get("ETag")
+		record.Body = rec.body.String()
+
+		s.di.Idempotency.Complete(record)
+	})
+}
+
+func (s *Server) replayIdempotent(w http.ResponseWriter, locale i18n.Locale, record idempotency.Record, fingerprint string) {
+
+	if record.Fingerprint != fingerprint {
+		s.handleError(w, locale, *errors.NewErrUnprocessableEntity(i18n.NewMessage("idempotency key was already used for another request")))
+		return
+	}
+
+	if record.InProgress() {
+		s.handleError(w, locale, *errors.NewErrConflict(i18n.NewMessage("request with the same idempotency key is still in progress")))
+		return
+	}
+
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+
+	if record.ETag != "" {
+		w.Header().Set("ETag", record.ETag)
+	}
+
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write([]byte(record.Body))
+}
+
+// spoolBody reads body, so it could be fingerprinted before it is handled, and returns its copy.
+// Small bodies are kept in memory, larger ones, like uploads, are written to temporary file.
+func spoolBody(body io.Reader) (io.ReadCloser, int64, error) {
+
+	buf := bytes.Buffer{}
+
+	size, err := io.CopyN(&buf, body, idempotencyMemoryBody)
+
+	if err == io.EOF {
+		return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), size, nil
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	file, err := ioutil.TempFile("", "idempotency-*")
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	spooled := &spooledBody{file}
+
+	rest, err := io.Copy(file, io.MultiReader(&buf, body))
+
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		_ = spooled.Close()
+		return nil, 0, err
+	}
+
+	return spooled, rest, nil
+}
+
+// spooledBody removes temporary file once body is closed
+type spooledBody struct {
+	*os.File
+}
+
+func (b *spooledBody) Close() error {
+	err := b.File.Close()
+	_ = os.Remove(b.File.Name())
+	return err
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"bytes"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/config"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/pkg/idempotency"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func newIdempotentServer(t *testing.T) *Server {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "idempotency.sqlite"))
+	assert.NoError(t, err)
+
+	repo, err := idempotency.Setup(d, 0)
+	assert.NoError(t, err)
+
+	return NewServer(nil, nil, nil, nil, nil, nil, nil, i18n.NewFileLocalizer(), &di.DI{
+		Cfg:         &config.Config{Mode: config.ModeApi},
+		Apm:         &apm.NoopApm{},
+		Logger:      logger.Default(),
+		Idempotency: repo,
+	})
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/product/1/add/", bytes.NewBufferString(body))
+	r.Header.Set("AccountId", "1")
+	r.Header.Set(idempotencyKeyHeader, key)
+	return r
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+
+	s := newIdempotentServer(t)
+
+	var executed int32
+
+	handler := s.idempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&executed, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(append(body, byte('0'+n)))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("add-milk", `{"quantity":2}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"quantity":2}1`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("add-milk", `{"quantity":2}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"quantity":2}1`, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "true", w.Header().Get(idempotentReplayedHeader))
+
+	// the same key could not be used for another request
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("add-milk", `{"quantity":3}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("add-bread", `{"quantity":3}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&executed))
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+
+	s := newIdempotentServer(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := s.idempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	go func() {
+		handler.ServeHTTP(first, idempotentRequest("add-milk", `{"quantity":2}`))
+		close(done)
+	}()
+
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("add-milk", `{"quantity":2}`))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	close(release)
+	<-done
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+}
+
+func TestIdempotencyRetriesServerError(t *testing.T) {
+
+	s := newIdempotentServer(t)
+
+	var executed int32
+
+	handler := s.idempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&executed, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("add-milk", `{"quantity":2}`))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("add-milk", `{"quantity":2}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestIdempotencySpoolsLargeBody(t *testing.T) {
+
+	s := newIdempotentServer(t)
+
+	payload := bytes.Repeat([]byte("a"), idempotencyMemoryBody*2)
+	var received []byte
+
+	handler := s.idempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("upload", string(payload)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, payload, received)
+}
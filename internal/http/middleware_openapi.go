@@ -0,0 +1,95 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// openApiMiddleware checks requests and responses of api v1 against specification. Invalid requests are rejected,
+// invalid responses are logged. It buffers bodies, so it is meant for development only.
+func (s *Server) openApiMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		template, err := mux.CurrentRoute(r).GetPathTemplate()
+
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := strings.TrimPrefix(template, apiV1Prefix)
+		operation := s.openApi.Operation(r.Method, path)
+
+		if operation == nil {
+			s.log(r).Warn("openapi: route is not described in specification", "method", r.Method, "path", path)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if schema := operation.RequestSchema(); schema != nil {
+			body, err := ioutil.ReadAll(r.Body)
+
+			if err != nil {
+				s.handleBadRequest(w, s.getLocale(r), "parse payload error: %v", err.Error())
+				return
+			}
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			// malformed payload is reported by handler
+			if value, ok := decodeJSON(body); ok {
+				if fields := s.openApi.Validate(schema, value, false); len(fields) > 0 {
+					s.handleError(w, s.getLocale(r), *errors.NewErrValidation(fields...))
+					return
+				}
+			}
+		}
+
+		// streams and files are passed as is
+		if !operation.RespondsWithJSON() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := newResponseRecorder(w)
+
+		next.ServeHTTP(rec, r)
+
+		schema := operation.ResponseSchema(rec.Status)
+
+		if schema == nil {
+			return
+		}
+
+		value, ok := decodeJSON(rec.body.Bytes())
+
+		if !ok {
+			s.log(r).Warn("openapi: response is not JSON", "method", r.Method, "path", path)
+			return
+		}
+
+		for _, field := range s.openApi.Validate(schema, value, true) {
+			s.log(r).Warn("openapi: response violates specification", "method", r.Method, "path", path, "field", field.Field, "violation", s.l.T(field.Message, i18n.En))
+		}
+	})
+}
+
+func decodeJSON(body []byte) (interface{}, bool) {
+
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/ratelimit"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	rateLimitRead  = "read"
+	rateLimitWrite = "write"
+	rateLimitImage = "image"
+)
+
+// imageRoutes accept or serve images and attachments, they have own (usually smaller) budget
+var imageRoutes = map[string]bool{
+	"POST /api/v1/product/":                                      true,
+	"PUT /api/v1/product/{id}/":                                  true,
+	"PATCH /api/v1/product/{id}/":                                true,
+	"POST /api/v2/product/":                                      true,
+	"PUT /api/v2/product/{id}/":                                  true,
+	"PATCH /api/v2/product/{id}/":                                true,
+	"POST /api/v1/media/":                                        true,
+	"POST /api/v2/media/":                                        true,
+	"POST /api/v1/product/{id}/attachment/":                      true,
+	"POST /api/v2/product/{id}/attachment/":                      true,
+	"GET /api/v1/product/{product_id}/attachment/{id}/download/": true,
+	"GET /api/v2/product/{product_id}/attachment/{id}/download/": true,
+	"GET /uc/img/{fileName}":                                     true,
+}
+
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		class, limit := s.rateLimitClass(r)
+
+		var keys []string
+		for _, key := range s.rateLimitKeys(r) {
+			keys = append(keys, fmt.Sprintf("%s:%s", class, key))
+		}
+
+		allowed, wait, err := s.di.RateLimiter.Allow(keys, limit)
+
+		if err != nil {
+			// fail open, limiter outage should not take api down
+			s.log(r).Error("rate limiter error", "error", err)
+		} else if !allowed {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			s.handleError(w, s.getLocale(r), *errors.NewErrTooManyRequests(i18n.NewMessage("too many requests, retry in %d seconds", retryAfter)))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) rateLimitClass(r *http.Request) (string, ratelimit.Limit) {
+
+	cfg := s.di.Cfg.RateLimit
+
+	template := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if t, err := route.GetPathTemplate(); err == nil {
+			template = t
+		}
+	}
+
+	if imageRoutes[fmt.Sprintf("%s %s", r.Method, template)] {
+		return rateLimitImage, ratelimit.Limit{Rate: cfg.Image.Rate, Burst: cfg.Image.Burst}
+	}
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+		return rateLimitRead, ratelimit.Limit{Rate: cfg.Read.Rate, Burst: cfg.Read.Burst}
+	}
+
+	return rateLimitWrite, ratelimit.Limit{Rate: cfg.Write.Rate, Burst: cfg.Write.Burst}
+}
+
+// rateLimitKeys returns every identity request is accounted for, request should fit into budget of each
+func (s *Server) rateLimitKeys(r *http.Request) []string {
+
+	var keys []string
+
+	if r.Header.Get("AccountId") != "" {
+		keys = append(keys, fmt.Sprintf("account:%d", s.accountId(r)))
+	}
+
+	if token := r.Header.Get("Authorization"); token != "" {
+		// never keep raw tokens in limiter storage
+		hash := sha256.Sum256([]byte(token))
+		keys = append(keys, fmt.Sprintf("token:%s", hex.EncodeToString(hash[:16])))
+	}
+
+	keys = append(keys, fmt.Sprintf("ip:%s", s.clientIp(r)))
+
+	return keys
+}
+
+func (s *Server) clientIp(r *http.Request) string {
+
+	if s.di.Cfg.RateLimit.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"github.com/google/uuid"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/utils"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// RequestIdHeader correlates response with log records of request
+const RequestIdHeader = "X-Request-Id"
+
+// id sent by client, e.g. by proxy, is kept while it cannot break log lines
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestIdMiddleware gives request an id, handlers log through logger which adds it to every record
+func (s *Server) requestIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		id := r.Header.Get(RequestIdHeader)
+
+		if !requestIdPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+
+		w.Header().Set(RequestIdHeader, id)
+
+		l := s.di.Logger.With("request_id", id)
+		sw := utils.NewStatusWriter(w)
+		start := time.Now()
+
+		next.ServeHTTP(sw, r.WithContext(logger.NewContext(r.Context(), l)))
+
+		l.Info("request served",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", sw.Status,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	})
+}
+
+// log returns logger of request, its records carry request id
+func (s *Server) log(r *http.Request) *logger.Logger {
+	return logger.FromContext(r.Context())
+}
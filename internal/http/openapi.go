@@ -0,0 +1,301 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/proviant-io/core/internal/graphql"
+	"github.com/proviant-io/core/internal/health"
+	"github.com/proviant-io/core/internal/openapi"
+	"github.com/proviant-io/core/internal/pkg/attachment"
+	"github.com/proviant-io/core/internal/pkg/audit"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/consumption"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/media"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/service"
+	"github.com/proviant-io/core/internal/pkg/shopping"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/proviant-io/core/internal/pkg/webhook"
+	"github.com/shopspring/decimal"
+	"net/http"
+)
+
+const apiV1Prefix = "/api/v1"
+
+const openApiDescription = `Every path is served by api v1 and v2. v1 wraps data into {status, data, error} envelope,
+v2 returns data as is, answers with 204 when there is nothing to return and reports errors as RFC 7807 problems.
+Versioned resources return their version in ETag header, send it back in If-Match to avoid lost updates.`
+
+var (
+	ifMatchParameter = openapi.Parameter{
+		Name:        "If-Match",
+		In:          "header",
+		Description: "version from ETag, request fails with 412 when resource was changed since",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	idempotencyKeyParameter = openapi.Parameter{
+		Name:        idempotencyKeyHeader,
+		In:          "header",
+		Description: "retries with the same key get response of the first request",
+		Schema:      &openapi.Schema{Type: "string", MaxLength: &[]int{idempotencyKeyMaxLength}[0]},
+	}
+	authorizationParameter = openapi.Parameter{
+		Name:        "Authorization",
+		In:          "header",
+		Description: "Bearer admin token",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	// rootServer serves routes registered outside of api, e.g. graphql and probes
+	rootServer = openapi.Server{Url: "/"}
+	eTagHeader = map[string]openapi.Header{
+		"ETag": {
+			Description: "version of resource",
+			Schema:      &openapi.Schema{Type: "string"},
+		},
+	}
+)
+
+func queryParameter(name, typ, description string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &openapi.Schema{Type: typ},
+	}
+}
+
+// apiRoutes describes every route registered by registerApiRoutes, paths are relative to api prefix
+func (s *Server) apiRoutes() []openapi.Route {
+
+	var versionResponse struct {
+		Version string `json:"version"`
+	}
+
+	var consumeResponse struct {
+		Stock           []stock.DTO     `json:"stock"`
+		ConsumedLogItem consumption.DTO `json:"consumed_log_item"`
+	}
+
+	return []openapi.Route{
+		// product routes
+		{Id: "getProduct", Method: http.MethodGet, Path: "/product/{id}/", Tag: "product", Response: product.DTO{}, Headers: eTagHeader},
+		{Id: "getProducts", Method: http.MethodGet, Path: "/product/", Tag: "product", Response: []product.DTO{}, Parameters: []openapi.Parameter{
+			queryParameter("list", "integer", "list id"),
+			queryParameter("category", "integer", "category id"),
+		}},
+		{Id: "createProduct", Method: http.MethodPost, Path: "/product/", Tag: "product", Request: product.CreateDTO{}, Response: product.DTO{}, Status: http.StatusCreated, Headers: eTagHeader},
+		{Id: "updateProduct", Method: http.MethodPut, Path: "/product/{id}/", Tag: "product", Request: product.UpdateDTO{}, Response: product.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "patchProduct", Method: http.MethodPatch, Path: "/product/{id}/", Tag: "product", Request: openapi.MergePatch{Of: product.UpdateDTO{}}, Response: product.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "deleteProduct", Method: http.MethodDelete, Path: "/product/{id}/", Tag: "product", Parameters: []openapi.Parameter{ifMatchParameter}},
+		// category routes
+		{Id: "getCategory", Method: http.MethodGet, Path: "/category/{id}/", Tag: "category", Response: category.DTO{}, Headers: eTagHeader},
+		{Id: "getCategories", Method: http.MethodGet, Path: "/category/", Tag: "category", Response: []category.DTO{}},
+		{Id: "createCategory", Method: http.MethodPost, Path: "/category/", Tag: "category", Request: category.DTO{}, Response: category.DTO{}, Status: http.StatusCreated, Headers: eTagHeader},
+		{Id: "updateCategory", Method: http.MethodPut, Path: "/category/{id}/", Tag: "category", Request: category.DTO{}, Response: category.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "patchCategory", Method: http.MethodPatch, Path: "/category/{id}/", Tag: "category", Request: openapi.MergePatch{Of: category.DTO{}}, Response: category.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "deleteCategory", Method: http.MethodDelete, Path: "/category/{id}/", Tag: "category", Parameters: []openapi.Parameter{ifMatchParameter}},
+		// list routes
+		{Id: "getList", Method: http.MethodGet, Path: "/list/{id}/", Tag: "list", Response: list.DTO{}, Headers: eTagHeader},
+		{Id: "getLists", Method: http.MethodGet, Path: "/list/", Tag: "list", Response: []list.DTO{}},
+		{Id: "createList", Method: http.MethodPost, Path: "/list/", Tag: "list", Request: list.DTO{}, Response: list.DTO{}, Status: http.StatusCreated, Headers: eTagHeader},
+		{Id: "updateList", Method: http.MethodPut, Path: "/list/{id}/", Tag: "list", Request: list.DTO{}, Response: list.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "patchList", Method: http.MethodPatch, Path: "/list/{id}/", Tag: "list", Request: openapi.MergePatch{Of: list.DTO{}}, Response: list.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "deleteList", Method: http.MethodDelete, Path: "/list/{id}/", Tag: "list", Parameters: []openapi.Parameter{ifMatchParameter}},
+		// stock routers
+		{Id: "getStock", Method: http.MethodGet, Path: "/product/{id}/stock/", Tag: "stock", Response: []stock.DTO{}},
+		{Id: "addStock", Method: http.MethodPost, Path: "/product/{id}/add/", Tag: "stock", Request: stock.DTO{}, Response: stock.DTO{}, Status: http.StatusCreated, Headers: eTagHeader},
+		{Id: "consumeStock", Method: http.MethodPost, Path: "/product/{id}/consume/", Tag: "stock", Request: stock.ConsumeDTO{}, Response: consumeResponse},
+		{Id: "getStockLot", Method: http.MethodGet, Path: "/product/{product_id}/stock/{id}/", Tag: "stock", Response: stock.DTO{}, Headers: eTagHeader},
+		{Id: "patchStockLot", Method: http.MethodPatch, Path: "/product/{product_id}/stock/{id}/", Tag: "stock", Request: openapi.MergePatch{Of: stock.DTO{}}, Response: stock.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "deleteStock", Method: http.MethodDelete, Path: "/product/{product_id}/stock/{id}/", Tag: "stock", Response: []stock.DTO{}, Parameters: []openapi.Parameter{ifMatchParameter}},
+		// shopping list
+		{Id: "getShoppingLists", Method: http.MethodGet, Path: "/shopping_list/", Tag: "shopping_list", Response: []shopping.ListDTO{}},
+		{Id: "getShoppingList", Method: http.MethodGet, Path: "/shopping_list/{id}/", Tag: "shopping_list", Response: shopping.ListFilledDTO{}},
+		{Id: "streamShoppingList", Method: http.MethodGet, Path: "/shopping_list/{id}/events/", Tag: "shopping_list", ContentType: "text/event-stream", Parameters: []openapi.Parameter{
+			queryParameter("last_event_id", "integer", "id of the last received event, Last-Event-ID header takes precedence"),
+		}},
+		{Id: "addShoppingListItem", Method: http.MethodPost, Path: "/shopping_list/{id}/", Tag: "shopping_list", Request: shopping.ItemDTO{}, Response: shopping.ItemDTO{}, Status: http.StatusCreated, Headers: eTagHeader},
+		{Id: "getShoppingListItem", Method: http.MethodGet, Path: "/shopping_list/{list_id}/{id}/", Tag: "shopping_list", Response: shopping.ItemDTO{}, Headers: eTagHeader},
+		{Id: "updateShoppingListItem", Method: http.MethodPut, Path: "/shopping_list/{list_id}/{id}/", Tag: "shopping_list", Request: shopping.ItemDTO{}, Response: shopping.ItemDTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "patchShoppingListItem", Method: http.MethodPatch, Path: "/shopping_list/{list_id}/{id}/", Tag: "shopping_list", Request: openapi.MergePatch{Of: shopping.ItemDTO{}}, Response: shopping.ItemDTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "deleteShoppingListItem", Method: http.MethodDelete, Path: "/shopping_list/{list_id}/{id}/", Tag: "shopping_list", Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "checkShoppingListItem", Method: http.MethodPut, Path: "/shopping_list/{list_id}/{id}/check/", Tag: "shopping_list", Response: shopping.ItemDTO{}, Status: http.StatusCreated, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		{Id: "uncheckShoppingListItem", Method: http.MethodPut, Path: "/shopping_list/{list_id}/{id}/uncheck/", Tag: "shopping_list", Response: shopping.ItemDTO{}, Status: http.StatusCreated, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		// stock consumption log
+		{Id: "getConsumptionLog", Method: http.MethodGet, Path: "/product/{id}/consumption_log/", Tag: "stock", Response: []consumption.DTO{}},
+		// media
+		{Id: "uploadMedia", Method: http.MethodPost, Path: "/media/", Tag: "media", Request: openapi.Upload{Field: mediaFormField}, Response: media.DTO{}, Status: http.StatusCreated},
+		{Id: "getMedia", Method: http.MethodGet, Path: "/media/{id}/", Tag: "media", Response: media.DTO{}},
+		{Id: "getProductMedia", Method: http.MethodGet, Path: "/product/{id}/media/", Tag: "media", Summary: "photos of product, the first one is image of product", Response: []media.DTO{}},
+		{Id: "setProductMedia", Method: http.MethodPut, Path: "/product/{id}/media/", Tag: "media", Summary: "replaces photos of product, the first one becomes image of product", Request: product.GalleryDTO{}, Response: product.DTO{}, Headers: eTagHeader, Parameters: []openapi.Parameter{ifMatchParameter}},
+		// attachments
+		{Id: "getAttachments", Method: http.MethodGet, Path: "/product/{id}/attachment/", Tag: "attachment", Response: []attachment.DTO{}},
+		{Id: "uploadAttachment", Method: http.MethodPost, Path: "/product/{id}/attachment/", Tag: "attachment", Request: openapi.Upload{Field: mediaFormField}, Response: attachment.DTO{}, Status: http.StatusCreated},
+		{Id: "downloadAttachment", Method: http.MethodGet, Path: "/product/{product_id}/attachment/{id}/download/", Tag: "attachment", ContentType: "application/octet-stream"},
+		{Id: "deleteAttachment", Method: http.MethodDelete, Path: "/product/{product_id}/attachment/{id}/", Tag: "attachment"},
+		// audit log
+		{Id: "getAuditLog", Method: http.MethodGet, Path: "/audit/", Tag: "audit", Response: []audit.DTO{}, Parameters: []openapi.Parameter{
+			queryParameter("entity", "string", "entity name, e.g. product"),
+			queryParameter("entity_id", "integer", ""),
+			queryParameter("action", "string", "create, update or delete"),
+			queryParameter("user_id", "integer", ""),
+			queryParameter("from", "integer", "unix timestamp"),
+			queryParameter("to", "integer", "unix timestamp"),
+			queryParameter("limit", "integer", ""),
+			queryParameter("offset", "integer", ""),
+		}},
+		// offline sync
+		{Id: "getSyncChanges", Method: http.MethodGet, Path: "/sync/", Summary: "entities changed after cursor, zero cursor gets all of them", Tag: "sync", Response: service.SyncFeed{}, Parameters: []openapi.Parameter{
+			queryParameter("cursor", "integer", "cursor returned by previous request"),
+			queryParameter("limit", "integer", "maximum of audit entries the page is built from"),
+		}},
+		// webhooks
+		{Id: "getWebhooks", Method: http.MethodGet, Path: "/webhook/", Tag: "webhook", Response: []webhook.SubscriptionDTO{}},
+		{Id: "createWebhook", Method: http.MethodPost, Path: "/webhook/", Tag: "webhook", Request: webhook.SubscriptionDTO{}, Response: webhook.SubscriptionDTO{}, Status: http.StatusCreated},
+		{Id: "getWebhook", Method: http.MethodGet, Path: "/webhook/{id}/", Tag: "webhook", Response: webhook.SubscriptionDTO{}},
+		{Id: "updateWebhook", Method: http.MethodPut, Path: "/webhook/{id}/", Tag: "webhook", Request: webhook.SubscriptionDTO{}, Response: webhook.SubscriptionDTO{}},
+		{Id: "deleteWebhook", Method: http.MethodDelete, Path: "/webhook/{id}/", Tag: "webhook"},
+		{Id: "getWebhookDeliveries", Method: http.MethodGet, Path: "/webhook/{id}/delivery/", Tag: "webhook", Response: []webhook.DeliveryDTO{}},
+		{Id: "replayWebhookDelivery", Method: http.MethodPost, Path: "/webhook/delivery/{id}/replay/", Tag: "webhook", Response: webhook.DeliveryDTO{}, Status: http.StatusCreated},
+		// admin
+		{Id: "exportAccount", Method: http.MethodGet, Path: "/admin/account/{id}/export/", Tag: "admin", Response: service.AccountExport{}, Parameters: []openapi.Parameter{authorizationParameter}},
+		{Id: "eraseAccount", Method: http.MethodDelete, Path: "/admin/account/{id}/", Tag: "admin", Response: service.ErasureReport{}, Parameters: []openapi.Parameter{authorizationParameter}},
+		{Id: "getDiagnostics", Method: http.MethodGet, Path: "/admin/diagnostics/", Tag: "admin", Response: Diagnostics{}, Parameters: []openapi.Parameter{authorizationParameter}},
+		// chore
+		{Id: "getMissingTranslations", Method: http.MethodGet, Path: "/i18n/missing/", Tag: "chore", Response: []string{}},
+		{Id: "getVersion", Method: http.MethodGet, Path: "/version/", Tag: "chore", Response: versionResponse},
+	}
+}
+
+// apiSpecification generates OpenAPI document of api v1 from route descriptions and DTO types
+func (s *Server) apiSpecification() openapi.Document {
+
+	version := s.di.Version
+
+	if version == "" {
+		version = "dev"
+	}
+
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       "Proviant API",
+		Description: openApiDescription,
+		Version:     version,
+	}, openapi.Server{Url: apiV1Prefix})
+
+	b.Format(decimal.Decimal{}, openapi.Schema{OneOf: []*openapi.Schema{
+		{Type: "string", Format: "decimal"},
+		{Type: "number"},
+	}})
+
+	b.Envelope = func(data *openapi.Schema) *openapi.Schema {
+		return &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"status": {Type: "integer"},
+				"data":   data,
+				"error":  {Type: "string"},
+			},
+			Required: []string{"status", "data", "error"},
+		}
+	}
+
+	b.Error = b.Component("Error", b.Envelope(&openapi.Schema{Nullable: true}))
+
+	for _, route := range s.apiRoutes() {
+		if route.Method == http.MethodPost {
+			route.Parameters = append(route.Parameters, idempotencyKeyParameter)
+		}
+		b.Add(route)
+	}
+
+	b.Format(json.RawMessage{}, openapi.Schema{Description: "any JSON value"})
+
+	b.Add(openapi.Route{
+		Id:         "batch",
+		Method:     http.MethodPost,
+		Path:       "/batch",
+		Summary:    "executes operations in single transaction, the first failed one rolls back all of them",
+		Tag:        "batch",
+		Request:    BatchRequest{},
+		Response:   BatchResult{},
+		Parameters: []openapi.Parameter{idempotencyKeyParameter},
+	})
+
+	b.Add(openapi.Route{
+		Id:         "pushSync",
+		Method:     http.MethodPost,
+		Path:       "/sync/push/",
+		Summary:    "applies mutations made offline, each of them on its own",
+		Tag:        "sync",
+		Request:    SyncPushRequest{},
+		Response:   SyncPushResult{},
+		Parameters: []openapi.Parameter{idempotencyKeyParameter},
+	})
+
+	b.Add(openapi.Route{
+		Id:       "getOpenApi",
+		Method:   http.MethodGet,
+		Path:     "/openapi.json",
+		Summary:  "this document",
+		Tag:      "chore",
+		Response: map[string]interface{}{},
+		Bare:     true,
+	})
+
+	b.Add(openapi.Route{
+		Id:          "getImage",
+		Method:      http.MethodGet,
+		Path:        "/img/{fileName}",
+		Summary:     "user content image, it is served to its account or by signed url",
+		Tag:         "user_content",
+		ContentType: "image/*",
+		Parameters: []openapi.Parameter{
+			{Name: "fileName", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			queryParameter("size", "string", "thumb, small or medium, original is served when omitted"),
+			queryParameter(media.ExpiresParam, "integer", "expiry of signed url, unix timestamp"),
+			queryParameter(media.SignatureParam, "string", "signature of signed url"),
+		},
+		Servers: []openapi.Server{{Url: "/uc"}},
+	})
+
+	b.Add(openapi.Route{
+		Id:       "graphql",
+		Method:   http.MethodPost,
+		Path:     "/graphql",
+		Summary:  "executes GraphQL request, response follows GraphQL over http convention",
+		Tag:      "graphql",
+		Request:  graphql.Request{},
+		Response: map[string]interface{}{},
+		Bare:     true,
+		Servers:  []openapi.Server{rootServer},
+	})
+
+	b.Add(openapi.Route{
+		Id:       "getLiveness",
+		Method:   http.MethodGet,
+		Path:     livenessPath,
+		Summary:  "liveness probe, it answers with 503 when background worker is stuck",
+		Tag:      "health",
+		Response: health.Report{},
+		Bare:     true,
+		Servers:  []openapi.Server{rootServer},
+	})
+
+	b.Add(openapi.Route{
+		Id:       "getReadiness",
+		Method:   http.MethodGet,
+		Path:     readinessPath,
+		Summary:  "readiness probe, it answers with 503 while server cannot serve requests",
+		Tag:      "health",
+		Response: health.Report{},
+		Bare:     true,
+		Servers:  []openapi.Server{rootServer},
+	})
+
+	return b.Document()
+}
+
+func (s *Server) getOpenApi(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, openapi.ContentTypeJSON, ResponseCodeOk, s.openApi)
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/config"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update testdata/openapi.json")
+
+const specificationFile = "testdata/openapi.json"
+
+func newTestServer() *Server {
+	return NewServer(nil, nil, nil, nil, nil, nil, nil, i18n.NewFileLocalizer(), &di.DI{
+		Cfg:    &config.Config{Mode: config.ModeApi},
+		Apm:    &apm.NoopApm{},
+		Logger: logger.Default(),
+	})
+}
+
+// undescribedRoutes are left out of specification on purpose, they are not api
+var undescribedRoutes = map[string]string{
+	"/static":              "static files of web ui",
+	"/":                    "web ui, every path unknown to server is its page",
+	apm.DefaultMetricsPath: "metrics are meant for scraper, their format is defined by exporter",
+}
+
+// registeredRoutes lists every route of router as "METHOD path", routes without handler are subrouters
+func registeredRoutes(t *testing.T, router *mux.Router) []string {
+
+	var routes []string
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			return nil
+		}
+
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := undescribedRoutes[template]; ok {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return fmt.Errorf("route %s accepts any method: %v", template, err)
+		}
+
+		for _, method := range methods {
+			routes = append(routes, method+" "+template)
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+
+	sort.Strings(routes)
+
+	return routes
+}
+
+// describedRoutes lists every operation of document as "METHOD path", path is prefixed with url of its server
+func describedRoutes(doc openapi.Document) []string {
+
+	var routes []string
+
+	for _, operation := range doc.Operations() {
+		parts := strings.SplitN(operation, " ", 2)
+		method, path := parts[0], parts[1]
+
+		servers := doc.Servers
+		if item := doc.Paths[path]; len(item.Servers) > 0 {
+			servers = item.Servers
+		}
+
+		path = strings.TrimSuffix(servers[0].Url, "/") + path
+
+		routes = append(routes, method+" "+path)
+
+		// GET of user content answers HEAD as well
+		if method == http.MethodGet && strings.HasPrefix(path, "/uc/") {
+			routes = append(routes, http.MethodHead+" "+path)
+		}
+	}
+
+	return routes
+}
+
+func TestOpenApiDescribesEveryRoute(t *testing.T) {
+
+	s := NewServer(nil, nil, nil, nil, nil, nil, nil, i18n.NewFileLocalizer(), &di.DI{
+		Cfg:    &config.Config{Mode: config.ModeWeb},
+		Apm:    apm.NewApm(config.APM{Vendor: config.ApmVendorPrometheus}),
+		Logger: logger.Default(),
+	})
+
+	var expected []string
+
+	for _, route := range describedRoutes(s.openApi) {
+		expected = append(expected, route)
+
+		// v2 serves the same routes, specification itself is served by v1 only
+		if strings.Contains(route, " "+apiV1Prefix+"/") && route != "GET "+apiV1Prefix+"/openapi.json" {
+			expected = append(expected, strings.Replace(route, apiV1Prefix, "/api/v2", 1))
+		}
+	}
+
+	sort.Strings(expected)
+
+	assert.Equal(t, expected, registeredRoutes(t, s.router))
+}
+
+func TestOpenApiOperationIdsAreUnique(t *testing.T) {
+
+	s := newTestServer()
+	ids := map[string]string{}
+
+	for _, operation := range s.openApi.Operations() {
+		parts := strings.SplitN(operation, " ", 2)
+		id := s.openApi.Operation(parts[0], parts[1]).OperationId
+
+		assert.NotEmpty(t, id, operation)
+		assert.NotContains(t, ids, id, operation)
+
+		ids[id] = operation
+	}
+}
+
+// TestOpenApiSpecification fails when DTOs or routes change without specification being regenerated with
+// go test ./internal/http -run TestOpenApiSpecification -update
+func TestOpenApiSpecification(t *testing.T) {
+
+	s := newTestServer()
+
+	actual, err := json.MarshalIndent(s.openApi, "", "  ")
+	assert.NoError(t, err)
+
+	actual = append(actual, '\n')
+
+	if *update {
+		assert.NoError(t, ioutil.WriteFile(specificationFile, actual, 0644))
+	}
+
+	expected, err := ioutil.ReadFile(specificationFile)
+	assert.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(actual))
+}
@@ -1,12 +1,18 @@
 package http
 
 const (
-	ResponseCodeOk      = 200
-	ResponseCodeCreated = 201
-	BadRequest          = 400
-	InternalServerError = 500
+	ResponseCodeOk        = 200
+	ResponseCodeCreated   = 201
+	ResponseCodeNoContent = 204
+	BadRequest            = 400
+	InternalServerError   = 500
 )
 
+const (
+	ApiVersionHeader   = "Api-Version"
+	ApiVersion2        = "2"
+	ProblemContentType = "application/problem+json"
+)
 
 type Response struct {
 	Status int         `json:"status"`
@@ -14,3 +20,42 @@ type Response struct {
 	Error  string      `json:"error"`
 }
 
+// Problem is RFC 7807 error body returned by api v2
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []FieldProblem `json:"errors,omitempty"`
+}
+
+type FieldProblem struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+var problemTypes = map[int]string{
+	400: "bad-request",
+	401: "unauthorized",
+	403: "forbidden",
+	404: "not-found",
+	409: "conflict",
+	412: "precondition-failed",
+	413: "payload-too-large",
+	415: "unsupported-media-type",
+	422: "validation-failed",
+	429: "too-many-requests",
+	500: "internal-error",
+}
+
+// problemType identifies kind of problem, it is URN so clients can rely on it without dereferencing
+func problemType(status int) string {
+
+	name, ok := problemTypes[status]
+
+	if !ok {
+		return "about:blank"
+	}
+
+	return "urn:proviant:problem:" + name
+}
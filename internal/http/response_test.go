@@ -0,0 +1,45 @@
+package http
+
+import (
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleErrorKeepsV1Status(t *testing.T) {
+
+	s := newTestServer()
+
+	conflict := errors.NewErrConflict(i18n.NewMessage("You can't remove list with products. Clean products first.")).WithV1Code(400)
+
+	w := httptest.NewRecorder()
+	s.handleError(w, i18n.En, *conflict)
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	w.Header().Set(ApiVersionHeader, ApiVersion2)
+	s.handleError(w, i18n.En, *conflict)
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestHandleErrorKeepsV1StatusOfMissingReference(t *testing.T) {
+
+	s := newTestServer()
+
+	invalid := errors.NewErrValidation(errors.FieldError{
+		Field:   "list_id",
+		Message: i18n.NewMessage("list with id %d not found", 7),
+		V1Code:  404,
+	})
+
+	w := httptest.NewRecorder()
+	s.handleError(w, i18n.En, *invalid)
+	assert.Equal(t, 404, w.Code)
+
+	w = httptest.NewRecorder()
+	w.Header().Set(ApiVersionHeader, ApiVersion2)
+	s.handleError(w, i18n.En, *invalid)
+	assert.Equal(t, 422, w.Code)
+}
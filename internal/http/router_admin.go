@@ -0,0 +1,90 @@
+package http
+
+import (
+	"crypto/subtle"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+// checkAdmin validates "Authorization: Bearer <token>" against admin token from config
+func (s *Server) checkAdmin(r *http.Request) *errors.CustomError {
+
+	token := s.di.Cfg.Admin.Token
+
+	if token == "" {
+		return errors.NewErrForbidden(i18n.NewMessage("admin api is disabled"))
+	}
+
+	header := r.Header.Get("Authorization")
+
+	if !strings.HasPrefix(header, bearerPrefix) ||
+		subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, bearerPrefix)), []byte(token)) != 1 {
+		return errors.NewErrUnauthorized(i18n.NewMessage("admin token is invalid"))
+	}
+
+	return nil
+}
+
+func (s *Server) adminAccountId(w http.ResponseWriter, r *http.Request) (int, bool) {
+	locale := s.getLocale(r)
+
+	customErr := s.checkAdmin(r)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return 0, false
+	}
+
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (s *Server) exportAccount(w http.ResponseWriter, r *http.Request) {
+	accountId, ok := s.adminAccountId(w, r)
+
+	if !ok {
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   s.accountService.Export(accountId),
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) eraseAccount(w http.ResponseWriter, r *http.Request) {
+	accountId, ok := s.adminAccountId(w, r)
+
+	if !ok {
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   s.accountService.Erase(accountId),
+	}
+
+	s.jsonResponse(w, response)
+}
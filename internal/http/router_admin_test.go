@@ -0,0 +1,38 @@
+package http
+
+import (
+	"github.com/proviant-io/core/internal/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckAdmin(t *testing.T) {
+
+	s := newTestServer()
+	s.di.Cfg = &config.Config{Mode: config.ModeApi, Admin: config.Admin{Token: "secret"}}
+
+	check := func(header string) int {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/diagnostics/", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+
+		if customErr := s.checkAdmin(r); customErr != nil {
+			return customErr.Code()
+		}
+
+		return http.StatusOK
+	}
+
+	assert.Equal(t, http.StatusOK, check("Bearer secret"))
+	assert.Equal(t, http.StatusUnauthorized, check("secret"))
+	assert.Equal(t, http.StatusUnauthorized, check("bearer secret"))
+	assert.Equal(t, http.StatusUnauthorized, check("Bearer  secret"))
+	assert.Equal(t, http.StatusUnauthorized, check("Bearer other"))
+	assert.Equal(t, http.StatusUnauthorized, check(""))
+
+	s.di.Cfg.Admin.Token = ""
+	assert.Equal(t, http.StatusForbidden, check("Bearer secret"))
+}
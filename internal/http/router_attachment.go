@@ -0,0 +1,203 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/attachment"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// uploadAttachment streams document from multipart form to storage
+func (s *Server) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	if r.ContentLength > attachment.MaxSize {
+		s.handleError(w, locale, *errors.NewErrPayloadTooLarge(i18n.NewMessage("attachment should not be bigger than %d bytes", attachment.MaxSize)))
+		return
+	}
+
+	reader, err := r.MultipartReader()
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+
+		if err == io.EOF {
+			s.handleBadRequest(w, locale, "multipart field %s is required", mediaFormField)
+			return
+		}
+
+		if err != nil {
+			s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+			return
+		}
+
+		if part.FormName() != mediaFormField {
+			continue
+		}
+
+		dto, customErr := s.relation(r).AddAttachment(id, part.FileName(), part, accountId)
+
+		if customErr != nil {
+			s.handleError(w, locale, *customErr)
+			return
+		}
+
+		response := Response{
+			Status: ResponseCodeCreated,
+			Data:   dto,
+		}
+
+		s.jsonResponse(w, response)
+		return
+	}
+}
+
+func (s *Server) getAttachments(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	dtos, customErr := s.relation(r).GetAttachments(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   dtos,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// downloadAttachment serves document with type it was sniffed as, browser is not allowed to guess another one
+func (s *Server) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+
+	productId, id, ok := s.attachmentIds(w, r, locale)
+
+	if !ok {
+		return
+	}
+
+	model, buf, customErr := s.relation(r).OpenAttachment(productId, id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": model.Name})
+
+	if disposition == "" {
+		disposition = fmt.Sprintf("attachment; filename=%q", model.File)
+	}
+
+	w.Header().Set("Content-Type", model.Mime)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	// answers conditional and range requests
+	http.ServeContent(w, r, model.File, model.CreatedAt, bytes.NewReader(buf.Bytes()))
+}
+
+func (s *Server) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+
+	productId, id, ok := s.attachmentIds(w, r, locale)
+
+	if !ok {
+		return
+	}
+
+	customErr := s.relation(r).DeleteAttachment(productId, id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// attachmentIds reads product id and attachment id from path, bad request is answered otherwise
+func (s *Server) attachmentIds(w http.ResponseWriter, r *http.Request, locale i18n.Locale) (int, int, bool) {
+	vars := mux.Vars(r)
+	productIdString := vars["product_id"]
+
+	if productIdString == "" {
+		s.handleBadRequest(w, locale, "product id cannot be empty")
+		return 0, 0, false
+	}
+
+	productId, err := strconv.Atoi(productIdString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "product id is not a number: %v", err.Error())
+		return 0, 0, false
+	}
+
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return 0, 0, false
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return 0, 0, false
+	}
+
+	return productId, id, true
+}
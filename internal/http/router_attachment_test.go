@@ -0,0 +1,96 @@
+package http
+
+import (
+	"fmt"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/config"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/product_category"
+	"github.com/proviant-io/core/internal/pkg/service"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newDbTestServer is server in web mode backed by sqlite and local storage, like single node setup
+func newDbTestServer(t *testing.T) *Server {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "server.sqlite"))
+	assert.NoError(t, err)
+
+	cfg := &config.Config{
+		Mode:        config.ModeWeb,
+		UserContent: config.UserContent{Mode: config.UserContentModeLocal, Location: t.TempDir()},
+	}
+
+	productRepo, err := product.Setup(d)
+	assert.NoError(t, err)
+
+	stockRepo, err := stock.Setup(d)
+	assert.NoError(t, err)
+
+	categoryRepo, err := category.Setup(d)
+	assert.NoError(t, err)
+
+	listRepo, err := list.Setup(d)
+	assert.NoError(t, err)
+
+	productCategoryRepo, err := product_category.Setup(d)
+	assert.NoError(t, err)
+
+	i, err := di.NewDI(d, cfg, &apm.NoopApm{}, logger.Default(), "test")
+	assert.NoError(t, err)
+
+	relationService := service.NewRelationService(productRepo, listRepo, categoryRepo, stockRepo, productCategoryRepo, i, *cfg)
+	accountService := service.NewAccountService(productRepo, listRepo, categoryRepo, stockRepo, productCategoryRepo, i)
+
+	return NewServer(productRepo, listRepo, categoryRepo, productCategoryRepo, stockRepo, relationService, accountService, i18n.NewFileLocalizer(), i)
+}
+
+func TestAttachmentOfOtherAccountIsRefused(t *testing.T) {
+
+	s := newDbTestServer(t)
+
+	fridge := s.relationService.CreateList(list.DTO{Title: "Fridge"}, 1, 1)
+
+	milk, customErr := s.relationService.CreateProduct(product.CreateDTO{Title: "Milk", ListId: fridge.Id}, 1, 1)
+	assert.Nil(t, customErr)
+
+	receipt, customErr := s.relationService.AddAttachment(milk.Id, "receipt.pdf", strings.NewReader("%PDF-1.4 receipt of account 1"), 1)
+	assert.Nil(t, customErr)
+
+	get := func(target string, accountId int) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("AccountId", fmt.Sprint(accountId))
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, r)
+		return w
+	}
+
+	stored, customErr := s.di.Attachment.Get(receipt.Id, milk.Id, 1)
+	assert.Nil(t, customErr)
+
+	download := fmt.Sprintf("/api/v1/product/%d/attachment/%d/download/", milk.Id, receipt.Id)
+
+	w := get(download, 1)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "receipt of account 1")
+
+	// neither through api, nor by file name the content is stored under
+	for _, target := range []string{download, "/content/" + stored.File, "/uc/img/" + stored.File} {
+		w = get(target, 2)
+		assert.NotEqual(t, http.StatusOK, w.Code, target)
+		assert.NotContains(t, w.Body.String(), "receipt of account 1", target)
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"github.com/proviant-io/core/internal/pkg/audit"
+	"net/http"
+	"strconv"
+)
+
+func (s *Server) getAuditLog(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+
+	query := audit.Query{
+		Entity: r.URL.Query().Get("entity"),
+		Action: r.URL.Query().Get("action"),
+	}
+
+	intFilters := map[string]*int{
+		"entity_id": &query.EntityId,
+		"user_id":   &query.UserId,
+		"limit":     &query.Limit,
+		"offset":    &query.Offset,
+	}
+
+	for name, target := range intFilters {
+		raw := r.URL.Query().Get(name)
+
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+
+		if err != nil {
+			s.handleBadRequest(w, locale, "%s is not a number: %v", name, err.Error())
+			return
+		}
+
+		*target = value
+	}
+
+	timeFilters := map[string]*int64{
+		"from": &query.From,
+		"to":   &query.To,
+	}
+
+	for name, target := range timeFilters {
+		raw := r.URL.Query().Get(name)
+
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.ParseInt(raw, 10, 64)
+
+		if err != nil {
+			s.handleBadRequest(w, locale, "%s is not a number: %v", name, err.Error())
+			return
+		}
+
+		*target = value
+	}
+
+	models := s.di.Audit.GetAll(query, accountId)
+
+	dtos := []audit.DTO{}
+
+	for _, model := range models {
+		dtos = append(dtos, audit.ModelToDTO(model))
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   dtos,
+	}
+
+	s.jsonResponse(w, response)
+}
@@ -0,0 +1,509 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/service"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+)
+
+const batchMaxOperations = 100
+
+// streams never finish, uploads are not json and account maintenance does not take part in transaction,
+// so they cannot be batched
+var batchExcludedRoutes = map[string]bool{
+	"GET /shopping_list/{id}/events/":                     true,
+	"POST /media/":                                        true,
+	"POST /product/{id}/attachment/":                      true,
+	"GET /product/{product_id}/attachment/{id}/download/": true,
+	"GET /admin/account/{id}/export/":                     true,
+	"DELETE /admin/account/{id}/":                         true,
+	"GET /admin/diagnostics/":                             true,
+}
+
+// reference to result of earlier operation, e.g. ${milk.id} is id from data of operation with ref milk
+var batchReference = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)((?:\.[A-Za-z0-9_]+)*)\}`)
+
+var batchRefName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// returned from transaction to roll it back when operation fails
+var errBatchFailed = fmt.Errorf("batch operation failed")
+
+type BatchOperation struct {
+	// Ref names operation, so later operations can reference its result
+	Ref    string `json:"ref" validate:"max=64"`
+	Method string `json:"method" validate:"required"`
+	// Path is relative to api prefix, it may contain references like /product/${milk.id}/add/
+	Path string `json:"path" validate:"required,max=2048"`
+	// Version is sent as If-Match header
+	Version int             `json:"version" validate:"nonnegative"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"required"`
+}
+
+type BatchOperationResult struct {
+	Ref    string `json:"ref,omitempty"`
+	Status int    `json:"status"`
+	// Body is response of operation as api would return it
+	Body json.RawMessage `json:"body"`
+}
+
+type BatchResult struct {
+	Committed bool                   `json:"committed"`
+	Results   []BatchOperationResult `json:"results"`
+}
+
+// BatchProblem is v2 answer to failed batch, results show which operation failed and why
+type BatchProblem struct {
+	Problem
+	Results []BatchOperationResult `json:"results"`
+}
+
+// batch executes operations one by one in single transaction, the first failed one rolls back all of them
+func (s *Server) batch(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	dto := BatchRequest{}
+
+	err := s.parseJSON(r, &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	if fields := s.checkOperations("operations", dto.Operations, accountId); len(fields) > 0 {
+		s.handleError(w, locale, *errors.NewErrValidation(fields...))
+		return
+	}
+
+	result := BatchResult{Results: []BatchOperationResult{}}
+	var customErr *errors.CustomError
+	var txServer *Server
+
+	err = db.Transaction(s.di.DB, func(tx db.DB) error {
+
+		txServer = s.withDB(tx)
+		router := txServer.batchRouter(s.isApiV2(w))
+		data := map[string]interface{}{}
+
+		for idx, op := range dto.Operations {
+			var opResult BatchOperationResult
+
+			opResult, customErr = txServer.executeOperation(router, r, idx, op, data)
+
+			if customErr != nil {
+				return errBatchFailed
+			}
+
+			result.Results = append(result.Results, opResult)
+
+			if opResult.Status >= BadRequest {
+				return errBatchFailed
+			}
+		}
+
+		return nil
+	})
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	if err == errBatchFailed {
+		s.batchFailed(w, locale, result)
+		return
+	}
+
+	if err != nil {
+		s.handleError(w, locale, *errors.NewInternalServer(i18n.NewMessage(err.Error())))
+		return
+	}
+
+	// changes are announced only once they are committed
+	txServer.di.Flush()
+
+	result.Committed = true
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   result,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// batchFailed answers with status of failed operation, which is the last one in results
+func (s *Server) batchFailed(w http.ResponseWriter, locale i18n.Locale, result BatchResult) {
+
+	failed := len(result.Results) - 1
+	status := result.Results[failed].Status
+	message := s.l.T(i18n.NewMessage("batch operation %d failed, nothing was applied", failed), locale)
+
+	if s.isApiV2(w) {
+		s.writeJSON(w, ProblemContentType, status, BatchProblem{
+			Problem: Problem{
+				Type:   problemType(status),
+				Title:  http.StatusText(status),
+				Status: status,
+				Detail: message,
+			},
+			Results: result.Results,
+		})
+		return
+	}
+
+	s.writeJSON(w, "application/json", status, Response{
+		Status: status,
+		Data:   result,
+		Error:  message,
+	})
+}
+
+// checkOperations reports everything which can be found out before execution: invalid operations,
+// unknown routes and references to operations which do not precede the one which references them
+func (s *Server) checkOperations(name string, operations []BatchOperation, accountId int) []errors.FieldError {
+
+	var fields []errors.FieldError
+
+	field := func(idx int, fieldName string, template string, params ...interface{}) {
+		fields = append(fields, errors.FieldError{
+			Field:   fmt.Sprintf("%s[%d].%s", name, idx, fieldName),
+			Message: i18n.NewMessage(template, params...),
+		})
+	}
+
+	if len(operations) > batchMaxOperations {
+		return []errors.FieldError{{
+			Field:   name,
+			Message: i18n.NewMessage("%s should not contain more than %d items", name, batchMaxOperations),
+		}}
+	}
+
+	router := s.batchRouter(false)
+	refs := map[string]bool{}
+
+	for idx, op := range operations {
+
+		invalid := s.validator.Validate(op, accountId)
+
+		for _, f := range invalid {
+			f.Field = fmt.Sprintf("%s[%d].%s", name, idx, f.Field)
+			fields = append(fields, f)
+		}
+
+		if len(invalid) > 0 {
+			continue
+		}
+
+		for _, name := range referencedNames(op.Path + string(op.Body)) {
+			if !refs[name] {
+				field(idx, "path", "unknown reference %s", name)
+			}
+		}
+
+		if op.Ref != "" {
+			if !batchRefName.MatchString(op.Ref) {
+				field(idx, "ref", "%s should contain only latin letters, digits and underscores", "ref")
+			}
+			if refs[op.Ref] {
+				field(idx, "ref", "reference %s is already used", op.Ref)
+			}
+			refs[op.Ref] = true
+		}
+
+		req, err := http.NewRequest(strings.ToUpper(op.Method), batchPath(op.Path), nil)
+
+		if err != nil {
+			field(idx, "path", "parse payload error: %v", err.Error())
+			continue
+		}
+
+		var match mux.RouteMatch
+
+		if !router.Match(req, &match) || match.MatchErr != nil {
+			field(idx, "path", "route %s %s does not exist", req.Method, op.Path)
+			continue
+		}
+
+		template, _ := match.Route.GetPathTemplate()
+
+		if batchExcludedRoutes[req.Method+" "+template] {
+			field(idx, "path", "%s %s cannot be batched", req.Method, template)
+		}
+	}
+
+	return fields
+}
+
+// executeOperation runs operation through api handlers, data of named operations is kept for references
+func (s *Server) executeOperation(router *mux.Router, outer *http.Request, idx int, op BatchOperation, data map[string]interface{}) (BatchOperationResult, *errors.CustomError) {
+
+	path, err := resolveReferences(op.Path, data)
+
+	if err != nil {
+		return BatchOperationResult{}, errors.NewErrUnprocessableEntity(i18n.NewMessage("operation %d: %s", idx, err.Error()))
+	}
+
+	body, err := resolveBodyReferences(op.Body, data)
+
+	if err != nil {
+		return BatchOperationResult{}, errors.NewErrUnprocessableEntity(i18n.NewMessage("operation %d: %s", idx, err.Error()))
+	}
+
+	req, err := http.NewRequestWithContext(outer.Context(), strings.ToUpper(op.Method), batchPath(path), bytes.NewReader(body))
+
+	if err != nil {
+		return BatchOperationResult{}, errors.NewErrBadRequest(i18n.NewMessage("operation %d: %s", idx, err.Error()))
+	}
+
+	for _, header := range []string{"AccountId", "UserId", "User-Locale"} {
+		req.Header.Set(header, outer.Header.Get(header))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if op.Version > 0 {
+		req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, op.Version))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	result := BatchOperationResult{
+		Ref:    op.Ref,
+		Status: rec.Code,
+		Body:   rec.Body.Bytes(),
+	}
+
+	if len(result.Body) == 0 {
+		result.Body = json.RawMessage("null")
+	}
+
+	if op.Ref != "" && result.Status < BadRequest {
+		data[op.Ref] = responseData(result.Body, rec.Header().Get(ApiVersionHeader) == ApiVersion2)
+	}
+
+	return result, nil
+}
+
+// batchRouter serves operations of batch, it has no middlewares since batch request already passed them
+func (s *Server) batchRouter(v2 bool) *mux.Router {
+
+	router := mux.NewRouter()
+
+	if v2 {
+		router.Use(s.apiV2Middleware)
+	}
+
+	s.registerApiRoutes(router)
+
+	return router
+}
+
+// withDB returns server which works through d, so handlers of batch operations take part in its transaction
+func (s *Server) withDB(d db.DB) *Server {
+
+	i := s.di.WithDB(d)
+
+	productRepo := s.productRepo.WithDB(d)
+	listRepo := s.listRepo.WithDB(d)
+	categoryRepo := s.categoryRepo.WithDB(d)
+	productCategoryRepo := s.productCategoryRepo.WithDB(d)
+	stockRepo := s.stockRepo.WithDB(d)
+
+	server := &Server{
+		productRepo:         productRepo,
+		listRepo:            listRepo,
+		categoryRepo:        categoryRepo,
+		productCategoryRepo: productCategoryRepo,
+		stockRepo:           stockRepo,
+		relationService:     service.NewRelationService(productRepo, listRepo, categoryRepo, stockRepo, productCategoryRepo, i, *i.Cfg),
+		accountService:      s.accountService,
+		l:                   s.l,
+		cfg:                 s.cfg,
+		di:                  i,
+	}
+
+	server.validator = server.newValidator()
+
+	return server
+}
+
+// batchPath accepts paths with and without api prefix
+func batchPath(path string) string {
+
+	for _, prefix := range []string{apiV1Prefix, "/api/v2"} {
+		if strings.HasPrefix(path, prefix+"/") {
+			return strings.TrimPrefix(path, prefix)
+		}
+	}
+
+	return path
+}
+
+// responseData extracts data from v1 envelope, v2 responses are data themselves
+func responseData(body []byte, v2 bool) interface{} {
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+
+	if v2 {
+		return value
+	}
+
+	if envelope, ok := value.(map[string]interface{}); ok {
+		return envelope["data"]
+	}
+
+	return nil
+}
+
+func referencedNames(s string) []string {
+
+	var names []string
+
+	for _, match := range batchReference.FindAllStringSubmatch(s, -1) {
+		names = append(names, match[1])
+	}
+
+	return names
+}
+
+// lookupReference walks data of referenced operation by dot separated fields
+func lookupReference(match []string, data map[string]interface{}) (interface{}, error) {
+
+	value, ok := data[match[1]]
+
+	if !ok {
+		return nil, fmt.Errorf("reference %s cannot be resolved", match[0])
+	}
+
+	for _, name := range strings.Split(strings.TrimPrefix(match[2], "."), ".") {
+		if name == "" {
+			continue
+		}
+
+		object, ok := value.(map[string]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("reference %s cannot be resolved", match[0])
+		}
+
+		if value, ok = object[name]; !ok {
+			return nil, fmt.Errorf("reference %s cannot be resolved", match[0])
+		}
+	}
+
+	return value, nil
+}
+
+// resolveReferences replaces references in s with scalar values they point to
+func resolveReferences(s string, data map[string]interface{}) (string, error) {
+
+	var err error
+
+	resolved := batchReference.ReplaceAllStringFunc(s, func(reference string) string {
+
+		value, lookupErr := lookupReference(batchReference.FindStringSubmatch(reference), data)
+
+		if lookupErr != nil {
+			err = lookupErr
+			return reference
+		}
+
+		switch v := value.(type) {
+		case json.Number:
+			return v.String()
+		case string:
+			return v
+		case bool:
+			return fmt.Sprint(v)
+		default:
+			err = fmt.Errorf("reference %s is not a scalar value", reference)
+			return reference
+		}
+	})
+
+	return resolved, err
+}
+
+// resolveBodyReferences replaces references in string values of body. String which is reference as a whole
+// is replaced with referenced value itself, so "${milk.id}" becomes number.
+func resolveBodyReferences(body json.RawMessage, data map[string]interface{}) ([]byte, error) {
+
+	if len(body) == 0 || !batchReference.Match(body) {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var resolve func(value interface{}) (interface{}, error)
+
+	resolve = func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case string:
+			if match := batchReference.FindStringSubmatch(v); match != nil && match[0] == v {
+				return lookupReference(match, data)
+			}
+			return resolveReferences(v, data)
+		case map[string]interface{}:
+			for key, item := range v {
+				resolved, err := resolve(item)
+				if err != nil {
+					return nil, err
+				}
+				v[key] = resolved
+			}
+			return v, nil
+		case []interface{}:
+			for i, item := range v {
+				resolved, err := resolve(item)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = resolved
+			}
+			return v, nil
+		default:
+			return v, nil
+		}
+	}
+
+	resolved, err := resolve(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resolved)
+}
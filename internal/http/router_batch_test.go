@@ -0,0 +1,47 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResolveReferences(t *testing.T) {
+
+	data := map[string]interface{}{
+		"milk": responseData([]byte(`{"status": 201, "data": {"id": 5, "title": "Milk", "list": {"id": 2}}, "error": ""}`), false),
+		"list": responseData([]byte(`{"id": 7}`), true),
+	}
+
+	path, err := resolveReferences("/product/${milk.id}/add/", data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/product/5/add/", path)
+
+	path, err = resolveReferences("/list/${milk.list.id}/${list.id}/", data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/list/2/7/", path)
+
+	_, err = resolveReferences("/product/${milk.price}/", data)
+	assert.Error(t, err)
+
+	_, err = resolveReferences("/product/${milk.list}/", data)
+	assert.Error(t, err)
+
+	body, err := resolveBodyReferences(json.RawMessage(`{"product_id": "${milk.id}", "title": "buy ${milk.title}", "ids": ["${list.id}"]}`), data)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"product_id": 5, "title": "buy Milk", "ids": [7]}`, string(body))
+
+	body, err = resolveBodyReferences(json.RawMessage(`{"quantity": 2}`), data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"quantity": 2}`, string(body))
+}
+
+func TestBatchPath(t *testing.T) {
+	assert.Equal(t, "/product/", batchPath("/product/"))
+	assert.Equal(t, "/product/", batchPath("/api/v1/product/"))
+	assert.Equal(t, "/product/", batchPath("/api/v2/product/"))
+}
@@ -34,6 +34,8 @@ func (s *Server) getCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.setETag(w, model.Version)
+
 	response := Response{
 		Status: ResponseCodeOk,
 		Data:   category.ModelToDTO(model),
@@ -63,6 +65,7 @@ func (s *Server) getCategories(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) deleteCategory(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
@@ -80,7 +83,14 @@ func (s *Server) deleteCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	customErr := s.relationService.DeleteCategory(id, accountId)
+	version, err := s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	customErr := s.relation(r).DeleteCategory(id, version, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
@@ -96,6 +106,7 @@ func (s *Server) deleteCategory(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) createCategory(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 
 	locale := s.getLocale(r)
 	dto := category.DTO{}
@@ -109,16 +120,17 @@ func (s *Server) createCategory(w http.ResponseWriter, r *http.Request) {
 
 	dto.Title = utils.ClearString(dto.Title)
 
-	if dto.Title == "" {
-		s.handleBadRequest(w, locale, "title should not be empty")
+	if !s.validate(w, locale, dto, accountId) {
 		return
 	}
 
-	model := s.categoryRepo.Create(dto, accountId)
+	data := s.relation(r).CreateCategory(dto, accountId, userId)
+
+	s.setETag(w, data.Version)
 
 	response := Response{
 		Status: ResponseCodeCreated,
-		Data:   category.ModelToDTO(model),
+		Data:   data,
 	}
 
 	s.jsonResponse(w, response)
@@ -126,6 +138,7 @@ func (s *Server) createCategory(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) updateCategory(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
@@ -153,21 +166,94 @@ func (s *Server) updateCategory(w http.ResponseWriter, r *http.Request) {
 
 	dto.Title = utils.ClearString(dto.Title)
 
-	if dto.Title == "" {
-		s.handleBadRequest(w, locale, "title should not be empty")
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
 		return
 	}
 
-	model, customErr := s.categoryRepo.Update(id, dto, accountId)
+	data, customErr := s.relation(r).UpdateCategory(id, dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, data.Version)
+
 	response := Response{
 		Status: ResponseCodeOk,
-		Data:   category.ModelToDTO(model),
+		Data:   data,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// patchCategory changes only fields present in JSON Merge Patch body
+func (s *Server) patchCategory(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	userId := s.userId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	current, customErr := s.categoryRepo.Get(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	dto := category.DTO{}
+
+	err = s.parseMergePatch(r, category.ModelToDTO(current), &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	dto.Title = utils.ClearString(dto.Title)
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	data, customErr := s.relation(r).UpdateCategory(id, dto, accountId, userId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, data.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   data,
 	}
 
 	s.jsonResponse(w, response)
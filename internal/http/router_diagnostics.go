@@ -0,0 +1,130 @@
+package http
+
+import (
+	"github.com/proviant-io/core/internal/health"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+type Diagnostics struct {
+	Build    BuildInfo     `json:"build"`
+	Config   ConfigSummary `json:"config"`
+	Database PoolStats     `json:"database"`
+	Runtime  RuntimeStats  `json:"runtime"`
+	// Health is report of readiness probe
+	Health health.Report `json:"health"`
+}
+
+type BuildInfo struct {
+	Version       string    `json:"version"`
+	GoVersion     string    `json:"go_version"`
+	Platform      string    `json:"platform"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+// ConfigSummary is built from redacted config, so secrets are never returned
+type ConfigSummary struct {
+	Mode             string `json:"mode"`
+	DbDriver         string `json:"db_driver"`
+	DbDsn            string `json:"db_dsn"`
+	UserContentMode  string `json:"user_content_mode"`
+	ApmVendor        string `json:"apm_vendor"`
+	GrpcPort         int    `json:"grpc_port"`
+	RateLimit        bool   `json:"rate_limit"`
+	RateLimitBackend string `json:"rate_limit_backend"`
+	OpenApiValidate  bool   `json:"openapi_validate"`
+	LogLevel         string `json:"log_level"`
+	LogFormat        string `json:"log_format"`
+}
+
+// PoolStats are statistics of database connection pool
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+type RuntimeStats struct {
+	Goroutines     int    `json:"goroutines"`
+	GoMaxProcs     int    `json:"gomaxprocs"`
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+}
+
+// getDiagnostics helps to look into running server, it is protected by admin token as it reveals its setup
+func (s *Server) getDiagnostics(w http.ResponseWriter, r *http.Request) {
+	locale := s.getLocale(r)
+
+	customErr := s.checkAdmin(r)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	cfg := s.di.Cfg.Redacted()
+
+	memStats := runtime.MemStats{}
+	runtime.ReadMemStats(&memStats)
+
+	diagnostics := Diagnostics{
+		Build: BuildInfo{
+			Version:       s.di.Version,
+			GoVersion:     runtime.Version(),
+			Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+			StartedAt:     s.started,
+			UptimeSeconds: int64(time.Since(s.started).Seconds()),
+		},
+		Config: ConfigSummary{
+			Mode:             cfg.Mode,
+			DbDriver:         cfg.Db.Driver,
+			DbDsn:            cfg.Db.Dsn,
+			UserContentMode:  cfg.UserContent.Mode,
+			ApmVendor:        cfg.APM.Vendor,
+			GrpcPort:         cfg.Grpc.Port,
+			RateLimit:        cfg.RateLimit.Enabled,
+			RateLimitBackend: cfg.RateLimit.Backend,
+			OpenApiValidate:  cfg.OpenApi.Validate,
+			LogLevel:         cfg.Log.Level,
+			LogFormat:        cfg.Log.Format,
+		},
+		Runtime: RuntimeStats{
+			Goroutines:     runtime.NumGoroutine(),
+			GoMaxProcs:     runtime.GOMAXPROCS(0),
+			HeapAllocBytes: memStats.HeapAlloc,
+			SysBytes:       memStats.Sys,
+			NumGC:          memStats.NumGC,
+		},
+		Health: health.Run(r.Context(), s.readinessChecks()),
+	}
+
+	if pool, err := s.di.DB.Connection().DB(); err == nil {
+		stats := pool.Stats()
+
+		diagnostics.Database = PoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		}
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   diagnostics,
+	}
+
+	s.jsonResponse(w, response)
+}
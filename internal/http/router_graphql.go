@@ -0,0 +1,31 @@
+package http
+
+import (
+	"github.com/proviant-io/core/internal/graphql"
+	"net/http"
+)
+
+// graphql executes GraphQL request, response follows GraphQL over http convention instead of api envelope
+func (s *Server) graphql(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	userId := s.userId(r)
+	locale := s.getLocale(r)
+
+	request := graphql.Request{}
+
+	err := s.parseJSON(r, &request)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	if request.Query == "" {
+		s.handleBadRequest(w, locale, "query cannot be empty")
+		return
+	}
+
+	result := s.graphqlSchema.Do(r.Context(), request, accountId, userId, locale)
+
+	s.writeJSON(w, "application/json", ResponseCodeOk, result)
+}
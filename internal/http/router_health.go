@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/health"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"net/http"
+)
+
+// probes are meant for orchestrators, like metrics they are served outside of api, so they are neither
+// rate limited nor scoped by account
+const (
+	livenessPath  = "/health/live"
+	readinessPath = "/health/ready"
+)
+
+// getLiveness fails when background worker is stuck, restart is the only thing which helps then
+func (s *Server) getLiveness(w http.ResponseWriter, r *http.Request) {
+	s.writeHealth(w, health.Run(r.Context(), s.di.Health.Checks()))
+}
+
+// getReadiness fails while server cannot serve requests, e.g. database is down or storage is not reachable
+func (s *Server) getReadiness(w http.ResponseWriter, r *http.Request) {
+	s.writeHealth(w, health.Run(r.Context(), s.readinessChecks()))
+}
+
+func (s *Server) readinessChecks() []health.Check {
+
+	checks := []health.Check{
+		{Name: "database", Run: func(ctx context.Context) error {
+			return db.Ping(ctx, s.di.DB)
+		}},
+		{Name: "migrations", Run: func(context.Context) error {
+			return s.di.SchemaErr
+		}},
+	}
+
+	if pinger, ok := s.di.ImageSaver.(image.Pinger); ok {
+		checks = append(checks, health.Check{Name: "storage", Run: pinger.Ping})
+	}
+
+	return append(checks, s.di.Health.Checks()...)
+}
+
+func (s *Server) writeHealth(w http.ResponseWriter, report health.Report) {
+
+	status := http.StatusOK
+
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeJSON(w, "application/json", status, report)
+}
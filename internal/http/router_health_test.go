@@ -0,0 +1,43 @@
+package http
+
+import (
+	"fmt"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/config"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/health"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadinessReportsSchemaCheckedAtStartup(t *testing.T) {
+
+	d, err := db.NewSQLite(":memory:")
+	assert.NoError(t, err)
+
+	i := &di.DI{
+		DB:     d,
+		Cfg:    &config.Config{Mode: config.ModeApi},
+		Apm:    &apm.NoopApm{},
+		Logger: logger.Default(),
+		Health: health.NewRegistry(),
+	}
+
+	s := NewServer(nil, nil, nil, nil, nil, nil, nil, i18n.NewFileLocalizer(), i)
+
+	w := httptest.NewRecorder()
+	s.getReadiness(w, httptest.NewRequest(http.MethodGet, readinessPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	i.SchemaErr = fmt.Errorf("column products.barcode is missing")
+
+	w = httptest.NewRecorder()
+	s.getReadiness(w, httptest.NewRequest(http.MethodGet, readinessPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "column products.barcode is missing")
+}
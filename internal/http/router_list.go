@@ -33,6 +33,8 @@ func (s *Server) getList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.setETag(w, model.Version)
+
 	response := Response{
 		Status: ResponseCodeOk,
 		Data:   list.ModelToDTO(model),
@@ -61,6 +63,7 @@ func (s *Server) getLists(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) deleteList(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	idString := vars["id"]
@@ -77,7 +80,14 @@ func (s *Server) deleteList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	customErr := s.relationService.DeleteList(id, accountId)
+	version, err := s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	customErr := s.relation(r).DeleteList(id, version, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
@@ -93,6 +103,7 @@ func (s *Server) deleteList(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) createList(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	dto := list.DTO{}
 
@@ -105,16 +116,17 @@ func (s *Server) createList(w http.ResponseWriter, r *http.Request) {
 
 	dto.Title = utils.ClearString(dto.Title)
 
-	if dto.Title == "" {
-		s.handleBadRequest(w, locale, "title should not be empty")
+	if !s.validate(w, locale, dto, accountId) {
 		return
 	}
 
-	model := s.listRepo.Create(dto, accountId)
+	data := s.relation(r).CreateList(dto, accountId, userId)
+
+	s.setETag(w, data.Version)
 
 	response := Response{
 		Status: ResponseCodeCreated,
-		Data:   list.ModelToDTO(model),
+		Data:   data,
 	}
 
 	s.jsonResponse(w, response)
@@ -122,6 +134,7 @@ func (s *Server) createList(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) updateList(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	idString := vars["id"]
@@ -148,21 +161,94 @@ func (s *Server) updateList(w http.ResponseWriter, r *http.Request) {
 
 	dto.Title = utils.ClearString(dto.Title)
 
-	if dto.Title == "" {
-		s.handleBadRequest(w, locale, "title should not be empty")
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
 		return
 	}
 
-	model, customErr := s.listRepo.Update(id, dto, accountId)
+	data, customErr := s.relation(r).UpdateList(id, dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, data.Version)
+
 	response := Response{
 		Status: ResponseCodeOk,
-		Data:   list.ModelToDTO(model),
+		Data:   data,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// patchList changes only fields present in JSON Merge Patch body
+func (s *Server) patchList(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	userId := s.userId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	current, customErr := s.listRepo.Get(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	dto := list.DTO{}
+
+	err = s.parseMergePatch(r, list.ModelToDTO(current), &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	dto.Title = utils.ClearString(dto.Title)
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	data, customErr := s.relation(r).UpdateList(id, dto, accountId, userId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, data.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   data,
 	}
 
 	s.jsonResponse(w, response)
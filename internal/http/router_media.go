@@ -0,0 +1,224 @@
+package http
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"github.com/proviant-io/core/internal/pkg/media"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// mediaFormField is multipart field upload is read from
+const mediaFormField = "file"
+
+// uploadMedia streams image from multipart form to storage, product refers to it by media id afterwards
+func (s *Server) uploadMedia(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+
+	tooLarge := errors.NewErrPayloadTooLarge(i18n.NewMessage("image should not be bigger than %d bytes", image.MaxSize))
+
+	// declared length is checked before anything is read, the actual one is counted while streaming
+	if r.ContentLength > image.MaxSize {
+		s.handleError(w, locale, *tooLarge)
+		return
+	}
+
+	reader, err := r.MultipartReader()
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+
+		if err == io.EOF {
+			s.handleBadRequest(w, locale, "multipart field %s is required", mediaFormField)
+			return
+		}
+
+		if err != nil {
+			s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+			return
+		}
+
+		if part.FormName() != mediaFormField {
+			continue
+		}
+
+		upload := image.NewUpload(part, part.Header.Get("Content-Type"), image.MaxSize)
+
+		dto, err := s.relation(r).SaveMedia(upload, accountId)
+
+		if upload.Exceeded() {
+			s.handleError(w, locale, *tooLarge)
+			return
+		}
+
+		if _, ok := err.(*image.ParseError); ok {
+			s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+			return
+		}
+
+		if err != nil {
+			s.handleError(w, locale, *errors.NewInternalServer(i18n.NewMessage(err.Error())))
+			return
+		}
+
+		response := Response{
+			Status: ResponseCodeCreated,
+			Data:   s.signMedia(dto),
+		}
+
+		s.jsonResponse(w, response)
+		return
+	}
+}
+
+func (s *Server) getMedia(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	model, customErr := s.di.Media.Get(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   s.signMedia(media.ModelToDTO(model)),
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// signMedia adds url which gives access to media without account, e.g. for img tags
+func (s *Server) signMedia(dto media.DTO) media.DTO {
+
+	signedUrl, expiresAt := s.di.MediaSigner.Sign(dto.Url, time.Now())
+
+	if signedUrl != "" {
+		dto.SignedUrl = signedUrl
+		dto.ExpiresAt = expiresAt.Unix()
+	}
+
+	return dto
+}
+
+func (s *Server) getProductMedia(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	dtos, customErr := s.relation(r).GetProductGallery(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	for i := range dtos {
+		dtos[i] = s.signMedia(dtos[i])
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   dtos,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// setProductMedia replaces photos of product, the first one becomes image of product
+func (s *Server) setProductMedia(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	userId := s.userId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	dto := product.GalleryDTO{}
+
+	err = s.parseJSON(r, &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	version, err := s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	productDTO, customErr := s.relation(r).SetProductGallery(id, version, dto.MediaIds, accountId, userId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, productDTO.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   productDTO,
+	}
+
+	s.jsonResponse(w, response)
+}
@@ -26,13 +26,15 @@ func (s *Server) getProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	p, customErr := s.relationService.GetProduct(id, accountId)
+	p, customErr := s.relation(r).GetProduct(id, accountId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, p.Version)
+
 	response := Response{
 		Status: ResponseCodeOk,
 		Data:   p,
@@ -82,7 +84,7 @@ func (s *Server) getProducts(w http.ResponseWriter, r *http.Request) {
 		query.Category = categoryFilter
 	}
 
-	dtos := s.relationService.GetAllProducts(query, accountId)
+	dtos := s.relation(r).GetAllProducts(query, accountId)
 
 	response := Response{
 		Status: ResponseCodeOk,
@@ -94,6 +96,7 @@ func (s *Server) getProducts(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) deleteProduct(w http.ResponseWriter, r *http.Request){
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	idString := vars["id"]
@@ -110,7 +113,14 @@ func (s *Server) deleteProduct(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
-	customErr := s.relationService.DeleteProduct(id, accountId)
+	version, err := s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	customErr := s.relation(r).DeleteProduct(id, version, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
@@ -126,6 +136,7 @@ func (s *Server) deleteProduct(w http.ResponseWriter, r *http.Request){
 
 func (s *Server) createProduct(w http.ResponseWriter, r *http.Request){
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	dto := product.CreateDTO{}
 
@@ -139,13 +150,19 @@ func (s *Server) createProduct(w http.ResponseWriter, r *http.Request){
 	dto.Title = utils.ClearString(dto.Title)
 	dto.Image = ""
 
-	productDto, customErr := s.relationService.CreateProduct(dto, accountId)
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	productDto, customErr := s.relation(r).CreateProduct(dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, productDto.Version)
+
 	response := Response{
 		Status: ResponseCodeCreated,
 		Data:   productDto,
@@ -156,6 +173,7 @@ func (s *Server) createProduct(w http.ResponseWriter, r *http.Request){
 
 func (s *Server) updateProduct(w http.ResponseWriter, r *http.Request){
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	idString := vars["id"]
@@ -183,17 +201,106 @@ func (s *Server) updateProduct(w http.ResponseWriter, r *http.Request){
 	dto.Id = id
 	dto.Title = utils.ClearString(dto.Title)
 
-	productDTO, customErr := s.relationService.UpdateProduct(dto, accountId)
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	productDTO, customErr := s.relation(r).UpdateProduct(dto, accountId, userId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, productDTO.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   productDTO,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// patchProduct changes only fields present in JSON Merge Patch body, omitted fields keep their values
+func (s *Server) patchProduct(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	userId := s.userId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	current, customErr := s.relation(r).GetProduct(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	dto := product.UpdateDTO{}
+
+	err = s.parseMergePatch(r, product.UpdateDTO{
+		Title:       current.Title,
+		Description: current.Description,
+		Link:        current.Link,
+		Image:       current.Image,
+		MediaId:     current.MediaId,
+		Barcode:     current.Barcode,
+		CategoryIds: current.CategoryIds,
+		ListId:      current.ListId,
+		Price:       current.Price,
+	}, &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	dto.Id = id
+	dto.Title = utils.ClearString(dto.Title)
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	productDTO, customErr := s.relation(r).UpdateProduct(dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, productDTO.Version)
+
 	response := Response{
 		Status: ResponseCodeOk,
 		Data:   productDTO,
 	}
 
 	s.jsonResponse(w, response)
-}
\ No newline at end of file
+}
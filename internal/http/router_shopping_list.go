@@ -2,6 +2,8 @@ package http
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
 	"github.com/proviant-io/core/internal/pkg/shopping"
 	"github.com/proviant-io/core/internal/utils"
 	"net/http"
@@ -54,7 +56,7 @@ func (s *Server) getShoppingList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, customErr := s.relationService.GetShoppingList(id, accountId)
+	data, customErr := s.relation(r).GetShoppingList(id, accountId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
@@ -69,8 +71,62 @@ func (s *Server) getShoppingList(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, response)
 }
 
+func (s *Server) getShoppingListItem(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	listIdString := vars["list_id"]
+
+	if listIdString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	listId, err := strconv.Atoi(listIdString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	model, customErr := s.di.ShoppingListItem.Get(id, accountId)
+
+	if customErr == nil && model.ListId != listId {
+		customErr = errors.NewErrNotFound(i18n.NewMessage("shopping list item with id %d not found", id))
+	}
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, model.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   shopping.ItemToDTO(model),
+	}
+
+	s.jsonResponse(w, response)
+}
+
 func (s *Server) addShoppingListItem(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	listIdString := vars["id"]
@@ -98,23 +154,19 @@ func (s *Server) addShoppingListItem(w http.ResponseWriter, r *http.Request) {
 
 	dto.Title = utils.ClearString(dto.Title)
 
-	if dto.Title == "" {
-		s.handleBadRequest(w, locale, "title should not be empty")
+	if !s.validate(w, locale, dto, accountId) {
 		return
 	}
 
-	if dto.Quantity == 0 {
-		s.handleBadRequest(w, locale, "quantity should not be 0")
-		return
-	}
-
-	data, customErr := s.relationService.AddShoppingListItem(listId, dto, accountId)
+	data, customErr := s.relation(r).AddShoppingListItem(listId, dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, data.Version)
+
 	response := Response{
 		Status: ResponseCodeCreated,
 		Data:   data,
@@ -125,6 +177,7 @@ func (s *Server) addShoppingListItem(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) updateShoppingListItem(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	listIdString := vars["list_id"]
@@ -168,23 +221,26 @@ func (s *Server) updateShoppingListItem(w http.ResponseWriter, r *http.Request)
 
 	dto.Title = utils.ClearString(dto.Title)
 
-	if dto.Title == "" {
-		s.handleBadRequest(w, locale, "title should not be empty")
+	if !s.validate(w, locale, dto, accountId) {
 		return
 	}
-	
-	if dto.Quantity == 0 {
-		s.handleBadRequest(w, locale, "quantity should not be 0")
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
 		return
 	}
 
-	data, customErr := s.relationService.UpdateShoppingListItem(listId, dto, accountId)
+	data, customErr := s.relation(r).UpdateShoppingListItem(listId, dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, data.Version)
+
 	response := Response{
 		Status: ResponseCodeOk,
 		Data:   data,
@@ -194,6 +250,7 @@ func (s *Server) updateShoppingListItem(w http.ResponseWriter, r *http.Request)
 }
 func (s *Server) deleteShoppingListItem(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	idString := vars["id"]
@@ -210,7 +267,14 @@ func (s *Server) deleteShoppingListItem(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	customErr := s.di.ShoppingListItem.Delete(id, accountId)
+	version, err := s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	customErr := s.relation(r).DeleteShoppingListItem(id, version, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
@@ -234,6 +298,7 @@ func (s *Server) uncheckShoppingListItem(w http.ResponseWriter, r *http.Request)
 
 func (s *Server) updateCheckedShoppingListItem(w http.ResponseWriter, r *http.Request, checked bool) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	listIdString := vars["list_id"]
@@ -264,17 +329,111 @@ func (s *Server) updateCheckedShoppingListItem(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	data, customErr := s.relationService.UpdateCheckedShoppingListItem(id, checked, accountId)
+	version, err := s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	data, customErr := s.relation(r).UpdateCheckedShoppingListItem(id, version, checked, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, data.Version)
+
 	response := Response{
 		Status: ResponseCodeCreated,
 		Data:   data,
 	}
 
 	s.jsonResponse(w, response)
-}
\ No newline at end of file
+}
+
+// patchShoppingListItem changes only fields present in JSON Merge Patch body
+func (s *Server) patchShoppingListItem(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	userId := s.userId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	listIdString := vars["list_id"]
+
+	if listIdString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	listId, err := strconv.Atoi(listIdString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	current, customErr := s.di.ShoppingListItem.Get(id, accountId)
+
+	if customErr == nil && current.ListId != listId {
+		customErr = errors.NewErrNotFound(i18n.NewMessage("shopping list item with id %d not found", id))
+	}
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	dto := shopping.ItemDTO{}
+
+	err = s.parseMergePatch(r, shopping.ItemToDTO(current), &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	dto.Id = id
+	dto.Title = utils.ClearString(dto.Title)
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	data, customErr := s.relation(r).UpdateShoppingListItem(listId, dto, accountId, userId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, data.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   data,
+	}
+
+	s.jsonResponse(w, response)
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	streamPollInterval      = time.Second
+	streamHeartbeatInterval = 15 * time.Second
+	streamBatch             = 100
+	streamRetryMs           = 3000
+)
+
+const eventSnapshot = "snapshot"
+
+// streamShoppingList pushes list changes as server-sent events. Clients resume via Last-Event-ID header
+// (sent by EventSource automatically) or last_event_id query param, without it stream starts with a snapshot.
+func (s *Server) streamShoppingList(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	listId, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	lastEventId := 0
+	lastEventIdRaw := r.Header.Get("Last-Event-ID")
+
+	if lastEventIdRaw == "" {
+		lastEventIdRaw = r.URL.Query().Get("last_event_id")
+	}
+
+	if lastEventIdRaw != "" {
+		lastEventId, err = strconv.Atoi(lastEventIdRaw)
+
+		if err != nil {
+			s.handleBadRequest(w, locale, "last event id is not a number: %v", err.Error())
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		s.handleError(w, locale, *errors.NewInternalServer(i18n.NewMessage("streaming is not supported")))
+		return
+	}
+
+	// subscribe before reading snapshot, so nothing published in between is lost
+	notify, unsubscribe := s.di.ShoppingListEvent.Subscribe(listId)
+	defer unsubscribe()
+
+	if lastEventIdRaw == "" {
+		lastEventId = s.di.ShoppingListEvent.LastSequence()
+	}
+
+	snapshot, customErr := s.relation(r).GetShoppingList(listId, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", streamRetryMs)
+	if err != nil {
+		return
+	}
+
+	if lastEventIdRaw == "" {
+		err = s.writeServerSentEvent(w, r, lastEventId, eventSnapshot, snapshot)
+		if err != nil {
+			return
+		}
+	}
+
+	flusher.Flush()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		for _, event := range s.di.ShoppingListEvent.GetAfter(listId, accountId, lastEventId, streamBatch) {
+			// sequence is the cursor, id could be overtaken by event committed earlier
+			err = s.writeServerSentEvent(w, r, *event.Sequence, event.Type, json.RawMessage(event.Payload))
+			if err != nil {
+				return
+			}
+			lastEventId = *event.Sequence
+		}
+
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-notify:
+		case <-poll.C:
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) writeServerSentEvent(w http.ResponseWriter, r *http.Request, id int, event string, data interface{}) error {
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		s.log(r).Error("cannot marshal server sent event", "event_id", id, "error", err)
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, payload)
+
+	return err
+}
@@ -2,6 +2,8 @@ package http
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
 	"github.com/proviant-io/core/internal/pkg/consumption"
 	"github.com/proviant-io/core/internal/pkg/stock"
 	"net/http"
@@ -48,8 +50,60 @@ func (s *Server) getStock(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, response)
 }
 
+func (s *Server) getStockLot(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	productIdString := vars["product_id"]
+
+	if productIdString == "" {
+		s.handleBadRequest(w, locale, "product id cannot be empty")
+		return
+	}
+	productId, err := strconv.Atoi(productIdString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "product id is not a number: %v", err.Error())
+		return
+	}
+
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	model, customErr := s.stockRepo.Get(id, accountId)
+
+	if customErr == nil && model.ProductId != productId {
+		customErr = errors.NewErrNotFound(i18n.NewMessage("stock with id %d not found", id))
+	}
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, model.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   stock.ModelToDTO(model),
+	}
+
+	s.jsonResponse(w, response)
+}
+
 func (s *Server) addStock(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	idString := vars["id"]
@@ -76,23 +130,19 @@ func (s *Server) addStock(w http.ResponseWriter, r *http.Request) {
 
 	dto.ProductId = id
 
-	if dto.Quantity == 0 {
-		response := Response{
-			Status: BadRequest,
-			Error:  "quantity should not be 0",
-		}
-
-		s.jsonResponse(w, response)
+	if !s.validate(w, locale, dto, accountId) {
 		return
 	}
 
-	model, customErr := s.relationService.AddStock(dto, accountId)
+	model, customErr := s.relation(r).AddStock(dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
 		return
 	}
 
+	s.setETag(w, model.Version)
+
 	response := Response{
 		Status: ResponseCodeCreated,
 		Data:   stock.ModelToDTO(model),
@@ -103,7 +153,7 @@ func (s *Server) addStock(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) consumeStock(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
-	userId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	idString := vars["id"]
@@ -128,19 +178,13 @@ func (s *Server) consumeStock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if dto.Quantity == 0 {
-		response := Response{
-			Status: BadRequest,
-			Error:  "quantity should not be 0",
-		}
-
-		s.jsonResponse(w, response)
+	if !s.validate(w, locale, dto, accountId) {
 		return
 	}
 
 	dto.ProductId = id
 
-	customErr, consumedDTO := s.relationService.ConsumeStock(dto, accountId, userId)
+	customErr, consumedDTO := s.relation(r).ConsumeStock(dto, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
@@ -172,6 +216,7 @@ func (s *Server) consumeStock(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) deleteStock(w http.ResponseWriter, r *http.Request) {
 	accountId := s.accountId(r)
+	userId := s.userId(r)
 	locale := s.getLocale(r)
 	vars := mux.Vars(r)
 	productIdString := vars["product_id"]
@@ -200,7 +245,14 @@ func (s *Server) deleteStock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	customErr := s.relationService.DeleteStock(id, accountId)
+	version, err := s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	customErr := s.relation(r).DeleteStock(id, version, accountId, userId)
 
 	if customErr != nil {
 		s.handleError(w, locale, *customErr)
@@ -223,3 +275,83 @@ func (s *Server) deleteStock(w http.ResponseWriter, r *http.Request) {
 
 	s.jsonResponse(w, response)
 }
+
+// patchStockLot changes quantity or expiration of single stock lot with JSON Merge Patch body
+func (s *Server) patchStockLot(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	userId := s.userId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	productIdString := vars["product_id"]
+
+	if productIdString == "" {
+		s.handleBadRequest(w, locale, "product id cannot be empty")
+		return
+	}
+	productId, err := strconv.Atoi(productIdString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "product id is not a number: %v", err.Error())
+		return
+	}
+
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	current, customErr := s.stockRepo.Get(id, accountId)
+
+	if customErr == nil && current.ProductId != productId {
+		customErr = errors.NewErrNotFound(i18n.NewMessage("stock with id %d not found", id))
+	}
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	dto := stock.DTO{}
+
+	err = s.parseMergePatch(r, stock.ModelToDTO(current), &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	dto.Version, err = s.ifMatch(r)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "If-Match header is not a version: %v", err.Error())
+		return
+	}
+
+	data, customErr := s.relation(r).UpdateStock(id, dto, accountId, userId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.setETag(w, data.Version)
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   data,
+	}
+
+	s.jsonResponse(w, response)
+}
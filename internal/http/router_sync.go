@@ -0,0 +1,276 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/idempotency"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+)
+
+const (
+	SyncOutcomeApplied  = "applied"
+	SyncOutcomeConflict = "conflict"
+	SyncOutcomeRejected = "rejected"
+)
+
+// mutation ids share storage with idempotency keys of requests, prefix keeps them apart
+const syncKeyPrefix = "sync:"
+
+// SyncMutation is change made offline, it is applied through api like batch operation
+type SyncMutation struct {
+	// Id identifies mutation on client, mutation with known id is not applied again and gets its original result
+	Id     string `json:"id"`
+	Ref    string `json:"ref"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Version is version of entity client changed, mutation of entity changed on server in the meantime conflicts
+	Version int             `json:"version"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type SyncPushRequest struct {
+	Mutations []SyncMutation `json:"mutations" validate:"required"`
+}
+
+type SyncMutationResult struct {
+	Id      string `json:"id,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Status  int    `json:"status"`
+	Outcome string `json:"outcome"`
+	// Replayed is set when mutation was applied by earlier push
+	Replayed bool            `json:"replayed"`
+	Body     json.RawMessage `json:"body"`
+}
+
+type SyncPushResult struct {
+	Results []SyncMutationResult `json:"results"`
+}
+
+func (m SyncMutation) operation() BatchOperation {
+	return BatchOperation{
+		Ref:     m.Ref,
+		Method:  m.Method,
+		Path:    m.Path,
+		Version: m.Version,
+		Body:    m.Body,
+	}
+}
+
+// syncOutcome tells whether mutation was applied, conflicts with server state or is invalid
+func syncOutcome(status int) string {
+	switch {
+	case status < BadRequest:
+		return SyncOutcomeApplied
+	case status == http.StatusNotFound || status == http.StatusConflict || status == http.StatusPreconditionFailed:
+		return SyncOutcomeConflict
+	default:
+		return SyncOutcomeRejected
+	}
+}
+
+func (s *Server) getSyncChanges(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+
+	params := map[string]int{}
+
+	for _, name := range []string{"cursor", "limit"} {
+		raw := r.URL.Query().Get(name)
+
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+
+		if err != nil || value < 0 {
+			s.handleBadRequest(w, locale, "%s should be a non-negative number", name)
+			return
+		}
+
+		params[name] = value
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   s.relation(r).Changes(params["cursor"], params["limit"], accountId),
+	}
+
+	s.jsonResponse(w, response)
+}
+
+// pushSync applies mutations one by one, each in its own transaction. Failed mutation does not stop the rest,
+// mutations which reference its result are rejected though.
+func (s *Server) pushSync(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	dto := SyncPushRequest{}
+
+	err := s.parseJSON(r, &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	operations := []BatchOperation{}
+	var fields []errors.FieldError
+
+	for idx, m := range dto.Mutations {
+		operations = append(operations, m.operation())
+
+		if len(syncKeyPrefix+m.Id) > idempotencyKeyMaxLength {
+			fields = append(fields, errors.FieldError{
+				Field:   "mutations[" + strconv.Itoa(idx) + "].id",
+				Message: i18n.NewMessage("idempotency key should not be longer than %d characters", idempotencyKeyMaxLength-len(syncKeyPrefix)),
+			})
+		}
+	}
+
+	fields = append(fields, s.checkOperations("mutations", operations, accountId)...)
+
+	if len(fields) > 0 {
+		s.handleError(w, locale, *errors.NewErrValidation(fields...))
+		return
+	}
+
+	v2 := s.isApiV2(w)
+	data := map[string]interface{}{}
+	result := SyncPushResult{Results: []SyncMutationResult{}}
+
+	for idx, m := range dto.Mutations {
+		result.Results = append(result.Results, s.applyMutation(r, locale, idx, m, data, v2))
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   result,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) applyMutation(r *http.Request, locale i18n.Locale, idx int, m SyncMutation, data map[string]interface{}, v2 bool) SyncMutationResult {
+
+	accountId := s.accountId(r)
+	op := m.operation()
+
+	var record idempotency.Record
+
+	if m.Id != "" {
+		fingerprint := idempotency.Fingerprint(op.Method, op.Path, op.Body)
+
+		var begun bool
+		record, begun = s.di.Idempotency.Begin(syncKeyPrefix+m.Id, fingerprint, accountId)
+
+		if !begun {
+			return s.replayMutation(locale, m, record, fingerprint, data, v2)
+		}
+	}
+
+	var opResult BatchOperationResult
+	var customErr *errors.CustomError
+	var txServer *Server
+
+	err := db.Transaction(s.di.DB, func(tx db.DB) error {
+
+		txServer = s.withDB(tx)
+
+		opResult, customErr = txServer.executeOperation(txServer.batchRouter(v2), r, idx, op, data)
+
+		if customErr != nil || opResult.Status >= BadRequest {
+			return errBatchFailed
+		}
+
+		return nil
+	})
+
+	if err != nil && err != errBatchFailed {
+		customErr = errors.NewInternalServer(i18n.NewMessage(err.Error()))
+		delete(data, m.Ref)
+	}
+
+	if customErr != nil {
+		opResult = BatchOperationResult{Ref: m.Ref}
+		opResult.Status, opResult.Body = s.errorResponse(locale, *customErr, v2)
+	}
+
+	if err == nil {
+		// changes are announced only once they are committed
+		txServer.di.Flush()
+	}
+
+	if m.Id != "" {
+		// server errors are not final, client should be able to retry them
+		if opResult.Status >= http.StatusInternalServerError {
+			s.di.Idempotency.Release(record)
+		} else {
+			record.Status = opResult.Status
+			record.ContentType = "application/json"
+			record.Body = string(opResult.Body)
+			s.di.Idempotency.Complete(record)
+		}
+	}
+
+	return SyncMutationResult{
+		Id:      m.Id,
+		Ref:     m.Ref,
+		Status:  opResult.Status,
+		Outcome: syncOutcome(opResult.Status),
+		Body:    opResult.Body,
+	}
+}
+
+// replayMutation answers mutation which was already pushed with its original result
+func (s *Server) replayMutation(locale i18n.Locale, m SyncMutation, record idempotency.Record, fingerprint string, data map[string]interface{}, v2 bool) SyncMutationResult {
+
+	var customErr *errors.CustomError
+
+	if record.Fingerprint != fingerprint {
+		customErr = errors.NewErrUnprocessableEntity(i18n.NewMessage("idempotency key was already used for another request"))
+	} else if record.InProgress() {
+		customErr = errors.NewErrConflict(i18n.NewMessage("request with the same idempotency key is still in progress"))
+	}
+
+	if customErr != nil {
+		result := SyncMutationResult{Id: m.Id, Ref: m.Ref}
+		result.Status, result.Body = s.errorResponse(locale, *customErr, v2)
+		result.Outcome = syncOutcome(result.Status)
+		return result
+	}
+
+	if m.Ref != "" && record.Status < BadRequest {
+		data[m.Ref] = responseData([]byte(record.Body), v2)
+	}
+
+	return SyncMutationResult{
+		Id:       m.Id,
+		Ref:      m.Ref,
+		Status:   record.Status,
+		Outcome:  syncOutcome(record.Status),
+		Replayed: true,
+		Body:     json.RawMessage(record.Body),
+	}
+}
+
+// errorResponse renders error the way api of requested version would answer with it
+func (s *Server) errorResponse(locale i18n.Locale, customErr errors.CustomError, v2 bool) (int, json.RawMessage) {
+
+	rec := httptest.NewRecorder()
+
+	if v2 {
+		rec.Header().Set(ApiVersionHeader, ApiVersion2)
+	}
+
+	s.handleError(rec, locale, customErr)
+
+	return rec.Code, rec.Body.Bytes()
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestSyncOutcome(t *testing.T) {
+	assert.Equal(t, SyncOutcomeApplied, syncOutcome(http.StatusCreated))
+	assert.Equal(t, SyncOutcomeConflict, syncOutcome(http.StatusPreconditionFailed))
+	assert.Equal(t, SyncOutcomeConflict, syncOutcome(http.StatusNotFound))
+	assert.Equal(t, SyncOutcomeRejected, syncOutcome(http.StatusUnprocessableEntity))
+}
@@ -1,14 +1,22 @@
 package http
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/proviant-io/core/internal/errors"
 	"github.com/proviant-io/core/internal/i18n"
-	"io"
-	"log"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"github.com/proviant-io/core/internal/pkg/media"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// imageCacheControl lets browsers keep images for a year without revalidation, access is checked per account
+// so shared caches should not keep them
+const imageCacheControl = "private, max-age=31536000, immutable"
+
 func (s *Server) getImage(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	imageFileName := vars["fileName"]
@@ -18,18 +26,149 @@ func (s *Server) getImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fileBuffer, mime, err := s.di.ImageSaver.GetImage(imageFileName)
+	// names are checked before storage is touched, so they cannot point outside of it
+	if !image.ValidFileName(imageFileName) {
+		s.handleError(w, s.getLocale(r), *errors.NewErrNotFound(i18n.NewMessage("image %s not found", imageFileName)))
+		return
+	}
+
+	m, customErr := s.imageMedia(r, imageFileName)
+
+	if customErr != nil {
+		s.handleError(w, s.getLocale(r), *customErr)
+		return
+	}
+
+	size := r.URL.Query().Get("size")
+	acceptsWebp := strings.Contains(r.Header.Get("Accept"), "image/webp")
 
-	if err != nil{
+	candidates, err := image.Candidates(imageFileName, size, acceptsWebp)
+
+	if err != nil {
+		s.handleBadRequest(w, s.getLocale(r), "unknown image size %s", size)
+		return
+	}
+
+	// the same url answers with different formats depending on Accept
+	w.Header().Set("Vary", "Accept")
+
+	// file is picked before anything is answered, so redirect and ETag point to file which is served
+	name, err := s.storedImage(candidates)
+
+	if err != nil {
 		s.handleError(w, s.getLocale(r), *errors.NewInternalServer(i18n.NewMessage("Cannot fetch file, : %s", err.Error())))
 		return
 	}
 
-	w.Header().Set("Content-Type", mime)
-	w.WriteHeader(200)
-	_, err = io.Copy(w, fileBuffer)
+	if name == "" {
+		s.handleError(w, s.getLocale(r), *errors.NewErrNotFound(i18n.NewMessage("image %s not found", imageFileName)))
+		return
+	}
+
+	if signer, ok := s.di.ImageSaver.(image.URLSigner); ok {
+		signedURL, err := signer.SignedURL(name)
+
+		if err != nil {
+			s.handleError(w, s.getLocale(r), *errors.NewInternalServer(i18n.NewMessage("Cannot sign file url, : %s", err.Error())))
+			return
+		}
+
+		if signedURL != "" {
+			http.Redirect(w, r, signedURL, http.StatusTemporaryRedirect)
+			return
+		}
+	}
+
+	// stored files never change, every upload gets new name
+	modTime := m.CreatedAt.UTC().Truncate(time.Second)
+	eTag := imageETag(m.Id, name)
+
+	w.Header().Set("Cache-Control", imageCacheControl)
+	w.Header().Set("ETag", eTag)
+	w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+
+	// revalidation is answered without reading file from storage
+	if notModified(r, eTag, modTime) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	fileBuffer, mime, err := s.di.ImageSaver.GetImage(name)
+
 	if err != nil {
-		log.Println(err)
+		s.handleError(w, s.getLocale(r), *errors.NewInternalServer(i18n.NewMessage("Cannot fetch file, : %s", err.Error())))
+		return
+	}
+
+	w.Header().Set("Content-Type", mime)
+
+	// answers conditional and range requests
+	http.ServeContent(w, r, imageFileName, modTime, bytes.NewReader(fileBuffer.Bytes()))
+}
+
+// imageMedia returns media of image request may read. Image is readable by its account or by anyone
+// with signed url, images of other accounts are reported as missing.
+func (s *Server) imageMedia(r *http.Request, fileName string) (media.Media, *errors.CustomError) {
+
+	url := media.Url(fileName)
+	query := r.URL.Query()
+
+	m, found := s.di.Media.GetByUrl(url)
+
+	if query.Get(media.SignatureParam) != "" {
+		if !s.di.MediaSigner.Verify(url, query.Get(media.ExpiresParam), query.Get(media.SignatureParam), time.Now()) {
+			return media.Media{}, errors.NewErrForbidden(i18n.NewMessage("image url signature is invalid or expired"))
+		}
+	} else if m.AccountId != s.accountId(r) {
+		found = false
+	}
+
+	if !found {
+		return media.Media{}, errors.NewErrNotFound(i18n.NewMessage("image %s not found", fileName))
+	}
+
+	return m, nil
+}
+
+// storedImage returns the first of candidates which is stored, empty name when there is none
+func (s *Server) storedImage(candidates []string) (string, error) {
+
+	for _, name := range candidates {
+		exists, err := s.di.ImageSaver.Exists(name)
+
+		if err != nil {
+			return "", err
+		}
+
+		if exists {
+			return name, nil
+		}
 	}
-	return
+
+	return "", nil
+}
+
+// imageETag identifies file served for media, variant and format are part of its name
+func imageETag(mediaId int, fileName string) string {
+	return fmt.Sprintf(`"%d-%s"`, mediaId, fileName)
+}
+
+// notModified tells client has the same image already, If-None-Match wins over If-Modified-Since
+func notModified(r *http.Request, eTag string, modTime time.Time) bool {
+
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+			if candidate == "*" || candidate == eTag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+
+	return err == nil && !modTime.After(since)
 }
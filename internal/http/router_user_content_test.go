@@ -0,0 +1,93 @@
+package http
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/config"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"github.com/proviant-io/core/internal/pkg/media"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetImageETagOfServedFile(t *testing.T) {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "media.sqlite"))
+	assert.NoError(t, err)
+
+	mediaRepo, err := media.Setup(d)
+	assert.NoError(t, err)
+
+	location := t.TempDir()
+
+	s := NewServer(nil, nil, nil, nil, nil, nil, nil, i18n.NewFileLocalizer(), &di.DI{
+		Cfg:        &config.Config{Mode: config.ModeApi},
+		Apm:        &apm.NoopApm{},
+		Logger:     logger.Default(),
+		Media:      mediaRepo,
+		ImageSaver: image.NewLocalSaver(location),
+	})
+
+	m := mediaRepo.Create(media.Media{Url: media.Url("abc.png"), Mime: "image/png", AccountId: 1})
+
+	get := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/uc/abc.png", nil)
+		r.Header.Set("AccountId", "1")
+		r.Header.Set("Accept", "image/webp,*/*")
+		r = mux.SetURLVars(r, map[string]string{"fileName": "abc.png"})
+
+		w := httptest.NewRecorder()
+		s.getImage(w, r)
+		return w
+	}
+
+	w := get()
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// images uploaded before variants were introduced have the original only
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(location, "abc.png"), []byte("png"), 0644))
+
+	w = get()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, imageETag(m.Id, "abc.png"), w.Header().Get("ETag"))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(location, "abc.webp"), []byte("webp"), 0644))
+
+	w = get()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/webp", w.Header().Get("Content-Type"))
+	assert.Equal(t, imageETag(m.Id, "abc.webp"), w.Header().Get("ETag"))
+}
+
+func TestUserContentIsNotServedAsStaticFiles(t *testing.T) {
+
+	location := t.TempDir()
+
+	s := NewServer(nil, nil, nil, nil, nil, nil, nil, i18n.NewFileLocalizer(), &di.DI{
+		Cfg: &config.Config{
+			Mode:        config.ModeWeb,
+			UserContent: config.UserContent{Mode: config.UserContentModeLocal, Location: location},
+		},
+		Apm:    &apm.NoopApm{},
+		Logger: logger.Default(),
+	})
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(location, "abc.png"), []byte("png of account 1"), 0644))
+
+	for _, target := range []string{"/content/abc.png", "/content/"} {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.NotContains(t, w.Body.String(), "png of account 1", target)
+		assert.NotContains(t, w.Body.String(), "abc.png", target)
+	}
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/pkg/webhook"
+	"net/http"
+	"strconv"
+)
+
+const webhookDeliveryLogLimit = 100
+
+func (s *Server) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+
+	models := s.di.Webhook.Subscriptions().GetAll(accountId)
+
+	dtos := []webhook.SubscriptionDTO{}
+
+	for _, model := range models {
+		dtos = append(dtos, webhook.SubscriptionToDTO(model))
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   dtos,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) getWebhook(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	model, customErr := s.di.Webhook.Subscriptions().Get(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   webhook.SubscriptionToDTO(model),
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	dto := webhook.SubscriptionDTO{}
+
+	err := s.parseJSON(r, &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	model, customErr := s.di.Webhook.Subscriptions().Create(dto, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	// secret is revealed only once, client has to keep it to verify signatures
+	created := webhook.SubscriptionToDTO(model)
+	created.Secret = model.Secret
+
+	response := Response{
+		Status: ResponseCodeCreated,
+		Data:   created,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	dto := webhook.SubscriptionDTO{}
+
+	err = s.parseJSON(r, &dto)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "parse payload error: %v", err.Error())
+		return
+	}
+
+	if !s.validate(w, locale, dto, accountId) {
+		return
+	}
+
+	model, customErr := s.di.Webhook.Subscriptions().Update(id, dto, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   webhook.SubscriptionToDTO(model),
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	customErr := s.di.Webhook.Subscriptions().Delete(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	s.di.Webhook.Deliveries().DeleteBySubscriptionId(id, accountId)
+
+	response := Response{
+		Status: ResponseCodeOk,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	_, customErr := s.di.Webhook.Subscriptions().Get(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	models := s.di.Webhook.Deliveries().GetAllBySubscriptionId(id, accountId, webhookDeliveryLogLimit)
+
+	dtos := []webhook.DeliveryDTO{}
+
+	for _, model := range models {
+		dtos = append(dtos, webhook.DeliveryToDTO(model))
+	}
+
+	response := Response{
+		Status: ResponseCodeOk,
+		Data:   dtos,
+	}
+
+	s.jsonResponse(w, response)
+}
+
+func (s *Server) replayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	accountId := s.accountId(r)
+	locale := s.getLocale(r)
+	vars := mux.Vars(r)
+	idString := vars["id"]
+
+	if idString == "" {
+		s.handleBadRequest(w, locale, "id cannot be empty")
+		return
+	}
+
+	id, err := strconv.Atoi(idString)
+
+	if err != nil {
+		s.handleBadRequest(w, locale, "id is not a number: %v", err.Error())
+		return
+	}
+
+	model, customErr := s.di.Webhook.Replay(id, accountId)
+
+	if customErr != nil {
+		s.handleError(w, locale, *customErr)
+		return
+	}
+
+	response := Response{
+		Status: ResponseCodeCreated,
+		Data:   webhook.DeliveryToDTO(model),
+	}
+
+	s.jsonResponse(w, response)
+}
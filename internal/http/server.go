@@ -2,20 +2,28 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/gorilla/mux"
+	"github.com/proviant-io/core/internal/apm"
 	"github.com/proviant-io/core/internal/config"
 	"github.com/proviant-io/core/internal/di"
 	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/graphql"
 	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/openapi"
 	"github.com/proviant-io/core/internal/pkg/category"
 	"github.com/proviant-io/core/internal/pkg/list"
 	"github.com/proviant-io/core/internal/pkg/product"
 	"github.com/proviant-io/core/internal/pkg/product_category"
 	"github.com/proviant-io/core/internal/pkg/service"
 	"github.com/proviant-io/core/internal/pkg/stock"
-	"log"
+	"github.com/proviant-io/core/internal/utils"
+	"github.com/proviant-io/core/internal/validation"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Server struct {
@@ -25,10 +33,15 @@ type Server struct {
 	productCategoryRepo *product_category.Repository
 	stockRepo           *stock.Repository
 	relationService     *service.RelationService
+	accountService      *service.AccountService
 	router              *mux.Router
 	l                   i18n.Localizer
 	cfg                 config.Config
 	di                  *di.DI
+	validator           *validation.Validator
+	openApi             openapi.Document
+	graphqlSchema       *graphql.Schema
+	started             time.Time
 }
 
 func (s *Server) Run(hostPort string) error {
@@ -39,42 +52,136 @@ func (s *Server) parseJSON(r *http.Request, model interface{}) error {
 	return json.NewDecoder(r.Body).Decode(model)
 }
 
+// parseMergePatch applies JSON Merge Patch from request body to current representation of resource and decodes result into model
+func (s *Server) parseMergePatch(r *http.Request, current interface{}, model interface{}) error {
+
+	patch, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	merged, err := utils.MergePatch(original, patch)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(merged, model)
+}
+
 func (s *Server) getLocale(r *http.Request) i18n.Locale {
 	return i18n.LocaleFromString(r.Header.Get("User-Locale"))
 }
 
 func (s *Server) handleBadRequest(w http.ResponseWriter, locale i18n.Locale, error string, params ...interface{}) {
-	m := i18n.NewMessage(error, params...)
+	s.handleError(w, locale, *errors.NewErrBadRequest(i18n.NewMessage(error, params...)))
+}
+
+func (s *Server) handleError(w http.ResponseWriter, locale i18n.Locale, error errors.CustomError) {
+
+	if s.isApiV2(w) {
+		s.problemResponse(w, locale, error)
+		return
+	}
+
+	status := error.V1Code()
+	message := error.Message()
+
+	// v1 reported invalid input as bad request with message of the first invalid field,
+	// missing references were not found
+	if fields := error.Fields(); len(fields) > 0 {
+		status = BadRequest
+		message = fields[0].Message
+
+		if fields[0].V1Code != 0 {
+			status = fields[0].V1Code
+		}
+	}
+
 	response := Response{
-		Status: BadRequest,
-		Error:  s.l.T(m, locale),
+		Status: status,
+		Error:  s.l.T(message, locale),
 	}
 
 	s.jsonResponse(w, response)
 }
 
-func (s *Server) handleError(w http.ResponseWriter, locale i18n.Locale, error errors.CustomError) {
-	response := Response{
+func (s *Server) problemResponse(w http.ResponseWriter, locale i18n.Locale, error errors.CustomError) {
+
+	problem := Problem{
+		Type:   problemType(error.Code()),
+		Title:  http.StatusText(error.Code()),
 		Status: error.Code(),
-		Error:  s.l.T(error.Message(), locale),
+		Detail: s.l.T(error.Message(), locale),
 	}
 
-	s.jsonResponse(w, response)
+	for _, field := range error.Fields() {
+		problem.Errors = append(problem.Errors, FieldProblem{
+			Field:  field.Field,
+			Detail: s.l.T(field.Message, locale),
+		})
+	}
+
+	s.writeJSON(w, ProblemContentType, problem.Status, problem)
 }
 
+// jsonResponse writes response envelope in v1, in v2 only data is written and errors are converted to problems
 func (s *Server) jsonResponse(w http.ResponseWriter, response Response) {
-	payload, err := json.Marshal(response)
+
+	if !s.isApiV2(w) {
+		s.writeJSON(w, "application/json", response.Status, response)
+		return
+	}
+
+	if response.Status >= BadRequest {
+		problem := Problem{
+			Type:   problemType(response.Status),
+			Title:  http.StatusText(response.Status),
+			Status: response.Status,
+			Detail: response.Error,
+		}
+
+		s.writeJSON(w, ProblemContentType, problem.Status, problem)
+		return
+	}
+
+	if response.Data == nil && response.Status == ResponseCodeOk {
+		w.WriteHeader(ResponseCodeNoContent)
+		return
+	}
+
+	s.writeJSON(w, "application/json", response.Status, response.Data)
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, contentType string, status int, body interface{}) {
+	payload, err := json.Marshal(body)
 	if err != nil {
-		log.Println(err)
+		s.di.Logger.Error("cannot marshal response", "error", err)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(response.Status)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
 	_, err = w.Write(payload)
 	if err != nil {
-		log.Println(err)
+		s.di.Logger.Warn("cannot write response", "error", err)
 	}
-	return
+}
+
+// apiV2Middleware marks response as v2 one, response helpers rely on the header because
+// apm could wrap response writer
+func (s *Server) apiV2Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ApiVersionHeader, ApiVersion2)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) isApiV2(w http.ResponseWriter) bool {
+	return w.Header().Get(ApiVersionHeader) == ApiVersion2
 }
 
 func (s *Server) accountId(r *http.Request) int {
@@ -88,7 +195,7 @@ func (s *Server) accountId(r *http.Request) int {
 	accountId, err := strconv.Atoi(accountHeader)
 
 	if err != nil {
-		log.Println(err)
+		s.log(r).Warn("AccountId header is not a number", "error", err)
 		return -1
 	}
 
@@ -106,19 +213,127 @@ func (s *Server) userId(r *http.Request) int {
 	accountId, err := strconv.Atoi(accountHeader)
 
 	if err != nil {
-		log.Println(err)
+		s.log(r).Warn("UserId header is not a number", "error", err)
 		return -1
 	}
 
 	return accountId
 }
 
+// ifMatch returns version client expects from If-Match header, 0 means any version is fine
+func (s *Server) ifMatch(r *http.Request) (int, error) {
+
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	header = strings.TrimPrefix(header, "W/")
+
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+
+	if err != nil {
+		return 0, err
+	}
+
+	if version < 1 {
+		return 0, fmt.Errorf("version should be positive: %d", version)
+	}
+
+	return version, nil
+}
+
+func (s *Server) setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// relation returns service which works for r, so its spans belong to trace of request
+func (s *Server) relation(r *http.Request) *service.RelationService {
+	return s.relationService.WithContext(r.Context())
+}
+
+func (s *Server) registerApiRoutes(api *mux.Router) {
+	// product routes
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/", s.getProduct)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/", s.getProducts)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/", s.createProduct)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/", s.updateProduct)).Methods("PUT")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/", s.patchProduct)).Methods("PATCH")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/", s.deleteProduct)).Methods("DELETE")
+	// category routes
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/category/{id}/", s.getCategory)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/category/", s.getCategories)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/category/", s.createCategory)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/category/{id}/", s.updateCategory)).Methods("PUT")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/category/{id}/", s.patchCategory)).Methods("PATCH")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/category/{id}/", s.deleteCategory)).Methods("DELETE")
+	// list routes
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/list/{id}/", s.getList)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/list/", s.getLists)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/list/", s.createList)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/list/{id}/", s.updateList)).Methods("PUT")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/list/{id}/", s.patchList)).Methods("PATCH")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/list/{id}/", s.deleteList)).Methods("DELETE")
+	// stock routers
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/stock/", s.getStock)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/add/", s.addStock)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/consume/", s.consumeStock)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{product_id}/stock/{id}/", s.getStockLot)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{product_id}/stock/{id}/", s.patchStockLot)).Methods("PATCH")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{product_id}/stock/{id}/", s.deleteStock)).Methods("DELETE")
+	// shopping list
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/", s.getShoppingLists)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{id}/", s.getShoppingList)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{id}/events/", s.streamShoppingList)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{id}/", s.addShoppingListItem)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/", s.getShoppingListItem)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/", s.updateShoppingListItem)).Methods("PUT")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/", s.patchShoppingListItem)).Methods("PATCH")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/", s.deleteShoppingListItem)).Methods("DELETE")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/check/", s.checkShoppingListItem)).Methods("PUT")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/uncheck/", s.uncheckShoppingListItem)).Methods("PUT")
+	// stock consumption log
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/consumption_log/", s.getConsumptionLog)).Methods("GET")
+	// media
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/media/", s.getProductMedia)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/media/", s.setProductMedia)).Methods("PUT")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/media/", s.uploadMedia)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/media/{id}/", s.getMedia)).Methods("GET")
+	// attachments
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/attachment/", s.getAttachments)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{id}/attachment/", s.uploadAttachment)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{product_id}/attachment/{id}/download/", s.downloadAttachment)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/product/{product_id}/attachment/{id}/", s.deleteAttachment)).Methods("DELETE")
+	// audit log
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/audit/", s.getAuditLog)).Methods("GET")
+	// offline sync
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/sync/", s.getSyncChanges)).Methods("GET")
+	// webhooks
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/webhook/", s.getWebhooks)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/webhook/", s.createWebhook)).Methods("POST")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/webhook/{id}/", s.getWebhook)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/webhook/{id}/", s.updateWebhook)).Methods("PUT")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/webhook/{id}/", s.deleteWebhook)).Methods("DELETE")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/webhook/{id}/delivery/", s.getWebhookDeliveries)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/webhook/delivery/{id}/replay/", s.replayWebhookDelivery)).Methods("POST")
+	// admin
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/admin/account/{id}/export/", s.exportAccount)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/admin/account/{id}/", s.eraseAccount)).Methods("DELETE")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/admin/diagnostics/", s.getDiagnostics)).Methods("GET")
+
+	// chore
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/i18n/missing/", s.getMissingTranslations)).Methods("GET")
+	api.HandleFunc(s.di.Apm.WrapHandleFunc("/version/", s.getVersion)).Methods("GET")
+}
+
 func NewServer(productRepo *product.Repository,
 	listRepo *list.Repository,
 	categoryRepo *category.Repository,
 	productCategoryRepo *product_category.Repository,
 	stockRepo *stock.Repository,
 	relationService *service.RelationService,
+	accountService *service.AccountService,
 	l i18n.Localizer,
 	i *di.DI) *Server {
 
@@ -129,63 +344,72 @@ func NewServer(productRepo *product.Repository,
 		productCategoryRepo: productCategoryRepo,
 		stockRepo:           stockRepo,
 		relationService:     relationService,
+		accountService:      accountService,
 		l:                   l,
 		di:                  i,
+		started:             time.Now(),
 	}
 
+	server.validator = server.newValidator()
+	server.openApi = server.apiSpecification()
+
+	graphqlSchema, err := graphql.NewSchema(productRepo, listRepo, categoryRepo, productCategoryRepo, stockRepo, relationService, l, i)
+
+	if err != nil {
+		i.Logger.Fatal("cannot build graphql schema", "error", err)
+	}
+
+	server.graphqlSchema = graphqlSchema
+
 	router := mux.NewRouter()
+	router.Use(server.requestIdMiddleware)
+
+	apiV1Router := router.PathPrefix(apiV1Prefix).Subrouter()
+	server.registerApiRoutes(apiV1Router)
+	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/openapi.json", server.getOpenApi)).Methods("GET")
+	// batch is registered apart from other routes, so it cannot be nested into itself
+	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/batch", server.batch)).Methods("POST")
+	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/sync/push/", server.pushSync)).Methods("POST")
+
+	// v2 shares handlers with v1, it differs only in response format
+	apiV2Router := router.PathPrefix("/api/v2").Subrouter()
+	apiV2Router.Use(server.apiV2Middleware)
+	server.registerApiRoutes(apiV2Router)
+	apiV2Router.HandleFunc(server.di.Apm.WrapHandleFunc("/batch", server.batch)).Methods("POST")
+	apiV2Router.HandleFunc(server.di.Apm.WrapHandleFunc("/sync/push/", server.pushSync)).Methods("POST")
 
-	apiV1Router := router.PathPrefix("/api/v1").Subrouter()
+	userContentRouter := router.PathPrefix("/uc/").Subrouter()
+	userContentRouter.HandleFunc(server.di.Apm.WrapHandleFunc("/img/{fileName}", server.getImage)).Methods("GET", "HEAD")
 
-	// product routes
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{id}/", server.getProduct)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/", server.getProducts)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/", server.createProduct)).Methods("POST")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{id}/", server.updateProduct)).Methods("PUT")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{id}/", server.deleteProduct)).Methods("DELETE")
-	// category routes
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/category/{id}/", server.getCategory)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/category/", server.getCategories)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/category/", server.createCategory)).Methods("POST")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/category/{id}/", server.updateCategory)).Methods("PUT")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/category/{id}/", server.deleteCategory)).Methods("DELETE")
-	// list routes
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/list/{id}/", server.getList)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/list/", server.getLists)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/list/", server.createList)).Methods("POST")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/list/{id}/", server.updateList)).Methods("PUT")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/list/{id}/", server.deleteList)).Methods("DELETE")
-	// stock routers
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{id}/stock/", server.getStock)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{id}/add/", server.addStock)).Methods("POST")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{id}/consume/", server.consumeStock)).Methods("POST")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{product_id}/stock/{id}/", server.deleteStock)).Methods("DELETE")
-	// shopping list
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/shopping_list/", server.getShoppingLists)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/shopping_list/{id}/", server.getShoppingList)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/shopping_list/{id}/", server.addShoppingListItem)).Methods("POST")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/", server.updateShoppingListItem)).Methods("PUT")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/", server.deleteShoppingListItem)).Methods("DELETE")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/check/", server.checkShoppingListItem)).Methods("PUT")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/shopping_list/{list_id}/{id}/uncheck/", server.uncheckShoppingListItem)).Methods("PUT")
-	// stock consumption log
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/product/{id}/consumption_log/", server.getConsumptionLog)).Methods("GET")
+	graphqlRouter := router.PathPrefix("/graphql").Subrouter()
+	graphqlRouter.HandleFunc(server.di.Apm.WrapHandleFunc("", server.graphql)).Methods("POST")
 
+	if server.di.RateLimiter != nil {
+		apiV1Router.Use(server.rateLimitMiddleware)
+		graphqlRouter.Use(server.rateLimitMiddleware)
+		apiV2Router.Use(server.rateLimitMiddleware)
+		userContentRouter.Use(server.rateLimitMiddleware)
+	}
 
+	apiV1Router.Use(server.idempotencyMiddleware)
+	apiV2Router.Use(server.idempotencyMiddleware)
 
-	// chore
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/i18n/missing/", server.getMissingTranslations)).Methods("GET")
-	apiV1Router.HandleFunc(server.di.Apm.WrapHandleFunc("/version/", server.getVersion)).Methods("GET")
+	if i.Cfg.OpenApi.Validate {
+		apiV1Router.Use(server.openApiMiddleware)
+	}
 
-	userContentRouter := router.PathPrefix("/uc/").Subrouter()
-	userContentRouter.HandleFunc(server.di.Apm.WrapHandleFunc("/img/{fileName}", server.getImage)).Methods("GET")
+	router.HandleFunc(livenessPath, server.getLiveness).Methods("GET")
+	router.HandleFunc(readinessPath, server.getReadiness).Methods("GET")
+
+	// metrics are meant for scraper inside of private network, they are not scoped by account
+	if exporter, ok := server.di.Apm.(apm.Exporter); ok {
+		router.Handle(exporter.MetricsPath(), exporter.MetricsHandler()).Methods("GET")
+	}
 
 	if i.Cfg.Mode == config.ModeWeb {
 		router.PathPrefix("/static").Handler(http.FileServer(http.Dir("./public/")))
 
-		if i.Cfg.UserContent.Mode == config.UserContentModeLocal {
-			router.PathPrefix("/content/").Handler(http.StripPrefix("/content/", http.FileServer(http.Dir(i.Cfg.UserContent.Location))))
-		}
+		// user content is served by /uc/ only, it is authorized per account there
 
 		spa := spaHandler{staticPath: "public", indexPath: "index.html"}
 		router.PathPrefix("/").Handler(spa)
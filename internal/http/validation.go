@@ -0,0 +1,43 @@
+package http
+
+import (
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/webhook"
+	"github.com/proviant-io/core/internal/validation"
+	"net/http"
+	"reflect"
+)
+
+// validate checks dto against rules from its `validate` tags, invalid request is answered right away
+func (s *Server) validate(w http.ResponseWriter, locale i18n.Locale, dto interface{}, accountId int) bool {
+
+	fields := s.validator.Validate(dto, accountId)
+
+	if len(fields) == 0 {
+		return true
+	}
+
+	s.handleError(w, locale, *errors.NewErrValidation(fields...))
+	return false
+}
+
+// newValidator registers rules which need storage or know about webhooks
+func (s *Server) newValidator() *validation.Validator {
+
+	v := validation.New()
+
+	v.RegisterReferences(s.listRepo, s.categoryRepo, s.productRepo, s.di.Media)
+
+	v.Register("webhook_events", func(field string, value reflect.Value, _ string, _ int) *i18n.Message {
+		for i := 0; i < value.Len(); i++ {
+			if event := value.Index(i).String(); !webhook.IsKnownEvent(event) {
+				m := i18n.NewMessage("unknown webhook event: %s", event)
+				return &m
+			}
+		}
+		return nil
+	})
+
+	return v
+}
@@ -2,7 +2,7 @@ package i18n
 
 import (
 	"fmt"
-	"log"
+	"github.com/proviant-io/core/internal/logger"
 )
 
 type Locale string
@@ -39,7 +39,6 @@ func (l *FileLocalizer) T(m Message, locale Locale) string {
 	if translations, ok := l.strings[m.Template]; ok {
 		if translation, ok := translations[locale]; ok {
 			if len(m.Params) > 0 {
-				log.Println(translation, m.Params)
 				return fmt.Sprintf(translation, m.Params...)
 			}
 			return translation
@@ -47,11 +46,10 @@ func (l *FileLocalizer) T(m Message, locale Locale) string {
 		}
 	}
 
-	log.Printf("missing translation: %s - %s \n", locale, m.Template)
+	logger.Default().Warn("missing translation", "locale", locale, "template", m.Template)
 	l.missing = append(l.missing, m.Template)
 
 	if len(m.Params) > 0 {
-		log.Println(m.Template, m.Params)
 		return fmt.Sprintf(m.Template, m.Params...)
 	}
 	return m.Template
@@ -88,14 +86,174 @@ func NewFileLocalizer() Localizer {
 			En: "parse payload error: %v",
 			Ru: "ошибка обработки данных запроса: %v",
 		},
-		"title should not be empty": {
-			En: "title should not be empty",
-			Ru: "заголовок должен быть заполнен",
+		"%s should not be empty": {
+			En: "%s should not be empty",
+			Ru: "поле %s должно быть заполнено",
+		},
+		"%s should not be 0": {
+			En: "%s should not be 0",
+			Ru: "поле %s не должно быть равно 0",
+		},
+		"%s should be at least %d": {
+			En: "%s should be at least %d",
+			Ru: "поле %s должно быть не меньше %d",
+		},
+		"%s should be at most %d": {
+			En: "%s should be at most %d",
+			Ru: "поле %s должно быть не больше %d",
+		},
+		"%s should not be longer than %d characters": {
+			En: "%s should not be longer than %d characters",
+			Ru: "поле %s не должно быть длиннее %d символов",
+		},
+		"%s should not be negative": {
+			En: "%s should not be negative",
+			Ru: "поле %s не должно быть отрицательным",
+		},
+		"%s should be %s": {
+			En: "%s should be %s",
+			Ru: "поле %s должно иметь тип %s",
+		},
+		"%s is not described in specification": {
+			En: "%s is not described in specification",
+			Ru: "поле %s не описано в спецификации",
+		},
+		"%s should be absolute http(s) url": {
+			En: "%s should be absolute http(s) url",
+			Ru: "поле %s должно быть абсолютным http(s) адресом",
+		},
+		"%s should be base64 data url, e.g. data:image/png;base64,...": {
+			En: "%s should be base64 data url, e.g. data:image/png;base64,...",
+			Ru: "поле %s должно быть data url в base64, например data:image/png;base64,...",
+		},
+		"unknown webhook event: %s": {
+			En: "unknown webhook event: %s",
+			Ru: "неизвестное событие вебхука: %s",
 		},
 		"You can't remove list with products. Clean products first.": {
 			En: "You can't remove list with products. Clean products first.",
 			Ru: "Вы не можете удалить список с продуктами. Удалите или перенесите продукты перед удалением.",
 		},
+		"too many requests, retry in %d seconds": {
+			En: "too many requests, retry in %d seconds",
+			Ru: "слишком много запросов, повторите через %d сек.",
+		},
+		"product with id %d was changed by someone else": {
+			En: "product with id %d was changed by someone else",
+			Ru: "продукт с id %d был изменен кем-то другим",
+		},
+		"category with id %d was changed by someone else": {
+			En: "category with id %d was changed by someone else",
+			Ru: "категория с id %d была изменена кем-то другим",
+		},
+		"list with id %d was changed by someone else": {
+			En: "list with id %d was changed by someone else",
+			Ru: "список с id %d был изменен кем-то другим",
+		},
+		"stock with id %d was changed by someone else": {
+			En: "stock with id %d was changed by someone else",
+			Ru: "запас с id %d был изменен кем-то другим",
+		},
+		"shopping list item with id %d was changed by someone else": {
+			En: "shopping list item with id %d was changed by someone else",
+			Ru: "элемент списка покупок с id %d был изменен кем-то другим",
+		},
+		"idempotency key should not be longer than %d characters": {
+			En: "idempotency key should not be longer than %d characters",
+			Ru: "ключ идемпотентности не должен быть длиннее %d символов",
+		},
+		"idempotency key was already used for another request": {
+			En: "idempotency key was already used for another request",
+			Ru: "ключ идемпотентности уже был использован для другого запроса",
+		},
+		"request with the same idempotency key is still in progress": {
+			En: "request with the same idempotency key is still in progress",
+			Ru: "запрос с тем же ключом идемпотентности еще выполняется",
+		},
+		"query cannot be empty": {
+			En: "query cannot be empty",
+			Ru: "запрос не может быть пустым",
+		},
+		"%s should not contain more than %d items": {
+			En: "%s should not contain more than %d items",
+			Ru: "поле %s не должно содержать больше %d элементов",
+		},
+		"%s should contain only latin letters, digits and underscores": {
+			En: "%s should contain only latin letters, digits and underscores",
+			Ru: "поле %s должно содержать только латинские буквы, цифры и подчеркивания",
+		},
+		"unknown reference %s": {
+			En: "unknown reference %s",
+			Ru: "неизвестная ссылка %s",
+		},
+		"reference %s is already used": {
+			En: "reference %s is already used",
+			Ru: "ссылка %s уже используется",
+		},
+		"route %s %s does not exist": {
+			En: "route %s %s does not exist",
+			Ru: "маршрут %s %s не существует",
+		},
+		"%s %s cannot be batched": {
+			En: "%s %s cannot be batched",
+			Ru: "%s %s нельзя выполнить в пакете",
+		},
+		"operation %d: %s": {
+			En: "operation %d: %s",
+			Ru: "операция %d: %s",
+		},
+		"batch operation %d failed, nothing was applied": {
+			En: "batch operation %d failed, nothing was applied",
+			Ru: "операция пакета %d не выполнена, изменения не применены",
+		},
+		"%s should be a non-negative number": {
+			En: "%s should be a non-negative number",
+			Ru: "%s должно быть неотрицательным числом",
+		},
+		"unknown image size %s": {
+			En: "unknown image size %s",
+			Ru: "неизвестный размер изображения %s",
+		},
+		"image should not be bigger than %d bytes": {
+			En: "image should not be bigger than %d bytes",
+			Ru: "изображение не должно быть больше %d байт",
+		},
+		"multipart field %s is required": {
+			En: "multipart field %s is required",
+			Ru: "поле %s формы обязательно",
+		},
+		"media with id %d not found": {
+			En: "media with id %d not found",
+			Ru: "медиафайл с id %d не найден",
+		},
+		"image %s not found": {
+			En: "image %s not found",
+			Ru: "изображение %s не найдено",
+		},
+		"image url signature is invalid or expired": {
+			En: "image url signature is invalid or expired",
+			Ru: "подпись ссылки на изображение неверна или истекла",
+		},
+		"attachment with id %d not found": {
+			En: "attachment with id %d not found",
+			Ru: "вложение с id %d не найдено",
+		},
+		"attachment of type %s is not supported": {
+			En: "attachment of type %s is not supported",
+			Ru: "вложения типа %s не поддерживаются",
+		},
+		"attachment should not be bigger than %d bytes": {
+			En: "attachment should not be bigger than %d bytes",
+			Ru: "вложение не должно быть больше %d байт",
+		},
+		"media %d is listed more than once": {
+			En: "media %d is listed more than once",
+			Ru: "медиафайл %d указан больше одного раза",
+		},
+		"request validation failed": {
+			En: "request validation failed",
+			Ru: "запрос не прошел проверку",
+		},
 	}
 
 	return &FileLocalizer{strings, []string{}}
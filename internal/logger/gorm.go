@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gormlogger "gorm.io/gorm/logger"
+	"time"
+)
+
+// SlowQuery is duration after which query is logged as warning
+const SlowQuery = 200 * time.Millisecond
+
+// GormLogger writes gorm records to logger of query context, so they carry id of request.
+// Queries are logged on debug level, record not found is not an error
+type GormLogger struct {
+	l *Logger
+}
+
+func NewGormLogger(l *Logger) *GormLogger {
+	return &GormLogger{l: l}
+}
+
+// LogMode is ignored, level of logger decides what is written
+func (g *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return g
+}
+
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	g.from(ctx).Info(fmt.Sprintf(msg, args...))
+}
+
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	g.from(ctx).Warn(fmt.Sprintf(msg, args...))
+}
+
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	g.from(ctx).Error(fmt.Sprintf(msg, args...))
+}
+
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+
+	l := g.from(ctx)
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gormlogger.ErrRecordNotFound):
+		query, rows := fc()
+		l.Error("query failed", "query", query, "rows", rows, "duration_ms", elapsed.Milliseconds(), "error", err)
+	case elapsed > SlowQuery:
+		query, rows := fc()
+		l.Warn("slow query", "query", query, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	case l.Enabled(LevelDebug):
+		query, rows := fc()
+		l.Debug("query", "query", query, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	}
+}
+
+// from prefers logger of request, context of queries outside of requests carries none
+func (g *GormLogger) from(ctx context.Context) *Logger {
+
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+
+	return g.l
+}
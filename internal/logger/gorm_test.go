@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	gormlogger "gorm.io/gorm/logger"
+	"testing"
+	"time"
+)
+
+func TestGormLogger(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+
+	l, err := New(buf, LevelInfo, FormatText)
+	assert.NoError(t, err)
+	l.now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	g := NewGormLogger(l)
+	query := func() (string, int64) { return "SELECT 1", 0 }
+
+	g.Trace(context.Background(), time.Now(), query, nil)
+	g.Trace(context.Background(), time.Now(), query, gormlogger.ErrRecordNotFound)
+	assert.Equal(t, "", buf.String())
+
+	ctx := NewContext(context.Background(), l.With("request_id", "abc"))
+	g.Trace(ctx, time.Now(), query, fmt.Errorf("no such table"))
+
+	assert.Equal(t, "2020-09-13T12:26:40Z ERROR query failed request_id=abc query=\"SELECT 1\" rows=0 duration_ms=0 error=\"no such table\"\n", buf.String())
+}
@@ -0,0 +1,278 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel accepts debug, info, warn and error, empty level is info
+func ParseLevel(s string) (Level, error) {
+
+	if s == "" {
+		return LevelInfo, nil
+	}
+
+	for level, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+
+	return LevelInfo, fmt.Errorf("unknown log level: %s", s)
+}
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Redacted replaces values of sensitive keys
+const Redacted = "[redacted]"
+
+// keys whose values are never written, matched as substrings of lower cased key
+var sensitiveKeys = []string{"password", "pwd", "secret", "token", "license", "signature", "authorization", "dsn"}
+
+// Logger writes one record per line, either as text or as JSON. Records are pairs of keys and values
+// following message, e.g. Info("image deleted", "file", name).
+type Logger struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	level  Level
+	json   bool
+	fields []interface{}
+	now    func() time.Time
+}
+
+func New(out io.Writer, level Level, format string) (*Logger, error) {
+
+	if format != "" && format != FormatText && format != FormatJSON {
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+
+	return &Logger{
+		out:   out,
+		mu:    &sync.Mutex{},
+		level: level,
+		json:  format == FormatJSON,
+		now:   time.Now,
+	}, nil
+}
+
+// With returns logger which adds given pairs to every record
+func (l *Logger) With(kv ...interface{}) *Logger {
+
+	logger := *l
+	logger.fields = append(append([]interface{}{}, l.fields...), kv...)
+
+	return &logger
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *Logger) Debug(msg string, kv ...interface{}) {
+	l.write(LevelDebug, msg, kv)
+}
+
+func (l *Logger) Info(msg string, kv ...interface{}) {
+	l.write(LevelInfo, msg, kv)
+}
+
+func (l *Logger) Warn(msg string, kv ...interface{}) {
+	l.write(LevelWarn, msg, kv)
+}
+
+func (l *Logger) Error(msg string, kv ...interface{}) {
+	l.write(LevelError, msg, kv)
+}
+
+// Fatal writes error and exits
+func (l *Logger) Fatal(msg string, kv ...interface{}) {
+	l.write(LevelError, msg, kv)
+	os.Exit(1)
+}
+
+func (l *Logger) write(level Level, msg string, kv []interface{}) {
+
+	if !l.Enabled(level) {
+		return
+	}
+
+	pairs := append(append([]interface{}{}, l.fields...), kv...)
+
+	var line []byte
+
+	if l.json {
+		line = l.formatJSON(level, msg, pairs)
+	} else {
+		line = l.formatText(level, msg, pairs)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, _ = l.out.Write(line)
+}
+
+func (l *Logger) formatText(level Level, msg string, pairs []interface{}) []byte {
+
+	buf := &bytes.Buffer{}
+
+	buf.WriteString(l.now().UTC().Format(time.RFC3339))
+	buf.WriteString(" ")
+	buf.WriteString(strings.ToUpper(level.String()))
+	buf.WriteString(" ")
+	buf.WriteString(msg)
+
+	eachPair(pairs, func(key string, value interface{}) {
+		buf.WriteString(" ")
+		buf.WriteString(key)
+		buf.WriteString("=")
+		buf.WriteString(textValue(value))
+	})
+
+	buf.WriteString("\n")
+
+	return buf.Bytes()
+}
+
+func (l *Logger) formatJSON(level Level, msg string, pairs []interface{}) []byte {
+
+	buf := &bytes.Buffer{}
+
+	buf.WriteString(`{"time":`)
+	writeJSON(buf, l.now().UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSON(buf, level.String())
+	buf.WriteString(`,"msg":`)
+	writeJSON(buf, msg)
+
+	eachPair(pairs, func(key string, value interface{}) {
+		buf.WriteString(",")
+		writeJSON(buf, key)
+		buf.WriteString(":")
+		writeJSON(buf, value)
+	})
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+// eachPair walks keys and values, value of odd key is missing and values of sensitive keys are redacted
+func eachPair(pairs []interface{}, f func(key string, value interface{})) {
+
+	for idx := 0; idx < len(pairs); idx += 2 {
+
+		key := fmt.Sprint(pairs[idx])
+
+		var value interface{} = "!missing"
+		if idx+1 < len(pairs) {
+			value = pairs[idx+1]
+		}
+
+		if Sensitive(key) {
+			value = Redacted
+		}
+
+		if err, ok := value.(error); ok && err != nil {
+			value = err.Error()
+		}
+
+		f(key, value)
+	}
+}
+
+// Sensitive tells if value of key is redacted
+func Sensitive(key string) bool {
+
+	key = strings.ToLower(key)
+
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func textValue(value interface{}) string {
+
+	s := fmt.Sprintf("%+v", value)
+
+	if s == "" || strings.ContainsAny(s, " =\"\n\t") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+func writeJSON(buf *bytes.Buffer, value interface{}) {
+
+	b, err := json.Marshal(value)
+
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%+v", value))
+	}
+
+	buf.Write(b)
+}
+
+var std, _ = New(os.Stderr, LevelInfo, FormatText)
+
+// Default is logger of packages which are not given one, e.g. repositories
+func Default() *Logger {
+	return std
+}
+
+// SetDefault replaces default logger, it is meant to be called once on start
+func SetDefault(l *Logger) {
+	std = l
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx which carries l, e.g. logger of request with its id
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns logger carried by ctx or default one
+func FromContext(ctx context.Context) *Logger {
+
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+
+	return std
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+
+	l, err := New(buf, LevelInfo, FormatText)
+	assert.NoError(t, err)
+	l.now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	l = l.With("request_id", "abc")
+
+	l.Debug("skipped")
+	l.Info("image deleted", "file", "milk.png", "error", fmt.Errorf("not found"), "admin_token", "secret", "odd")
+
+	assert.Equal(t, "2020-09-13T12:26:40Z INFO image deleted request_id=abc file=milk.png error=\"not found\" admin_token=[redacted] odd=!missing\n", buf.String())
+
+	buf.Reset()
+
+	l, err = New(buf, LevelWarn, FormatJSON)
+	assert.NoError(t, err)
+	l.now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	l.Info("skipped")
+	l.Warn("rate limiter error", "account_id", 1, "password", "hunter2")
+
+	assert.Equal(t, `{"time":"2020-09-13T12:26:40Z","level":"warn","msg":"rate limiter error","account_id":1,"password":"[redacted]"}`+"\n", buf.String())
+
+	_, err = New(buf, LevelInfo, "xml")
+	assert.Error(t, err)
+}
+
+func TestParseLevel(t *testing.T) {
+
+	level, err := ParseLevel("")
+	assert.NoError(t, err)
+	assert.Equal(t, LevelInfo, level)
+
+	level, err = ParseLevel("DEBUG")
+	assert.NoError(t, err)
+	assert.Equal(t, LevelDebug, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
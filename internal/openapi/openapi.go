@@ -0,0 +1,386 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+const (
+	ContentTypeJSON       = "application/json"
+	ContentTypeMergePatch = "application/merge-patch+json"
+	ContentTypeMultipart  = "multipart/form-data"
+)
+
+type Document struct {
+	OpenApi    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	Url         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type PathItem struct {
+	Servers []Server   `json:"servers,omitempty"`
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+}
+
+func (p *PathItem) operation(method string) **Operation {
+	switch method {
+	case http.MethodGet:
+		return &p.Get
+	case http.MethodPut:
+		return &p.Put
+	case http.MethodPost:
+		return &p.Post
+	case http.MethodDelete:
+		return &p.Delete
+	case http.MethodPatch:
+		return &p.Patch
+	}
+	panic(fmt.Sprintf("openapi: unsupported method %s", method))
+}
+
+type Operation struct {
+	OperationId string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// RequestSchema returns schema of JSON body, nil means operation does not expect one
+func (o *Operation) RequestSchema() *Schema {
+
+	if o.RequestBody == nil {
+		return nil
+	}
+
+	if media, ok := o.RequestBody.Content[ContentTypeJSON]; ok {
+		return media.Schema
+	}
+
+	if media, ok := o.RequestBody.Content[ContentTypeMergePatch]; ok {
+		return media.Schema
+	}
+
+	return nil
+}
+
+// ResponseSchema returns schema of JSON response with given status, nil means response is not JSON
+func (o *Operation) ResponseSchema(status int) *Schema {
+
+	response, ok := o.Responses[strconv.Itoa(status)]
+
+	if !ok {
+		response, ok = o.Responses["default"]
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if media, ok := response.Content[ContentTypeJSON]; ok {
+		return media.Schema
+	}
+
+	return nil
+}
+
+// RespondsWithJSON reports whether successful response is JSON
+func (o *Operation) RespondsWithJSON() bool {
+
+	for status, response := range o.Responses {
+		if _, ok := response.Content[ContentTypeJSON]; ok && status != "default" {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Operation finds operation by method and path template, e.g. GET /product/{id}/
+func (d *Document) Operation(method, path string) *Operation {
+
+	item, ok := d.Paths[path]
+
+	if !ok {
+		return nil
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch:
+		return *item.operation(method)
+	}
+
+	return nil
+}
+
+// Operations lists every described operation as "METHOD path"
+func (d *Document) Operations() []string {
+
+	var operations []string
+
+	for path, item := range d.Paths {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch} {
+			if *item.operation(method) != nil {
+				operations = append(operations, method+" "+path)
+			}
+		}
+	}
+
+	sort.Strings(operations)
+
+	return operations
+}
+
+// Route describes single operation. Request and Response are samples of values which are sent and received,
+// their schemas are generated from types.
+type Route struct {
+	Id          string
+	Method      string
+	Path        string
+	Summary     string
+	Tag         string
+	Parameters  []Parameter
+	Request     interface{}
+	Response    interface{}
+	Status      int
+	ContentType string
+	// Bare response is not wrapped into envelope
+	Bare    bool
+	Headers map[string]Header
+	Servers []Server
+}
+
+// MergePatch marks request as JSON Merge Patch of given representation
+type MergePatch struct {
+	Of interface{}
+}
+
+// Upload marks request as multipart form with file in given field
+type Upload struct {
+	Field string
+}
+
+var pathParameter = regexp.MustCompile(`{([^}]+)}`)
+
+type Builder struct {
+	doc     Document
+	formats map[reflect.Type]Schema
+	names   map[string]reflect.Type
+
+	// Envelope wraps schema of successful JSON response
+	Envelope func(data *Schema) *Schema
+	// Error is schema of response returned when operation fails
+	Error *Schema
+}
+
+// Format overrides schema generated for type of sample, it is needed for types with custom json marshalling
+func (b *Builder) Format(sample interface{}, schema Schema) {
+	b.formats[reflect.TypeOf(sample)] = schema
+}
+
+func (b *Builder) Add(route Route) {
+
+	item, ok := b.doc.Paths[route.Path]
+
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[route.Path] = item
+	}
+
+	if route.Servers != nil {
+		item.Servers = route.Servers
+	}
+
+	operation := item.operation(route.Method)
+
+	if *operation != nil {
+		panic(fmt.Sprintf("openapi: %s %s is already described", route.Method, route.Path))
+	}
+
+	*operation = b.operation(route)
+}
+
+func (b *Builder) operation(route Route) *Operation {
+
+	op := &Operation{
+		OperationId: route.Id,
+		Summary:     route.Summary,
+		Responses:   map[string]Response{},
+	}
+
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	declared := map[string]bool{}
+
+	for _, p := range route.Parameters {
+		declared[p.Name] = true
+	}
+
+	for _, match := range pathParameter.FindAllStringSubmatch(route.Path, -1) {
+		if declared[match[1]] {
+			continue
+		}
+
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "integer"},
+		})
+	}
+
+	op.Parameters = append(op.Parameters, route.Parameters...)
+
+	switch request := route.Request.(type) {
+	case nil:
+	case MergePatch:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				ContentTypeMergePatch: {Schema: &Schema{
+					Type:        "object",
+					Description: fmt.Sprintf("JSON Merge Patch (RFC 7396) of %s", strings.TrimPrefix(b.Schema(reflect.TypeOf(request.Of)).Ref, refPrefix)),
+				}},
+			},
+		}
+	case Upload:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				ContentTypeMultipart: {Schema: &Schema{
+					Type:       "object",
+					Properties: map[string]*Schema{request.Field: {Type: "string", Format: "binary"}},
+					Required:   []string{request.Field},
+				}},
+			},
+		}
+	default:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				ContentTypeJSON: {Schema: b.Schema(reflect.TypeOf(request))},
+			},
+		}
+	}
+
+	status := route.Status
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	response := Response{
+		Description: http.StatusText(status),
+		Headers:     route.Headers,
+	}
+
+	switch route.ContentType {
+	case "", ContentTypeJSON:
+		data := &Schema{Nullable: true}
+
+		if route.Response != nil {
+			data = b.Schema(reflect.TypeOf(route.Response))
+		}
+
+		if b.Envelope != nil && !route.Bare {
+			data = b.Envelope(data)
+		}
+
+		response.Content = map[string]MediaType{ContentTypeJSON: {Schema: data}}
+	default:
+		response.Content = map[string]MediaType{route.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	}
+
+	op.Responses[strconv.Itoa(status)] = response
+
+	if b.Error != nil {
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{ContentTypeJSON: {Schema: b.Error}},
+		}
+	}
+
+	return op
+}
+
+func (b *Builder) Document() Document {
+	return b.doc
+}
+
+func NewBuilder(info Info, servers ...Server) *Builder {
+
+	b := &Builder{
+		doc: Document{
+			OpenApi: Version,
+			Info:    info,
+			Servers: servers,
+			Paths:   map[string]*PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+			},
+		},
+		formats: map[reflect.Type]Schema{},
+		names:   map[string]reflect.Type{},
+	}
+
+	return b
+}
@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Item struct {
+	Id       int      `json:"id"`
+	Title    string   `json:"title" validate:"required,max=5"`
+	Quantity uint     `json:"quantity" validate:"min=1"`
+	Tags     []string `json:"tags"`
+	Version  int      `json:"-"`
+}
+
+type Order struct {
+	Items []Item  `json:"items" validate:"required"`
+	Note  *string `json:"note"`
+}
+
+func decode(t *testing.T, body string) interface{} {
+
+	var value interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	assert.NoError(t, decoder.Decode(&value))
+
+	return value
+}
+
+func TestSchema(t *testing.T) {
+
+	b := NewBuilder(Info{Title: "test", Version: "1"})
+
+	schema := b.Schema(reflect.TypeOf(Order{}))
+
+	assert.Equal(t, "#/components/schemas/OpenapiOrder", schema.Ref)
+
+	doc := b.Document()
+	o := doc.Components.Schemas["OpenapiOrder"]
+
+	assert.Equal(t, []string{"items"}, o.Required)
+	assert.Equal(t, 1, *o.Properties["items"].MinItems)
+	assert.False(t, o.Properties["items"].Nullable)
+	assert.Equal(t, "#/components/schemas/OpenapiItem", o.Properties["items"].Items.Ref)
+	assert.True(t, o.Properties["note"].Nullable)
+
+	i := doc.Components.Schemas["OpenapiItem"]
+
+	assert.NotContains(t, i.Properties, "Version")
+	assert.Equal(t, []string{"title"}, i.Required)
+	assert.Equal(t, 1, *i.Properties["title"].MinLength)
+	assert.Equal(t, 5, *i.Properties["title"].MaxLength)
+	assert.Equal(t, int64(1), *i.Properties["quantity"].Minimum)
+	assert.True(t, i.Properties["tags"].Nullable)
+}
+
+func TestAdd(t *testing.T) {
+
+	b := NewBuilder(Info{Title: "test", Version: "1"})
+
+	b.Add(Route{Id: "getItem", Method: http.MethodGet, Path: "/item/{id}/", Response: Item{}})
+	b.Add(Route{Id: "createItem", Method: http.MethodPost, Path: "/item/", Request: Item{}, Response: Item{}, Status: http.StatusCreated})
+
+	doc := b.Document()
+
+	assert.Equal(t, []string{"GET /item/{id}/", "POST /item/"}, doc.Operations())
+
+	get := doc.Operation(http.MethodGet, "/item/{id}/")
+
+	assert.Equal(t, "id", get.Parameters[0].Name)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.Nil(t, get.RequestSchema())
+	assert.True(t, get.RespondsWithJSON())
+
+	create := doc.Operation(http.MethodPost, "/item/")
+
+	assert.NotNil(t, create.RequestSchema())
+	assert.NotNil(t, create.ResponseSchema(http.StatusCreated))
+	assert.Nil(t, create.ResponseSchema(http.StatusOK))
+}
+
+func TestValidate(t *testing.T) {
+
+	b := NewBuilder(Info{Title: "test", Version: "1"})
+	schema := b.Schema(reflect.TypeOf(Order{}))
+	doc := b.Document()
+
+	assert.Empty(t, doc.Validate(schema, decode(t, `{"items": [{"id": 1, "title": "milk", "quantity": 2, "tags": null}], "note": null}`), true))
+
+	fields := func(body string, strict bool) []string {
+		var names []string
+		for _, f := range doc.Validate(schema, decode(t, body), strict) {
+			names = append(names, f.Field)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"items"}, fields(`{}`, false))
+	assert.Equal(t, []string{"items"}, fields(`{"items": []}`, false))
+	assert.Equal(t, []string{"body"}, fields(`[]`, false))
+	assert.Equal(t, []string{"items[0].title", "items[0].id", "items[0].quantity"}, fields(`{"items": [{"id": "1", "quantity": 0}]}`, false))
+	assert.Equal(t, []string{"items[0].title"}, fields(`{"items": [{"title": "cheese"}]}`, false))
+	assert.Empty(t, fields(`{"items": [{"title": "milk", "color": "white"}]}`, false))
+	assert.Equal(t, []string{"items[0].color"}, fields(`{"items": [{"title": "milk", "color": "white"}]}`, true))
+}
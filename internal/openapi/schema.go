@@ -0,0 +1,249 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/proviant-io/core/internal/validation"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const refPrefix = "#/components/schemas/"
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Minimum              *int64             `json:"minimum,omitempty"`
+	Maximum              *int64             `json:"maximum,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema generates schema of type, named structs are placed into components and referenced
+func (b *Builder) Schema(t reflect.Type) *Schema {
+
+	if format, ok := b.formats[t]; ok {
+		return &format
+	}
+
+	switch t {
+	case rawMessageType:
+		return &Schema{}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := b.Schema(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: int64Ptr(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// nil slices are encoded as null
+		return &Schema{Type: "array", Items: b.Schema(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: b.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return b.component(t)
+	}
+
+	panic(fmt.Sprintf("openapi: %s is not supported", t))
+}
+
+func (b *Builder) component(t reflect.Type) *Schema {
+
+	name := componentName(t)
+	ref := &Schema{Ref: refPrefix + name}
+
+	if known, ok := b.names[name]; ok {
+		if known != t {
+			panic(fmt.Sprintf("openapi: %s and %s have the same name %s", known, t, name))
+		}
+		return ref
+	}
+
+	// registered before fields are processed, so recursive types refer to themselves
+	b.names[name] = t
+	b.doc.Components.Schemas[name] = b.object(t)
+
+	return ref
+}
+
+// Component places schema into components under given name and returns reference to it
+func (b *Builder) Component(name string, schema *Schema) *Schema {
+
+	if _, ok := b.doc.Components.Schemas[name]; ok {
+		panic(fmt.Sprintf("openapi: component %s is already defined", name))
+	}
+
+	b.doc.Components.Schemas[name] = schema
+
+	return &Schema{Ref: refPrefix + name}
+}
+
+// componentName prefixes type with package, so product.DTO becomes ProductDTO
+func componentName(t reflect.Type) string {
+
+	pkg := strings.ReplaceAll(strings.Title(strings.ReplaceAll(path.Base(t.PkgPath()), "_", " ")), " ", "")
+
+	if strings.HasPrefix(t.Name(), pkg) {
+		return t.Name()
+	}
+
+	return pkg + t.Name()
+}
+
+func (b *Builder) object(t reflect.Type) *Schema {
+
+	schema := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+
+	b.fields(t, schema)
+
+	return schema
+}
+
+func (b *Builder) fields(t reflect.Type, schema *Schema) {
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		if name == "-" {
+			continue
+		}
+
+		// fields of embedded structs are encoded as own ones
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.fields(field.Type, schema)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := b.Schema(field.Type)
+
+		if applyRules(property, field.Tag.Get(validation.Tag)) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules describes validation rules of field in schema, it reports whether field is required
+func applyRules(schema *Schema, tag string) bool {
+
+	if tag == "" || schema.Ref != "" {
+		return false
+	}
+
+	rules := strings.Split(tag, ",")
+
+	// limits of optional fields apply only to non zero values, schema is not able to tell that
+	if rules[0] == "omitempty" {
+		return false
+	}
+
+	required := false
+
+	for _, rule := range rules {
+		name, param := rule, ""
+
+		if pos := strings.Index(rule, "="); pos != -1 {
+			name, param = rule[:pos], rule[pos+1:]
+		}
+
+		limit, _ := strconv.ParseInt(param, 10, 64)
+
+		switch name {
+		case "required":
+			required = true
+			switch schema.Type {
+			case "string":
+				schema.MinLength = intPtr(1)
+			case "array":
+				schema.MinItems = intPtr(1)
+				schema.Nullable = false
+			}
+		case "min":
+			switch schema.Type {
+			case "integer":
+				schema.Minimum = int64Ptr(limit)
+			case "array":
+				schema.Items.Minimum = int64Ptr(limit)
+			}
+		case "max":
+			switch schema.Type {
+			case "integer":
+				schema.Maximum = int64Ptr(limit)
+			case "string":
+				schema.MaxLength = intPtr(int(limit))
+			}
+		case "nonnegative":
+			if schema.Type == "integer" {
+				schema.Minimum = int64Ptr(0)
+			}
+		case "url":
+			schema.Format = "uri"
+		}
+	}
+
+	return required
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks decoded JSON value against schema, numbers are expected to be decoded as json.Number.
+// Strict mode reports properties which are not described, it is used for responses.
+func (d *Document) Validate(schema *Schema, value interface{}, strict bool) []errors.FieldError {
+	return d.validate(schema, value, "", strict)
+}
+
+func (d *Document) resolve(schema *Schema) *Schema {
+
+	for schema.Ref != "" {
+		resolved, ok := d.Components.Schemas[strings.TrimPrefix(schema.Ref, refPrefix)]
+
+		if !ok {
+			panic(fmt.Sprintf("openapi: unknown schema %s", schema.Ref))
+		}
+
+		schema = resolved
+	}
+
+	return schema
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, field string, strict bool) []errors.FieldError {
+
+	schema = d.resolve(schema)
+	name := field
+
+	if name == "" {
+		name = "body"
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return violation(name, "%s should be %s", name, schema.Type)
+	}
+
+	if len(schema.OneOf) > 0 {
+		var fieldErrors []errors.FieldError
+
+		for _, option := range schema.OneOf {
+			if fieldErrors = d.validate(option, value, field, strict); len(fieldErrors) == 0 {
+				return nil
+			}
+		}
+
+		return fieldErrors
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]interface{})
+
+		if !ok {
+			return violation(name, "%s should be %s", name, schema.Type)
+		}
+
+		return d.validateObject(schema, object, field, strict)
+	case "array":
+		array, ok := value.([]interface{})
+
+		if !ok {
+			return violation(name, "%s should be %s", name, schema.Type)
+		}
+
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			return violation(name, "%s should not be empty", name)
+		}
+
+		var fieldErrors []errors.FieldError
+
+		for i, item := range array {
+			fieldErrors = append(fieldErrors, d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", name, i), strict)...)
+		}
+
+		return fieldErrors
+	case "string":
+		s, ok := value.(string)
+
+		if !ok {
+			return violation(name, "%s should be %s", name, schema.Type)
+		}
+
+		length := utf8.RuneCountInString(s)
+
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return violation(name, "%s should not be empty", name)
+		}
+
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return violation(name, "%s should not be longer than %d characters", name, *schema.MaxLength)
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+
+		if !ok {
+			return violation(name, "%s should be %s", name, schema.Type)
+		}
+
+		if schema.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				return violation(name, "%s should be %s", name, schema.Type)
+			}
+		}
+
+		n, _ := number.Float64()
+
+		if schema.Minimum != nil && n < float64(*schema.Minimum) {
+			return violation(name, "%s should be at least %d", name, *schema.Minimum)
+		}
+
+		if schema.Maximum != nil && n > float64(*schema.Maximum) {
+			return violation(name, "%s should be at most %d", name, *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return violation(name, "%s should be %s", name, schema.Type)
+		}
+	}
+
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]interface{}, field string, strict bool) []errors.FieldError {
+
+	var fieldErrors []errors.FieldError
+
+	for _, required := range schema.Required {
+		if _, ok := object[required]; !ok {
+			fieldErrors = append(fieldErrors, violation(join(field, required), "%s should not be empty", join(field, required))...)
+		}
+	}
+
+	// sorted, so violations are reported in the same order every time
+	keys := make([]string, 0, len(object))
+
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := schema.Properties[key]
+
+		if !ok {
+			property = schema.AdditionalProperties
+		}
+
+		if property == nil {
+			if strict && schema.Properties != nil {
+				fieldErrors = append(fieldErrors, violation(join(field, key), "%s is not described in specification", join(field, key))...)
+			}
+			continue
+		}
+
+		fieldErrors = append(fieldErrors, d.validate(property, object[key], join(field, key), strict)...)
+	}
+
+	return fieldErrors
+}
+
+func join(parent, field string) string {
+
+	if parent == "" {
+		return field
+	}
+
+	return parent + "." + field
+}
+
+func violation(field string, template string, params ...interface{}) []errors.FieldError {
+	return []errors.FieldError{{Field: field, Message: i18n.NewMessage(template, params...)}}
+}
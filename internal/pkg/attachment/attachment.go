@@ -0,0 +1,188 @@
+package attachment
+
+import (
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"gorm.io/gorm"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// MaxSize is the biggest attachment accepted, in bytes
+const MaxSize = 20 * 1024 * 1024
+
+// SniffLength is how many leading bytes are needed to detect type of content
+const SniffLength = 512
+
+// types are accepted types of content with extensions files are stored under
+var types = map[string]string{
+	"application/pdf": "pdf",
+	"image/png":       "png",
+	"image/jpeg":      "jpeg",
+	"image/gif":       "gif",
+	"image/webp":      "webp",
+	"text/plain":      "txt",
+}
+
+// Attachment is document of product, e.g. receipt, warranty or manual
+type Attachment struct {
+	gorm.Model
+	Id        int    `json:"id" gorm:"primaryKey;autoIncrement;"`
+	ProductId int    `json:"product_id" gorm:"index"`
+	Name      string `json:"name"`
+	Mime      string `json:"mime"`
+	Size      int64  `json:"size"`
+	// File is name content is stored under
+	File      string `json:"file"`
+	AccountId int    `json:"account_id" gorm:"default:0;index"`
+}
+
+type DTO struct {
+	Id        int    `json:"id"`
+	ProductId int    `json:"product_id"`
+	Name      string `json:"name"`
+	Mime      string `json:"mime"`
+	Size      int64  `json:"size"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Sniff detects type of content from its leading bytes, declared type is not trusted.
+// It returns extension content is stored under, empty one means type is not accepted.
+func Sniff(head []byte) (string, string) {
+
+	detected := http.DetectContentType(head)
+
+	mimeType, _, err := mime.ParseMediaType(detected)
+
+	if err != nil {
+		return detected, ""
+	}
+
+	return mimeType, types[mimeType]
+}
+
+// CleanName keeps base of uploaded file name without characters which break Content-Disposition header
+func CleanName(name string) string {
+
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "." || name == "/" {
+		return ""
+	}
+
+	return name
+}
+
+type Repository struct {
+	db db.DB
+}
+
+func (r *Repository) Get(id int, productId int, accountId int) (Attachment, *errors.CustomError) {
+
+	model := &Attachment{}
+
+	r.db.Connection().First(model, "id = ? and product_id = ? and account_id = ?", id, productId, accountId)
+
+	if (*model).Id == 0 {
+		return Attachment{}, errors.NewErrNotFound(i18n.NewMessage("attachment with id %d not found", id))
+	}
+
+	return *model, nil
+}
+
+func (r *Repository) GetByProductId(productId int, accountId int) []Attachment {
+
+	var models []Attachment
+	r.db.Connection().Where("product_id = ? and account_id = ?", productId, accountId).Order("id").Find(&models)
+
+	return models
+}
+
+func (r *Repository) GetAll(accountId int) []Attachment {
+
+	var models []Attachment
+	r.db.Connection().Where("account_id = ?", accountId).Find(&models)
+
+	return models
+}
+
+func (r *Repository) Create(m Attachment) Attachment {
+	r.db.Connection().Create(&m)
+	return m
+}
+
+func (r *Repository) Delete(id int, accountId int) {
+	r.db.Connection().Where("id = ? and account_id = ?", id, accountId).Unscoped().Delete(&Attachment{})
+}
+
+func (r *Repository) DeleteByProductId(productId int, accountId int) {
+	r.db.Connection().Where("product_id = ? and account_id = ?", productId, accountId).Unscoped().Delete(&Attachment{})
+}
+
+// TotalSize returns size of attachments of all accounts, they are stored as they were uploaded
+func (r *Repository) TotalSize() int64 {
+	var size int64
+	r.db.Connection().Model(&Attachment{}).Select("coalesce(sum(size), 0)").Row().Scan(&size)
+	return size
+}
+
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Attachment{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Attachment{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+func ModelToDTO(m Attachment) DTO {
+	return DTO{
+		Id:        m.Id,
+		ProductId: m.ProductId,
+		Name:      m.Name,
+		Mime:      m.Mime,
+		Size:      m.Size,
+		CreatedAt: m.CreatedAt.Unix(),
+	}
+}
+
+func (r *Repository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Attachment{})
+	if err != nil {
+		return fmt.Errorf("migration of Attachment table failed: %v", err)
+	}
+	return nil
+}
+
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
+func Setup(d db.DB) (*Repository, error) {
+
+	repo := &Repository{}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,33 @@
+package attachment
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+
+	mimeType, ext := Sniff([]byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"))
+	assert.Equal(t, "application/pdf", mimeType)
+	assert.Equal(t, "pdf", ext)
+
+	mimeType, ext = Sniff([]byte("Warranty: 2 years"))
+	assert.Equal(t, "text/plain", mimeType)
+	assert.Equal(t, "txt", ext)
+
+	// declared type does not matter, html is not accepted whatever name it has
+	_, ext = Sniff([]byte("<html><script>alert(1)</script></html>"))
+	assert.Empty(t, ext)
+
+	_, ext = Sniff([]byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff\x00\x00"))
+	assert.Empty(t, ext)
+}
+
+func TestCleanName(t *testing.T) {
+
+	assert.Equal(t, "receipt.pdf", CleanName("receipt.pdf"))
+	assert.Equal(t, "receipt.pdf", CleanName("C:\\Users\\me\\receipt.pdf"))
+	assert.Equal(t, "passwd", CleanName("../../etc/passwd"))
+	assert.Equal(t, "manual.pdf", CleanName("man\"ual\r\n.pdf"))
+	assert.Equal(t, "", CleanName(""))
+}
@@ -0,0 +1,372 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/logger"
+	"gorm.io/gorm"
+	"reflect"
+	"time"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+const (
+	EntityProduct      = "product"
+	EntityStock        = "stock"
+	EntityList         = "list"
+	EntityCategory     = "category"
+	EntityShoppingItem = "shopping_item"
+)
+
+const DefaultLimit = 100
+const MaxLimit = 1000
+
+type Entry struct {
+	gorm.Model
+	Id        int    `json:"id" gorm:"primaryKey;autoIncrement;"`
+	Action    string `json:"action" gorm:"index"`
+	Entity    string `json:"entity" gorm:"index"`
+	EntityId  int    `json:"entity_id" gorm:"index"`
+	Before    string `json:"before" gorm:"type:text"`
+	After     string `json:"after" gorm:"type:text"`
+	Diff      string `json:"diff" gorm:"type:text"`
+	Timestamp int64  `json:"timestamp" gorm:"index"`
+	AccountId int    `json:"account_id" gorm:"default:0;index"`
+	UserId    int    `json:"user_id" gorm:"default:0;index"`
+	// Sequence orders entries by commit, it is assigned by Seal once entry is committed
+	Sequence *int `json:"sequence" gorm:"uniqueIndex"`
+}
+
+func (Entry) TableName() string {
+	return "audit_entries"
+}
+
+type DTO struct {
+	Id        int             `json:"id"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityId  int             `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Diff      json.RawMessage `json:"diff"`
+	Timestamp int64           `json:"timestamp"`
+	AccountId int             `json:"account_id"`
+	UserId    int             `json:"user_id"`
+}
+
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type Query struct {
+	Entity   string
+	EntityId int
+	Action   string
+	UserId   int
+	From     int64
+	To       int64
+	Limit    int
+	Offset   int
+}
+
+// Ref points to entity which was changed
+type Ref struct {
+	Entity string
+	Id     int
+}
+
+type Repository struct {
+	db db.DB
+}
+
+// Record stores a single mutation. before is nil for creations and after is nil for deletions.
+// Failures are logged only, a broken audit trail should never block the mutation itself.
+func (r *Repository) Record(action, entity string, entityId int, before, after interface{}, accountId, userId int) Entry {
+
+	beforeMap, beforeJson := snapshot(before)
+	afterMap, afterJson := snapshot(after)
+
+	diffJson, err := json.Marshal(Diff(beforeMap, afterMap))
+	if err != nil {
+		logger.Default().Error("audit: cannot marshal diff", "entity", entity, "entity_id", entityId, "error", err)
+		diffJson = []byte("{}")
+	}
+
+	model := Entry{
+		Action:    action,
+		Entity:    entity,
+		EntityId:  entityId,
+		Before:    beforeJson,
+		After:     afterJson,
+		Diff:      string(diffJson),
+		Timestamp: time.Now().Unix(),
+		AccountId: accountId,
+		UserId:    userId,
+	}
+
+	result := r.db.Connection().Create(&model)
+	if result.Error != nil {
+		logger.Default().Error("audit: cannot record", "action", action, "entity", entity, "entity_id", entityId, "error", result.Error)
+	}
+
+	return model
+}
+
+func (r *Repository) GetAll(query Query, accountId int) []Entry {
+
+	var models []Entry
+
+	q := r.db.Connection().Where("account_id = ?", accountId)
+
+	if query.Entity != "" {
+		q = q.Where("entity = ?", query.Entity)
+	}
+
+	if query.EntityId != 0 {
+		q = q.Where("entity_id = ?", query.EntityId)
+	}
+
+	if query.Action != "" {
+		q = q.Where("action = ?", query.Action)
+	}
+
+	if query.UserId != 0 {
+		q = q.Where("user_id = ?", query.UserId)
+	}
+
+	if query.From != 0 {
+		q = q.Where("timestamp >= ?", query.From)
+	}
+
+	if query.To != 0 {
+		q = q.Where("timestamp <= ?", query.To)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	q.Order("id DESC").Limit(limit).Offset(query.Offset).Find(&models)
+
+	return models
+}
+
+// Seal assigns sequence to committed entries. Entries recorded inside of transaction could be committed after
+// entries with higher id, so id could not tell what was seen already, sequence is given in order entries
+// become visible instead. Concurrent seals which pick the same sequence are rolled back by unique index,
+// entries left without sequence are sealed by the next call.
+func (r *Repository) Seal() {
+
+	var ids []int
+	r.db.Connection().Model(&Entry{}).Where("sequence is null").Order("id ASC").Pluck("id", &ids)
+
+	if len(ids) == 0 {
+		return
+	}
+
+	err := r.db.Connection().Transaction(func(tx *gorm.DB) error {
+
+		var last int
+		err := tx.Model(&Entry{}).Select("coalesce(max(sequence), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			last++
+			err := tx.Model(&Entry{}).Where("id = ? and sequence is null", id).UpdateColumn("sequence", last).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logger.Default().Warn("audit: cannot seal entries", "error", err)
+	}
+}
+
+// GetSince returns sealed entries with sequence after cursor, the oldest first
+func (r *Repository) GetSince(cursor, limit, accountId int) []Entry {
+
+	r.Seal()
+
+	var models []Entry
+	r.db.Connection().Where("account_id = ? and sequence > ?", accountId, cursor).Order("sequence ASC").Limit(limit).Find(&models)
+
+	return models
+}
+
+// LastSequence returns sequence of the latest sealed entry, 0 when nothing was recorded yet. Sequence is shared
+// by accounts, so sequence of the latest entry overall serves every account.
+func (r *Repository) LastSequence() int {
+
+	r.Seal()
+
+	var last int
+	r.db.Connection().Model(&Entry{}).Select("coalesce(max(sequence), 0)").Scan(&last)
+
+	return last
+}
+
+func (r *Repository) GetAllByAccountId(accountId int) []Entry {
+
+	var models []Entry
+	r.db.Connection().Where("account_id = ?", accountId).Order("id ASC").Find(&models)
+
+	return models
+}
+
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Entry{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Entry{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+func (r *Repository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Entry{})
+	if err != nil {
+		return fmt.Errorf("migration of AuditEntry table failed: %v", err)
+	}
+	return nil
+}
+
+func ModelToDTO(m Entry) DTO {
+	return DTO{
+		Id:        m.Id,
+		Action:    m.Action,
+		Entity:    m.Entity,
+		EntityId:  m.EntityId,
+		Before:    rawOrNull(m.Before),
+		After:     rawOrNull(m.After),
+		Diff:      rawOrNull(m.Diff),
+		Timestamp: m.Timestamp,
+		AccountId: m.AccountId,
+		UserId:    m.UserId,
+	}
+}
+
+// Changed returns entities touched by entries, every entity once in order of its first change.
+// Stock of product is part of product, so change of a lot marks its product as changed as well.
+func Changed(entries []Entry) []Ref {
+
+	refs := []Ref{}
+	seen := map[Ref]bool{}
+
+	add := func(ref Ref) {
+		if ref.Id == 0 || seen[ref] {
+			return
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+
+	for _, entry := range entries {
+
+		add(Ref{Entity: entry.Entity, Id: entry.EntityId})
+
+		if entry.Entity != EntityStock {
+			continue
+		}
+
+		for _, s := range []string{entry.After, entry.Before} {
+			lot := struct {
+				ProductId int `json:"product_id"`
+			}{}
+
+			if s != "" && json.Unmarshal([]byte(s), &lot) == nil && lot.ProductId != 0 {
+				add(Ref{Entity: EntityProduct, Id: lot.ProductId})
+				break
+			}
+		}
+	}
+
+	return refs
+}
+
+// Diff returns fields which differ between two snapshots, keyed by json field name
+func Diff(before, after map[string]interface{}) map[string]FieldChange {
+
+	changes := map[string]FieldChange{}
+
+	for key, beforeValue := range before {
+		afterValue, ok := after[key]
+		if !ok || !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[key] = FieldChange{Before: beforeValue, After: afterValue}
+		}
+	}
+
+	for key, afterValue := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = FieldChange{Before: nil, After: afterValue}
+		}
+	}
+
+	return changes
+}
+
+func snapshot(v interface{}) (map[string]interface{}, string) {
+
+	if v == nil {
+		return map[string]interface{}{}, ""
+	}
+
+	payload, err := json.Marshal(v)
+	if err != nil {
+		logger.Default().Error("audit: cannot marshal snapshot", "error", err)
+		return map[string]interface{}{}, ""
+	}
+
+	m := map[string]interface{}{}
+	err = json.Unmarshal(payload, &m)
+	if err != nil {
+		// not an object, keep raw payload only
+		return map[string]interface{}{}, string(payload)
+	}
+
+	return m, string(payload)
+}
+
+func rawOrNull(s string) json.RawMessage {
+	if s == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s)
+}
+
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
+func Setup(d db.DB) (*Repository, error) {
+
+	repo := &Repository{}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+
+	before := map[string]interface{}{
+		"title": "Rice",
+		"stock": float64(3),
+		"image": "/uc/img/1.png",
+	}
+
+	after := map[string]interface{}{
+		"title":   "Rice",
+		"stock":   float64(1),
+		"barcode": "123",
+	}
+
+	expected := map[string]FieldChange{
+		"stock":   {Before: float64(3), After: float64(1)},
+		"image":   {Before: "/uc/img/1.png", After: nil},
+		"barcode": {Before: nil, After: "123"},
+	}
+
+	assert.Equal(t, expected, Diff(before, after))
+}
+
+func TestSnapshot(t *testing.T) {
+
+	m, raw := snapshot(nil)
+	assert.Empty(t, m)
+	assert.Equal(t, "", raw)
+
+	m, raw = snapshot(struct {
+		Title string `json:"title"`
+	}{Title: "Rice"})
+	assert.Equal(t, map[string]interface{}{"title": "Rice"}, m)
+	assert.Equal(t, `{"title":"Rice"}`, raw)
+}
+
+func TestChanged(t *testing.T) {
+
+	entries := []Entry{
+		{Entity: EntityProduct, EntityId: 1},
+		{Entity: EntityStock, EntityId: 4, After: `{"id": 4, "product_id": 2}`},
+		{Entity: EntityProduct, EntityId: 1},
+		{Entity: EntityStock, EntityId: 5, Before: `{"id": 5, "product_id": 1}`},
+		{Entity: EntityCategory, EntityId: 3},
+	}
+
+	expected := []Ref{
+		{Entity: EntityProduct, Id: 1},
+		{Entity: EntityStock, Id: 4},
+		{Entity: EntityProduct, Id: 2},
+		{Entity: EntityStock, Id: 5},
+		{Entity: EntityCategory, Id: 3},
+	}
+
+	assert.Equal(t, expected, Changed(entries))
+	assert.Empty(t, Changed(nil))
+}
@@ -13,11 +13,13 @@ type Category struct {
 	Id        int    `json:"id" gorm:"primaryKey;autoIncrement;"`
 	Title     string `json:"title"`
 	AccountId int    `json:"account_id" gorm:"default:0;index"`
+	Version   int    `json:"version" gorm:"default:1;not null"`
 }
 
 type DTO struct {
-	Id        int    `json:"id"`
-	Title     string `json:"title"`
+	Id      int    `json:"id"`
+	Version int    `json:"-"`
+	Title   string `json:"title" validate:"required,max=255"`
 }
 
 type Repository struct {
@@ -53,23 +55,37 @@ func (r *Repository) GetAll(accountId int) []Category {
 	return categories
 }
 
-func (r *Repository) Delete(id, accountId int) *errors.CustomError {
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Category{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Category{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+func (r *Repository) Delete(id, version, accountId int) *errors.CustomError {
 
-	model, err := r.Get(id, accountId)
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return err
 	}
 
-	r.db.Connection().Unscoped().Delete(model, id)
+	if !db.DeleteVersioned(r.db.Connection(), &Category{}, id, accountId, version) {
+		return errors.NewErrPreconditionFailed(i18n.NewMessage("category with id %d was changed by someone else", id))
+	}
+
 	return nil
 }
 
 func (r *Repository) Create(dto DTO, accountId int) Category {
 
 	model := Category{
-		Title: dto.Title,
+		Title:     dto.Title,
 		AccountId: accountId,
+		Version:   1,
 	}
 
 	r.db.Connection().Create(&model)
@@ -78,34 +94,45 @@ func (r *Repository) Create(dto DTO, accountId int) Category {
 
 func (r *Repository) Update(id int, dto DTO, accountId int) (Category, *errors.CustomError) {
 
-	model, err := r.Get(id, accountId)
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return Category{}, err
 	}
 
-	model.Title = dto.Title
+	updated := db.UpdateVersioned(r.db.Connection(), &Category{}, id, accountId, dto.Version, map[string]interface{}{
+		"title": dto.Title,
+	})
+
+	if !updated {
+		return Category{}, errors.NewErrPreconditionFailed(i18n.NewMessage("category with id %d was changed by someone else", id))
+	}
 
-	r.db.Connection().Model(&Category{Id: id}).Updates(&model)
-	return model, nil
+	return r.Get(id, accountId)
 }
 
 func ModelToDTO(m Category) DTO {
 	return DTO{
-		Id:    m.Id,
-		Title: m.Title,
+		Id:      m.Id,
+		Version: m.Version,
+		Title:   m.Title,
 	}
 }
 
 func (r *Repository) Migrate() error {
 	// Migrate the schema
-	err := r.db.Connection().AutoMigrate(&Category{})
+	err := db.AutoMigrate(r.db, &Category{})
 	if err != nil {
 		return fmt.Errorf("migration of Product table failed: %v", err)
 	}
 	return nil
 }
 
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
 func Setup(d db.DB) (*Repository, error) {
 
 	repo := &Repository{}
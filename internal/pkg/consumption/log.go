@@ -61,6 +61,22 @@ func (r *LogRepository) GetAllByProductId(id int, accountId int) []Log {
 	return s
 }
 
+func (r *LogRepository) GetAllByProductIds(ids []int, accountId int) []Log {
+
+	var s []Log
+	r.db.Connection().Where("product_id IN (?) and account_id = ?", ids, accountId).Order("consumed_at DESC").Find(&s)
+
+	return s
+}
+
+func (r *LogRepository) GetAll(accountId int) []Log {
+
+	var s []Log
+	r.db.Connection().Where("account_id = ?", accountId).Order("consumed_at DESC").Find(&s)
+
+	return s
+}
+
 func (r *LogRepository) Delete(id int, accountId int) *errors.CustomError {
 
 	model, err := r.Get(id, accountId)
@@ -77,6 +93,16 @@ func (r *LogRepository) DeleteByProductId(id int, accountId int) {
 	r.db.Connection().Where("product_id = ? and account_id = ?", id, accountId).Unscoped().Delete(&Log{})
 }
 
+func (r *LogRepository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Log{})
+}
+
+func (r *LogRepository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Log{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
 func (r *LogRepository) Create(dto ConsumeDTO, accountId int, userId int) Log {
 
 	model := Log{
@@ -94,7 +120,7 @@ func (r *LogRepository) Create(dto ConsumeDTO, accountId int, userId int) Log {
 
 func (r *LogRepository) Migrate() error {
 	// Migrate the schema
-	err := r.db.Connection().AutoMigrate(&Log{})
+	err := db.AutoMigrate(r.db, &Log{})
 	if err != nil {
 		return fmt.Errorf("migration of Stock table failed: %v", err)
 	}
@@ -112,6 +138,11 @@ func ModelToDTO(m Log) DTO {
 	}
 }
 
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *LogRepository) WithDB(d db.DB) *LogRepository {
+	return &LogRepository{db: d}
+}
+
 func LogSetup(d db.DB) (*LogRepository, error) {
 
 	repo := &LogRepository{}
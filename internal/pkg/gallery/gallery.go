@@ -0,0 +1,111 @@
+package gallery
+
+import (
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"gorm.io/gorm"
+)
+
+// Item places media into photo gallery of product, the first item is primary photo
+type Item struct {
+	gorm.Model
+	Id        int `json:"id" gorm:"primaryKey;autoIncrement;"`
+	ProductId int `json:"product_id" gorm:"index"`
+	MediaId   int `json:"media_id" gorm:"index"`
+	Position  int `json:"position"`
+	AccountId int `json:"account_id" gorm:"default:0;index"`
+}
+
+func (Item) TableName() string {
+	return "gallery_items"
+}
+
+type Repository struct {
+	db db.DB
+}
+
+// GetMediaIds returns media of product gallery in order, primary photo goes first
+func (r *Repository) GetMediaIds(productId int, accountId int) []int {
+
+	var models []Item
+	r.db.Connection().Where("product_id = ? and account_id = ?", productId, accountId).Order("position").Find(&models)
+
+	ids := []int{}
+
+	for _, m := range models {
+		ids = append(ids, m.MediaId)
+	}
+
+	return ids
+}
+
+// Set replaces gallery of product with media in given order
+func (r *Repository) Set(productId int, mediaIds []int, accountId int) {
+
+	r.DeleteByProductId(productId, accountId)
+
+	for position, mediaId := range mediaIds {
+		r.db.Connection().Create(&Item{ProductId: productId, MediaId: mediaId, Position: position, AccountId: accountId})
+	}
+}
+
+func (r *Repository) DeleteByProductId(productId int, accountId int) {
+	r.db.Connection().Where("product_id = ? and account_id = ?", productId, accountId).Unscoped().Delete(&Item{})
+}
+
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Item{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Item{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+// WithPrimary returns gallery after primary photo was replaced the way product image is replaced,
+// previous primary photo leaves gallery and new one goes first. Zero primary just removes previous one.
+func WithPrimary(mediaIds []int, previous, primary int) []int {
+
+	result := []int{}
+
+	if primary != 0 {
+		result = append(result, primary)
+	}
+
+	for _, id := range mediaIds {
+		if id != previous && id != primary {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+func (r *Repository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Item{})
+	if err != nil {
+		return fmt.Errorf("migration of Gallery table failed: %v", err)
+	}
+	return nil
+}
+
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
+func Setup(d db.DB) (*Repository, error) {
+
+	repo := &Repository{}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,16 @@
+package gallery
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithPrimary(t *testing.T) {
+
+	assert.Equal(t, []int{4}, WithPrimary([]int{}, 0, 4))
+	assert.Equal(t, []int{4, 2, 3}, WithPrimary([]int{1, 2, 3}, 1, 4))
+	// photo from gallery becomes primary
+	assert.Equal(t, []int{3, 2}, WithPrimary([]int{1, 2, 3}, 1, 3))
+	assert.Equal(t, []int{2, 3}, WithPrimary([]int{1, 2, 3}, 1, 0))
+	assert.Equal(t, []int{}, WithPrimary([]int{1}, 1, 0))
+}
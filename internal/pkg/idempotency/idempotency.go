@@ -0,0 +1,213 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"hash"
+	"sync"
+	"time"
+)
+
+const DefaultRetention = 24 * time.Hour
+const pruneEveryRequests = 100
+
+// requests in progress without heartbeat for longer were most likely interrupted by restart
+const abandonAfter = time.Minute
+
+// HeartbeatInterval is how often request in progress should be touched, so it is not taken as abandoned
+const HeartbeatInterval = abandonAfter / 4
+
+// Record keeps response of request executed with idempotency key, so retries get the same result.
+// Status is 0 while original request is still being executed.
+type Record struct {
+	gorm.Model
+	Id             int    `json:"id" gorm:"primaryKey;autoIncrement;"`
+	IdempotencyKey string `json:"idempotency_key" gorm:"size:191;uniqueIndex:idx_idempotency_account_key"`
+	Fingerprint    string `json:"fingerprint" gorm:"size:64"`
+	Status         int    `json:"status"`
+	ContentType    string `json:"content_type"`
+	ETag           string `json:"etag"`
+	Body           string `json:"body" gorm:"type:mediumtext"`
+	AccountId      int    `json:"account_id" gorm:"default:0;uniqueIndex:idx_idempotency_account_key"`
+}
+
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+func (m Record) InProgress() bool {
+	return m.Status == 0
+}
+
+type Repository struct {
+	db        db.DB
+	retention time.Duration
+
+	mu    sync.Mutex
+	begun int
+}
+
+// Begin reserves key for request, when key is already taken it returns existing record and false
+func (r *Repository) Begin(key, fingerprint string, accountId int) (Record, bool) {
+
+	r.mu.Lock()
+	r.begun++
+	prune := r.begun%pruneEveryRequests == 0
+	r.mu.Unlock()
+
+	if prune {
+		r.Prune(time.Now().Add(-r.retention))
+	}
+
+	model := Record{
+		IdempotencyKey: key,
+		Fingerprint:    fingerprint,
+		AccountId:      accountId,
+	}
+
+	if r.create(&model) {
+		return model, true
+	}
+
+	existing, found := r.get(key, accountId)
+
+	if found && !r.expired(existing) {
+		return existing, false
+	}
+
+	// previous record expired or was abandoned, key could be used again. Only that record is removed,
+	// concurrent retry could have taken the key already
+	if found {
+		r.db.Connection().Unscoped().Delete(&Record{}, existing.Id)
+	}
+
+	model = Record{
+		IdempotencyKey: key,
+		Fingerprint:    fingerprint,
+		AccountId:      accountId,
+	}
+
+	if r.create(&model) {
+		return model, true
+	}
+
+	existing, _ = r.get(key, accountId)
+	return existing, false
+}
+
+func (r *Repository) expired(model Record) bool {
+
+	if model.InProgress() {
+		return model.UpdatedAt.Before(time.Now().Add(-abandonAfter))
+	}
+
+	return model.CreatedAt.Before(time.Now().Add(-r.retention))
+}
+
+// create inserts record unless key is already taken
+func (r *Repository) create(model *Record) bool {
+	result := r.db.Connection().Clauses(clause.OnConflict{DoNothing: true}).Create(model)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func (r *Repository) get(key string, accountId int) (Record, bool) {
+
+	model := Record{}
+	r.db.Connection().Where("idempotency_key = ? and account_id = ?", key, accountId).Limit(1).Find(&model)
+
+	return model, model.Id != 0
+}
+
+// Touch tells that request is still in progress
+func (r *Repository) Touch(model Record) {
+	r.db.Connection().Model(&Record{Id: model.Id}).UpdateColumn("updated_at", time.Now())
+}
+
+// Complete stores response, so the following retries could replay it
+func (r *Repository) Complete(model Record) {
+	r.db.Connection().Model(&Record{Id: model.Id}).
+		Select("Status", "ContentType", "ETag", "Body").
+		Updates(&model)
+}
+
+// Release frees key of request which failed, so it could be retried
+func (r *Repository) Release(model Record) {
+	r.db.Connection().Unscoped().Delete(&Record{}, model.Id)
+}
+
+func (r *Repository) Prune(olderThan time.Time) {
+	r.db.Connection().Where("created_at < ?", olderThan).Unscoped().Delete(&Record{})
+}
+
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Record{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Record{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+// Fingerprint identifies request, the same key must not be reused for another request
+func Fingerprint(method, path string, body []byte) string {
+
+	digest := NewDigest(method, path)
+	_, _ = digest.Write(body)
+
+	return digest.Fingerprint()
+}
+
+// Digest computes fingerprint of request while its body is streamed through it
+type Digest struct {
+	hash hash.Hash
+}
+
+func NewDigest(method, path string) *Digest {
+
+	d := &Digest{hash: sha256.New()}
+	_, _ = fmt.Fprintf(d.hash, "%s %s\n", method, path)
+
+	return d
+}
+
+func (d *Digest) Write(b []byte) (int, error) {
+	return d.hash.Write(b)
+}
+
+func (d *Digest) Fingerprint() string {
+	return hex.EncodeToString(d.hash.Sum(nil))
+}
+
+func (r *Repository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Record{})
+	if err != nil {
+		return fmt.Errorf("migration of IdempotencyKey table failed: %v", err)
+	}
+	return nil
+}
+
+func Setup(d db.DB, retention time.Duration) (*Repository, error) {
+
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+
+	repo := &Repository{
+		retention: retention,
+	}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
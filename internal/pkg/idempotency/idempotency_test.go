@@ -0,0 +1,43 @@
+package idempotency
+
+import (
+	"github.com/proviant-io/core/internal/db"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+
+	fingerprint := Fingerprint("POST", "/api/v1/product/1/add/", []byte(`{"quantity":2}`))
+
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, Fingerprint("POST", "/api/v1/product/1/add/", []byte(`{"quantity":2}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint("POST", "/api/v1/product/1/add/", []byte(`{"quantity":3}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint("POST", "/api/v1/product/1/consume/", []byte(`{"quantity":2}`)))
+}
+
+func TestAbandoned(t *testing.T) {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "idempotency.sqlite"))
+	assert.NoError(t, err)
+
+	repo, err := Setup(d, 0)
+	assert.NoError(t, err)
+
+	record, begun := repo.Begin("add-milk", "fingerprint", 1)
+	assert.True(t, begun)
+
+	// request which is still running is not abandoned, however long ago it started
+	d.Connection().Model(&Record{Id: record.Id}).UpdateColumn("created_at", time.Now().Add(-time.Hour))
+	repo.Touch(record)
+
+	_, begun = repo.Begin("add-milk", "fingerprint", 1)
+	assert.False(t, begun)
+
+	d.Connection().Model(&Record{Id: record.Id}).UpdateColumn("updated_at", time.Now().Add(-2*abandonAfter))
+
+	_, begun = repo.Begin("add-milk", "fingerprint", 1)
+	assert.True(t, begun)
+}
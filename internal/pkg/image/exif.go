@@ -0,0 +1,138 @@
+package image
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+const (
+	orientationNormal     = 1
+	orientationTag        = 0x0112
+	jpegMarkerApp1        = 0xE1
+	jpegMarkerStartOfScan = 0xDA
+)
+
+// exifOrientation reads orientation tag of jpeg, phones store photos as sensor captured them and set this tag
+// instead of rotating pixels. Anything which cannot be parsed counts as normal orientation.
+func exifOrientation(data []byte) int {
+
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return orientationNormal
+	}
+
+	offset := 2
+
+	for offset+4 <= len(data) {
+
+		if data[offset] != 0xFF {
+			return orientationNormal
+		}
+
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+
+		if marker == jpegMarkerStartOfScan || length < 2 || offset+2+length > len(data) {
+			return orientationNormal
+		}
+
+		segment := data[offset+4 : offset+2+length]
+
+		if marker == jpegMarkerApp1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+
+		offset += 2 + length
+	}
+
+	return orientationNormal
+}
+
+func tiffOrientation(tiff []byte) int {
+
+	if len(tiff) < 8 {
+		return orientationNormal
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return orientationNormal
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+
+	if ifd+2 > len(tiff) {
+		return orientationNormal
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			o := int(order.Uint16(tiff[entry+8:]))
+			if o < 1 || o > 8 {
+				return orientationNormal
+			}
+			return o
+		}
+	}
+
+	return orientationNormal
+}
+
+// orient turns image upright according to exif orientation
+func orient(img image.Image, orientation int) image.Image {
+
+	if orientation <= orientationNormal || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -5,8 +5,6 @@ import (
 	"cloud.google.com/go/storage"
 	"context"
 	"fmt"
-	"image/jpeg"
-	"image/png"
 	"io"
 	"path"
 	"path/filepath"
@@ -33,7 +31,24 @@ type GcsSaver struct {
 
 func (gs *GcsSaver) SaveBase64(base64 string) (string, error) {
 
-	img, err := decodeFromBase64(base64)
+	err := isBase64ImageValidSize(base64)
+
+	if err != nil {
+		return "", err
+	}
+
+	r, mimeType, err := fromBase64(base64)
+
+	if err != nil {
+		return "", err
+	}
+
+	return gs.Save(r, mimeType)
+}
+
+func (gs *GcsSaver) Save(r io.Reader, mimeType string) (string, error) {
+
+	img, err := decode(r, mimeType)
 
 	if err != nil {
 		return "", err
@@ -41,22 +56,54 @@ func (gs *GcsSaver) SaveBase64(base64 string) (string, error) {
 
 	filename := generateFileName(img.mimeType)
 
-	err = gs.gcsBucketClient.uploadFile(img, filename)
+	files, err := render(img, filename)
 	if err != nil {
 		return "", err
 	}
 
+	for _, f := range files {
+		err = gs.gcsBucketClient.uploadFile(f)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	// generate full filepath
 
 	return path.Join(gs.gcsBucketClient.location, filename), nil
 }
 
+func (gs *GcsSaver) SaveFile(r io.Reader, ext, mimeType string) (string, error) {
+
+	filename := generateFileName(ext)
+
+	err := gs.gcsBucketClient.uploadStream(filename, mimeType, r)
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(gs.gcsBucketClient.location, filename), nil
+}
+
 func (gs *GcsSaver) GetImage(filePath string) (*bytes.Buffer, string, error) {
 	return gs.gcsBucketClient.getFile(filePath)
 }
 
+func (gs *GcsSaver) Exists(fileName string) (bool, error) {
+	return gs.gcsBucketClient.exists(fileName)
+}
+
+func (gs *GcsSaver) StoredSize(fileName string) (int64, error) {
+	return storedSize(fileName, gs.gcsBucketClient.size)
+}
+
 func (gs *GcsSaver) DeleteFile(fileName string) error {
-	return gs.gcsBucketClient.deleteFile(fileName)
+	return deleteStored(fileName, gs.gcsBucketClient.deleteFile)
+}
+
+// Ping checks that bucket exists and credentials may access it
+func (gs *GcsSaver) Ping(ctx context.Context) error {
+	return gs.gcsBucketClient.ping(ctx)
 }
 
 type GcsBucketClient struct {
@@ -66,6 +113,11 @@ type GcsBucketClient struct {
 	location   string
 }
 
+func (c *GcsBucketClient) ping(ctx context.Context) error {
+	_, err := c.cl.Bucket(c.bucketName).Attrs(ctx)
+	return err
+}
+
 func (c *GcsBucketClient) deleteFile(fileName string) error {
 	ctx := context.Background()
 
@@ -77,6 +129,30 @@ func (c *GcsBucketClient) deleteFile(fileName string) error {
 	return err
 }
 
+func (c *GcsBucketClient) exists(fileName string) (bool, error) {
+	_, exists, err := c.size(fileName)
+	return exists, err
+}
+
+func (c *GcsBucketClient) size(fileName string) (int64, bool, error) {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	attrs, err := c.cl.Bucket(c.bucketName).Object(path.Join(c.location, fileName)).Attrs(ctx)
+
+	if err == storage.ErrObjectNotExist {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return attrs.Size, true, nil
+}
+
 func (c *GcsBucketClient) getFile(fileName string) (*bytes.Buffer, string, error) {
 	ctx := context.Background()
 
@@ -101,29 +177,38 @@ func (c *GcsBucketClient) getFile(fileName string) (*bytes.Buffer, string, error
 	return buf, mime, nil
 }
 
-func (c *GcsBucketClient) uploadFile(img *Image, fileName string) error {
+func (c *GcsBucketClient) uploadFile(f file) error {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	wc := c.cl.Bucket(c.bucketName).Object(path.Join(c.location, f.name)).NewWriter(ctx)
+	wc.ContentType = f.mime
+
+	if _, err := wc.Write(f.data); err != nil {
+		return err
+	}
+
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("Writer.Close: %v", err)
+	}
+
+	return nil
+}
+
+func (c *GcsBucketClient) uploadStream(fileName, mimeType string, r io.Reader) error {
 	ctx := context.Background()
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
 	defer cancel()
 
 	wc := c.cl.Bucket(c.bucketName).Object(path.Join(c.location, fileName)).NewWriter(ctx)
+	wc.ContentType = mimeType
 
-	switch img.mimeType {
-	case "png":
-		err := png.Encode(wc, img.img)
-		if err != nil {
-			return err
-		}
-	case "jpeg":
-		err := jpeg.Encode(wc, img.img, &jpeg.Options{
-			Quality: 100,
-		})
-		if err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unsuported file type %s", img.mimeType)
+	if _, err := io.Copy(wc, r); err != nil {
+		// unfinished upload is discarded by cancelled context
+		return err
 	}
 
 	if err := wc.Close(); err != nil {
@@ -1,25 +1,66 @@
 package image
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
+	"github.com/chai2010/webp"
 	"github.com/google/uuid"
 	"image"
 	"image/jpeg"
 	"image/png"
+	"io"
+	"io/ioutil"
 	"strings"
 )
 
 type Image struct {
-	img      image.Image
-	mimeType string
+	img         image.Image
+	mimeType    string
+	orientation int
 }
 
 type Saver interface {
 	SaveBase64(base64 string) (string, error)
+	// Save decodes image while it is read from r, so upload is never held in memory as a whole
+	Save(r io.Reader, mimeType string) (string, error)
+	// SaveFile stores content of r as it is, e.g. document, under new name with extension ext
+	SaveFile(r io.Reader, ext, mimeType string) (string, error)
 	DeleteFile(fileName string) error
 	GetImage(filename string) (*bytes.Buffer, string, error)
+	// Exists tells whether file is stored without reading it
+	Exists(fileName string) (bool, error)
+	// StoredSize returns bytes taken by file and every variant rendered from it
+	StoredSize(fileName string) (int64, error)
+}
+
+// Pinger is implemented by savers which can tell whether their storage works without touching stored files
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// MaxSize is the biggest upload accepted, in bytes
+const MaxSize = 8 * 1024 * 1024 * 10
+
+// MaxPixels caps width×height of uploaded image, it is checked before image is decoded, as small file
+// could declare dimensions which would not fit into memory once decoded. 50 megapixels fit photos of phone cameras.
+const MaxPixels = 50 * 1000 * 1000
+
+// webpHeaderSize is enough to read dimensions of webp
+const webpHeaderSize = 32
+
+// exif segment is the first one in jpeg and it cannot be longer than 64KB
+const exifHeadSize = 64*1024 + 16
+
+// ParseError tells upload is not an image which can be processed
+type ParseError struct {
+	err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse image: %s", e.err.Error())
 }
 
 func generateFileName(mimeType string) string {
@@ -28,48 +69,149 @@ func generateFileName(mimeType string) string {
 	return fmt.Sprintf("%s.%s", fileName, mimeType)
 }
 
+// dataUrlPrefix starts every data url, e.g. data:image/png;base64,...
+const dataUrlPrefix = "data:"
+
+// ParseDataUrl splits base64 data url into its mime type and encoded data
+func ParseDataUrl(dataUrl string) (string, string, error) {
+
+	commaIndex := strings.Index(dataUrl, ",")
+
+	if !strings.HasPrefix(dataUrl, dataUrlPrefix) || commaIndex == -1 {
+		return "", "", fmt.Errorf("image should be data url, e.g. data:image/png;base64,...")
+	}
+
+	mediaType := dataUrl[len(dataUrlPrefix):commaIndex]
+
+	if !strings.HasSuffix(mediaType, ";base64") || mediaType == ";base64" {
+		return "", "", fmt.Errorf("image data url should be base64 encoded and declare its type")
+	}
+
+	return strings.TrimSuffix(mediaType, ";base64"), dataUrl[commaIndex+1:], nil
+}
+
+// fromBase64 returns reader of image data url and its mime type
+func fromBase64(b64 string) (io.Reader, string, error) {
+
+	imageType, base64Image, err := ParseDataUrl(b64)
+
+	if err != nil {
+		return nil, "", &ParseError{err}
+	}
+
+	return base64.NewDecoder(base64.StdEncoding, strings.NewReader(base64Image)), imageType, nil
+}
+
 func decodeFromBase64(b64 string) (*Image, error) {
-	var err error
 
-	commaIndex := strings.Index(b64, ",")
+	r, imageType, err := fromBase64(b64)
+
+	if err != nil {
+		return nil, err
+	}
 
-	imageType := strings.TrimSuffix(b64[5:commaIndex], ";base64")
-	base64Image := b64[commaIndex+1:]
+	return decode(r, imageType)
+}
 
-	imageDecoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(base64Image))
+func decode(r io.Reader, imageType string) (*Image, error) {
+	var err error
 
 	var img image.Image
 	var mimeType string
+	orientation := orientationNormal
 
 	switch imageType {
 	case "image/png":
-		img, err = png.Decode(imageDecoder)
+		r, err = checkDimensions(r, png.DecodeConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		img, err = png.Decode(r)
 		mimeType = "png"
 
 		if err != nil {
-			return nil, err
+			return nil, &ParseError{err}
 		}
 
 	case "image/jpeg":
-		img, err = jpeg.Decode(imageDecoder)
+		br := bufio.NewReaderSize(r, exifHeadSize)
+		// peeked bytes stay in buffer, so decoder gets them as well
+		head, _ := br.Peek(exifHeadSize)
+		orientation = exifOrientation(head)
+
+		r, err = checkDimensions(br, jpeg.DecodeConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		img, err = jpeg.Decode(r)
 		mimeType = "jpeg"
 
+		if err != nil {
+			return nil, &ParseError{err}
+		}
+
+	case "image/webp":
+		r, err = checkDimensions(r, webpDecodeConfig)
 		if err != nil {
 			return nil, err
 		}
-	}
 
+		img, err = webp.Decode(r)
+		// webp is stored as jpeg for clients which do not support it
+		mimeType = "jpeg"
+
+		if err != nil {
+			return nil, &ParseError{err}
+		}
+
+	default:
+		return nil, &ParseError{fmt.Errorf("unsuported file type %s", imageType)}
+	}
 
 	return &Image{
-		img:      img,
-		mimeType: mimeType,
+		img:         img,
+		mimeType:    mimeType,
+		orientation: orientation,
 	}, nil
 }
 
+// checkDimensions reads header of image and rejects image over MaxPixels, returned reader replays
+// the header followed by the rest of image
+func checkDimensions(r io.Reader, decodeConfig func(io.Reader) (image.Config, error)) (io.Reader, error) {
+
+	head := bytes.NewBuffer(nil)
+
+	config, err := decodeConfig(io.TeeReader(r, head))
+
+	if err != nil {
+		return nil, &ParseError{err}
+	}
+
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, &ParseError{fmt.Errorf("image is %dx%d pixels and limit is %d pixels", config.Width, config.Height, MaxPixels)}
+	}
+
+	return io.MultiReader(head, r), nil
+}
+
+// webpDecodeConfig reads whole header first, webp reads it with single Read which could return less
+func webpDecodeConfig(r io.Reader) (image.Config, error) {
+
+	header, err := ioutil.ReadAll(io.LimitReader(r, webpHeaderSize))
+
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	return webp.DecodeConfig(bytes.NewReader(header))
+}
+
 func isBase64ImageValidSize(base64 string) error {
 
 	imgSize := calcBase64OrigLength(base64)
-	imgMax := 8 * 1024 * 1024 * 10
+	imgMax := MaxSize
 
 	if imgSize > imgMax {
 		return fmt.Errorf("image size is %d and limit is %d", imgSize, imgMax)
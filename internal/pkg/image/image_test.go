@@ -1,8 +1,15 @@
 package image
 
 import (
+	"bytes"
+	"encoding/binary"
+	"github.com/chai2010/webp"
 	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
 	"image/png"
+	"io"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -72,4 +79,63 @@ func TestIsBase64ImageValidSize(t *testing.T){
 
 	assert.NoError(t, isBase64ImageValidSize(string(base64)))
 
-}
\ No newline at end of file
+}
+func TestParseDataUrl(t *testing.T) {
+
+	mimeType, data, err := ParseDataUrl("data:image/png;base64,iVBORw0KGgo=")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", mimeType)
+	assert.Equal(t, "iVBORw0KGgo=", data)
+
+	for _, malformed := range []string{"", "data", "iVBORw0KGgo=", "data:image/png;base64", "image/png;base64,iVBORw0KGgo=", "data:;base64,iVBORw0KGgo=", "data:image/png,iVBORw0KGgo="} {
+		_, _, err = ParseDataUrl(malformed)
+		assert.Error(t, err, malformed)
+
+		_, err = decodeFromBase64(malformed)
+		assert.IsType(t, &ParseError{}, err, malformed)
+	}
+}
+
+func TestDecodeRejectsTooManyPixels(t *testing.T) {
+
+	small := image.NewRGBA(image.Rect(0, 0, 8, 8))
+
+	pngData := bytes.NewBuffer(nil)
+	assert.NoError(t, png.Encode(pngData, small))
+
+	// IHDR data follows signature, length and type, its crc follows the data
+	declared := pngData.Bytes()
+	binary.BigEndian.PutUint32(declared[16:], 40000)
+	binary.BigEndian.PutUint32(declared[20:], 40000)
+	binary.BigEndian.PutUint32(declared[29:], crc32.ChecksumIEEE(declared[12:29]))
+
+	_, err := decode(bytes.NewReader(declared), "image/png")
+	assert.IsType(t, &ParseError{}, err)
+	assert.Contains(t, err.Error(), "40000x40000")
+
+	jpegData := bytes.NewBuffer(nil)
+	assert.NoError(t, jpeg.Encode(jpegData, small, nil))
+
+	declared = jpegData.Bytes()
+	sof := bytes.Index(declared, []byte{0xFF, 0xC0})
+	binary.BigEndian.PutUint16(declared[sof+5:], 40000)
+	binary.BigEndian.PutUint16(declared[sof+7:], 40000)
+
+	_, err = decode(bytes.NewReader(declared), "image/jpeg")
+	assert.IsType(t, &ParseError{}, err)
+	assert.Contains(t, err.Error(), "40000x40000")
+
+	// header is replayed, so image within limit is decoded whole
+	for mimeType, encode := range map[string]func(io.Writer, image.Image) error{
+		"image/png":  png.Encode,
+		"image/jpeg": func(w io.Writer, m image.Image) error { return jpeg.Encode(w, m, nil) },
+		"image/webp": func(w io.Writer, m image.Image) error { return webp.Encode(w, m, nil) },
+	} {
+		encoded := bytes.NewBuffer(nil)
+		assert.NoError(t, encode(encoded, small))
+
+		decoded, err := decode(encoded, mimeType)
+		assert.NoError(t, err, mimeType)
+		assert.Equal(t, small.Bounds(), decoded.img.Bounds(), mimeType)
+	}
+}
@@ -2,10 +2,10 @@ package image
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"image/jpeg"
-	"image/png"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -29,22 +29,70 @@ func (ls *LocalSaver) SaveBase64(base64 string) (string, error) {
 		return "", err
 	}
 
-	img, err := decodeFromBase64(base64)
+	r, mimeType, err := fromBase64(base64)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to parse image: %s", err.Error())
+		return "", err
+	}
+
+	return ls.Save(r, mimeType)
+}
+
+func (ls *LocalSaver) Save(r io.Reader, mimeType string) (string, error) {
+
+	img, err := decode(r, mimeType)
+
+	if err != nil {
+		return "", err
 	}
 
 	return ls.persist(*img)
 }
 
+func (ls *LocalSaver) SaveFile(r io.Reader, ext, mimeType string) (string, error) {
+
+	err := ls.ensureLocation()
+	if err != nil {
+		return "", err
+	}
+
+	fileName := ls.generateFileName(ext)
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(f, r)
+
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(fileName)
+		return "", err
+	}
+
+	return fileName, nil
+}
+
 func (ls *LocalSaver) GetImage(fileName string) (*bytes.Buffer, string, error) {
+
+	// name comes from url, anything but stored file name could escape location
+	if !ValidFileName(fileName) {
+		return nil, "", fmt.Errorf("invalid file name %s", fileName)
+	}
+
 	fullPath := path.Join(ls.location, fileName)
 
 	f, err := os.Open(fullPath)
 	if err != nil {
 		return nil, "", err
 	}
+	defer f.Close()
+
 	buf := bytes.NewBuffer(nil)
 	_, err = io.Copy(buf, f)
 
@@ -57,48 +105,108 @@ func (ls *LocalSaver) GetImage(fileName string) (*bytes.Buffer, string, error) {
 	return buf, mime, nil
 }
 
+func (ls *LocalSaver) Exists(fileName string) (bool, error) {
+
+	if !ValidFileName(fileName) {
+		return false, fmt.Errorf("invalid file name %s", fileName)
+	}
+
+	_, err := os.Stat(path.Join(ls.location, fileName))
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (ls *LocalSaver) StoredSize(fileName string) (int64, error) {
+
+	if !ValidFileName(fileName) {
+		return 0, fmt.Errorf("invalid file name %s", fileName)
+	}
+
+	return storedSize(fileName, func(name string) (int64, bool, error) {
+		info, err := os.Stat(path.Join(ls.location, name))
+
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+
+		if err != nil {
+			return 0, false, err
+		}
+
+		return info.Size(), true, nil
+	})
+}
+
 func (ls *LocalSaver) DeleteFile(fileName string) error {
-	fullPath := path.Join(ls.location, fileName)
 
-	return os.Remove(fullPath)
+	if !ValidFileName(fileName) {
+		return fmt.Errorf("invalid file name %s", fileName)
+	}
+
+	return deleteStored(fileName, func(name string) error {
+		return os.Remove(path.Join(ls.location, name))
+	})
 }
 
 func (ls *LocalSaver) generateFileName(mimeType string) string {
 	return path.Join(ls.location, generateFileName(mimeType))
 }
 
-func (ls *LocalSaver) persist(img Image) (string, error) {
+// Ping checks that location is writable
+func (ls *LocalSaver) Ping(context.Context) error {
+
+	err := ls.ensureLocation()
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(ls.location, ".ping-*")
+	if err != nil {
+		return err
+	}
+
+	err = f.Close()
+	removeErr := os.Remove(f.Name())
+
+	if err != nil {
+		return err
+	}
+
+	return removeErr
+}
+
+func (ls *LocalSaver) ensureLocation() error {
 
 	if _, err := os.Stat(ls.location); os.IsNotExist(err) {
-		err := os.Mkdir(ls.location, 0644)
-		if err != nil {
-			return "", err
-		}
+		return os.Mkdir(ls.location, 0644)
+	}
+
+	return nil
+}
+
+func (ls *LocalSaver) persist(img Image) (string, error) {
+
+	err := ls.ensureLocation()
+	if err != nil {
+		return "", err
 	}
 
 	fileName := ls.generateFileName(img.mimeType)
 
-	f, err := os.Create(fileName)
+	files, err := render(&img, path.Base(fileName))
 	if err != nil {
 		return "", err
 	}
-	defer f.Close()
 
-	switch img.mimeType {
-	case "png":
-		err = png.Encode(f, img.img)
-		if err != nil {
-			return "", err
-		}
-	case "jpeg":
-		err = jpeg.Encode(f, img.img, &jpeg.Options{
-			Quality: 100,
-		})
+	for _, f := range files {
+		err = ioutil.WriteFile(path.Join(ls.location, f.name), f.data, 0644)
 		if err != nil {
 			return "", err
 		}
-	default:
-		return "", fmt.Errorf("unsuported file type %s", img.mimeType)
 	}
 
 	return fileName, nil
@@ -0,0 +1,48 @@
+package image
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLocalSaver(t *testing.T) {
+
+	location := t.TempDir()
+	saver := NewLocalSaver(location)
+
+	base64, err := ioutil.ReadFile("./test-assets/1/base64.txt")
+	assert.NoError(t, err)
+
+	filePath, err := saver.SaveBase64(string(base64))
+	assert.NoError(t, err)
+
+	fileName := path.Base(filePath)
+
+	exists, err := saver.Exists(fileName)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	var expected int64
+	for _, name := range storedFiles(fileName) {
+		info, err := os.Stat(path.Join(location, name))
+		assert.NoError(t, err)
+		expected += info.Size()
+	}
+
+	size, err := saver.StoredSize(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, size)
+
+	assert.NoError(t, saver.DeleteFile(fileName))
+
+	exists, err = saver.Exists(fileName)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	size, err = saver.StoredSize(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+}
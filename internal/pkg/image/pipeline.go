@@ -0,0 +1,237 @@
+package image
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxSide caps the longest side of stored original
+	MaxSide = 2048
+
+	jpegQuality = 85
+	webpQuality = 80
+)
+
+// Variant is smaller copy of image stored next to the original
+type Variant struct {
+	Name    string
+	MaxSide int
+}
+
+var Variants = []Variant{
+	{Name: "thumb", MaxSide: 160},
+	{Name: "small", MaxSide: 480},
+	{Name: "medium", MaxSide: 1024},
+}
+
+// file is encoded image ready to be stored
+type file struct {
+	name string
+	mime string
+	data []byte
+}
+
+// render normalizes uploaded image and encodes original and every variant of it, the original goes first.
+// Every image is stored in fallback format of upload, png keeps transparency and the rest becomes jpeg,
+// and as webp which is served to clients accepting it.
+func render(img *Image, fileName string) ([]file, error) {
+
+	if img == nil || img.img == nil {
+		return nil, fmt.Errorf("unsuported file type")
+	}
+
+	base := strings.TrimSuffix(fileName, path.Ext(fileName))
+	// capping first keeps orientation cheap, it moves pixels one by one
+	upright := orient(scale(img.img, MaxSide), img.orientation)
+
+	sizes := append([]Variant{{MaxSide: MaxSide}}, Variants...)
+
+	var files []file
+
+	for _, size := range sizes {
+
+		scaled := scale(upright, size.MaxSide)
+
+		name := base
+		if size.Name != "" {
+			name = fmt.Sprintf("%s_%s", base, size.Name)
+		}
+
+		fallback, err := encode(scaled, img.mimeType)
+		if err != nil {
+			return nil, err
+		}
+
+		efficient, err := encode(scaled, "webp")
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files,
+			file{name: name + "." + img.mimeType, mime: "image/" + img.mimeType, data: fallback},
+			file{name: name + ".webp", mime: "image/webp", data: efficient},
+		)
+	}
+
+	return files, nil
+}
+
+// scale shrinks image so its longest side fits into maxSide, smaller images are kept as they are
+func scale(img image.Image, maxSide int) image.Image {
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+
+	if w >= h {
+		h = h * maxSide / w
+		w = maxSide
+	} else {
+		w = w * maxSide / h
+		h = maxSide
+	}
+
+	if w < 1 {
+		w = 1
+	}
+
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+
+	return dst
+}
+
+func encode(img image.Image, mimeType string) ([]byte, error) {
+
+	buf := bytes.NewBuffer(nil)
+
+	var err error
+
+	switch mimeType {
+	case "png":
+		err = png.Encode(buf, img)
+	case "jpeg":
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "webp":
+		err = webp.Encode(buf, img, &webp.Options{Quality: webpQuality})
+	default:
+		return nil, fmt.Errorf("unsuported file type %s", mimeType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fileNamePattern matches names of stored files, they are uuids with optional variant suffix and extension
+var fileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[a-z0-9]+$`)
+
+// ValidFileName tells name can belong to stored file, such name never points outside of storage
+func ValidFileName(name string) bool {
+	return fileNamePattern.MatchString(name)
+}
+
+// storedFiles returns names of all files stored for original fileName
+func storedFiles(fileName string) []string {
+
+	base := strings.TrimSuffix(fileName, path.Ext(fileName))
+	names := []string{fileName, base + ".webp"}
+
+	for _, v := range Variants {
+		names = append(names, fmt.Sprintf("%s_%s%s", base, v.Name, path.Ext(fileName)), fmt.Sprintf("%s_%s.webp", base, v.Name))
+	}
+
+	return names
+}
+
+// storedSize sums sizes of files stored for fileName, size tells whether file exists as images uploaded
+// before variants were introduced have the original only
+func storedSize(fileName string, size func(name string) (int64, bool, error)) (int64, error) {
+
+	var total int64
+
+	for _, name := range storedFiles(fileName) {
+		n, exists, err := size(name)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if exists {
+			total += n
+		}
+	}
+
+	return total, nil
+}
+
+// deleteStored deletes files stored for fileName, only error of the original is returned as images uploaded
+// before variants were introduced have the original only
+func deleteStored(fileName string, del func(name string) error) error {
+
+	var err error
+
+	for idx, name := range storedFiles(fileName) {
+		deleteErr := del(name)
+
+		if idx == 0 {
+			err = deleteErr
+		}
+	}
+
+	return err
+}
+
+// Candidates returns files which can serve fileName in size, the best first. Images uploaded before variants
+// were introduced have the original only, so it always closes the list.
+func Candidates(fileName, size string, acceptsWebp bool) ([]string, error) {
+
+	base := strings.TrimSuffix(fileName, path.Ext(fileName))
+
+	if size != "" {
+		known := false
+		for _, v := range Variants {
+			if v.Name == size {
+				known = true
+			}
+		}
+
+		if !known {
+			return nil, fmt.Errorf("unknown image size %s", size)
+		}
+
+		base = fmt.Sprintf("%s_%s", base, size)
+	}
+
+	var candidates []string
+
+	if acceptsWebp && path.Ext(fileName) != ".webp" {
+		candidates = append(candidates, base+".webp")
+	}
+
+	candidates = append(candidates, base+path.Ext(fileName))
+
+	if size != "" {
+		candidates = append(candidates, fileName)
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,109 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/chai2010/webp"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// withOrientation inserts exif segment with orientation tag right after jpeg start of image marker
+func withOrientation(jpegData []byte, orientation uint16) []byte {
+
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	entries := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(entries[0:], 1)
+	binary.LittleEndian.PutUint16(entries[2:], orientationTag)
+	binary.LittleEndian.PutUint16(entries[4:], 3)
+	binary.LittleEndian.PutUint32(entries[6:], 1)
+	binary.LittleEndian.PutUint16(entries[10:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), append(tiff, entries...)...)
+
+	segment := []byte{0xFF, jpegMarkerApp1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	result := append([]byte{}, jpegData[:2]...)
+	result = append(result, segment...)
+	return append(result, jpegData[2:]...)
+}
+
+func TestExifOrientation(t *testing.T) {
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, 4, 2)), nil))
+
+	assert.Equal(t, orientationNormal, exifOrientation(buf.Bytes()))
+	assert.Equal(t, 6, exifOrientation(withOrientation(buf.Bytes(), 6)))
+	assert.Equal(t, orientationNormal, exifOrientation([]byte("not an image")))
+}
+
+func TestOrient(t *testing.T) {
+
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+
+	// rotated clockwise, top left corner moves to top right one
+	rotated := orient(img, 6)
+	assert.Equal(t, image.Rect(0, 0, 2, 3), rotated.Bounds())
+	assert.Equal(t, color.NRGBA{R: 255, A: 255}, rotated.At(1, 0))
+
+	assert.Equal(t, img, orient(img, orientationNormal))
+}
+
+func TestRender(t *testing.T) {
+
+	img := &Image{
+		img:         image.NewRGBA(image.Rect(0, 0, 3000, 1000)),
+		mimeType:    "jpeg",
+		orientation: 6,
+	}
+
+	files, err := render(img, "abc.jpeg")
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+
+	assert.Equal(t, storedFiles("abc.jpeg"), names)
+
+	original, err := jpeg.Decode(bytes.NewReader(files[0].data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 682, MaxSide), original.Bounds())
+
+	thumb, err := webp.Decode(bytes.NewReader(files[3].data))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/webp", files[3].mime)
+	assert.Equal(t, image.Rect(0, 0, 53, 160), thumb.Bounds())
+}
+
+func TestCandidates(t *testing.T) {
+
+	candidates, err := Candidates("abc.jpeg", "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc.webp", "abc.jpeg"}, candidates)
+
+	candidates, err = Candidates("abc.png", "thumb", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc_thumb.png", "abc.png"}, candidates)
+
+	_, err = Candidates("abc.png", "huge", false)
+	assert.Error(t, err)
+}
+
+func TestValidFileName(t *testing.T) {
+
+	assert.True(t, ValidFileName("6bff1721-dbc2-457f-886b-ee62b25711b9.png"))
+	assert.True(t, ValidFileName("6bff1721-dbc2-457f-886b-ee62b25711b9_thumb.webp"))
+	assert.False(t, ValidFileName(".."))
+	assert.False(t, ValidFileName("../config.yml"))
+	assert.False(t, ValidFileName("..\\milk.png"))
+	assert.False(t, ValidFileName("milk"))
+}
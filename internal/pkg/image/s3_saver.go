@@ -0,0 +1,292 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+type S3Options struct {
+	Endpoint        string
+	Region          string
+	BucketName      string
+	AccessKeyId     string
+	SecretAccessKey string
+	UseSSL          bool
+	// PathStyle addresses bucket as endpoint/bucket instead of bucket.endpoint, MinIO and most self-hosted stores need it
+	PathStyle bool
+	// PresignExpiry enables presigned urls, images are fetched by clients from storage directly
+	PresignExpiry time.Duration
+}
+
+// URLSigner is implemented by savers which can hand out temporary urls, so images are not proxied through server
+type URLSigner interface {
+	// SignedURL returns empty url when signing is disabled
+	SignedURL(fileName string) (string, error)
+}
+
+func NewS3Saver(options S3Options, uploadPath string) (Saver, error) {
+
+	// credentials from config win, otherwise they are taken from environment or instance metadata like aws cli does
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.Static{Value: credentials.Value{
+			AccessKeyID:     options.AccessKeyId,
+			SecretAccessKey: options.SecretAccessKey,
+			SignerType:      credentials.SignatureV4,
+		}},
+		&credentials.EnvAWS{},
+		&credentials.FileAWSCredentials{},
+		&credentials.IAM{},
+	})
+
+	lookup := minio.BucketLookupAuto
+	if options.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       options.UseSSL,
+		Region:       options.Region,
+		BucketLookup: lookup,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create s3 client: %v", err)
+	}
+
+	return &S3Saver{
+		s3BucketClient: &S3BucketClient{
+			cl:         client,
+			bucketName: options.BucketName,
+			location:   uploadPath,
+		},
+		presignExpiry: options.PresignExpiry,
+	}, nil
+}
+
+type S3Saver struct {
+	s3BucketClient *S3BucketClient
+	presignExpiry  time.Duration
+}
+
+func (ss *S3Saver) SaveBase64(base64 string) (string, error) {
+
+	err := isBase64ImageValidSize(base64)
+
+	if err != nil {
+		return "", err
+	}
+
+	r, mimeType, err := fromBase64(base64)
+
+	if err != nil {
+		return "", err
+	}
+
+	return ss.Save(r, mimeType)
+}
+
+func (ss *S3Saver) Save(r io.Reader, mimeType string) (string, error) {
+
+	img, err := decode(r, mimeType)
+
+	if err != nil {
+		return "", err
+	}
+
+	filename := generateFileName(img.mimeType)
+
+	files, err := render(img, filename)
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range files {
+		err = ss.s3BucketClient.uploadFile(f)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return path.Join(ss.s3BucketClient.location, filename), nil
+}
+
+func (ss *S3Saver) SaveFile(r io.Reader, ext, mimeType string) (string, error) {
+
+	filename := generateFileName(ext)
+
+	err := ss.s3BucketClient.uploadStream(filename, mimeType, r)
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(ss.s3BucketClient.location, filename), nil
+}
+
+func (ss *S3Saver) GetImage(filePath string) (*bytes.Buffer, string, error) {
+	return ss.s3BucketClient.getFile(filePath)
+}
+
+func (ss *S3Saver) Exists(fileName string) (bool, error) {
+	return ss.s3BucketClient.exists(fileName)
+}
+
+func (ss *S3Saver) StoredSize(fileName string) (int64, error) {
+	return storedSize(fileName, ss.s3BucketClient.size)
+}
+
+func (ss *S3Saver) DeleteFile(fileName string) error {
+	return deleteStored(fileName, ss.s3BucketClient.deleteFile)
+}
+
+func (ss *S3Saver) SignedURL(fileName string) (string, error) {
+
+	if ss.presignExpiry == 0 {
+		return "", nil
+	}
+
+	return ss.s3BucketClient.presign(fileName, ss.presignExpiry)
+}
+
+// Ping checks that bucket exists and credentials may access it
+func (ss *S3Saver) Ping(ctx context.Context) error {
+	return ss.s3BucketClient.ping(ctx)
+}
+
+type S3BucketClient struct {
+	cl         *minio.Client
+	bucketName string
+	location   string
+}
+
+func (c *S3BucketClient) ping(ctx context.Context) error {
+
+	exists, err := c.cl.BucketExists(ctx, c.bucketName)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", c.bucketName)
+	}
+
+	return nil
+}
+
+func (c *S3BucketClient) deleteFile(fileName string) error {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	return c.cl.RemoveObject(ctx, c.bucketName, path.Join(c.location, fileName), minio.RemoveObjectOptions{})
+}
+
+func (c *S3BucketClient) exists(fileName string) (bool, error) {
+	_, exists, err := c.size(fileName)
+	return exists, err
+}
+
+func (c *S3BucketClient) size(fileName string) (int64, bool, error) {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	info, err := c.cl.StatObject(ctx, c.bucketName, path.Join(c.location, fileName), minio.StatObjectOptions{})
+
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return info.Size, true, nil
+}
+
+func (c *S3BucketClient) getFile(fileName string) (*bytes.Buffer, string, error) {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	r, err := c.cl.GetObject(ctx, c.bucketName, path.Join(c.location, fileName), minio.GetObjectOptions{})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer r.Close()
+
+	buf := bytes.NewBuffer(nil)
+	_, err = io.Copy(buf, r)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	mime := fmt.Sprintf("image/%s", filepath.Ext(fileName)[1:])
+
+	return buf, mime, nil
+}
+
+func (c *S3BucketClient) uploadFile(f file) error {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	_, err := c.cl.PutObject(ctx, c.bucketName, path.Join(c.location, f.name), bytes.NewReader(f.data), int64(len(f.data)), minio.PutObjectOptions{
+		ContentType: f.mime,
+	})
+
+	if err != nil {
+		return fmt.Errorf("cannot upload %s: %v", f.name, err)
+	}
+
+	return nil
+}
+
+func (c *S3BucketClient) uploadStream(fileName, mimeType string, r io.Reader) error {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	// size is not known upfront, client uploads in parts
+	_, err := c.cl.PutObject(ctx, c.bucketName, path.Join(c.location, fileName), r, -1, minio.PutObjectOptions{
+		ContentType: mimeType,
+	})
+
+	if err != nil {
+		return fmt.Errorf("cannot upload %s: %v", fileName, err)
+	}
+
+	return nil
+}
+
+func (c *S3BucketClient) presign(fileName string, expiry time.Duration) (string, error) {
+	ctx := context.Background()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+
+	// presigning does not look into bucket, caller makes sure object exists
+	u, err := c.cl.PresignedGetObject(ctx, c.bucketName, path.Join(c.location, fileName), expiry, url.Values{})
+
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
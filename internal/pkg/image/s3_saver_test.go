@@ -0,0 +1,83 @@
+package image
+
+import (
+	"context"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// runs against MinIO or another S3 compatible store, see make test/s3
+func TestS3Saver(t *testing.T) {
+
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+
+	saver, err := NewS3Saver(S3Options{
+		Endpoint:        endpoint,
+		Region:          "us-east-1",
+		BucketName:      "proviant-test",
+		AccessKeyId:     os.Getenv("S3_TEST_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_TEST_SECRET_ACCESS_KEY"),
+		PathStyle:       true,
+		PresignExpiry:   time.Minute,
+	}, "1/")
+	assert.NoError(t, err)
+
+	s3Saver := saver.(*S3Saver)
+	client := s3Saver.s3BucketClient.cl
+
+	exists, err := client.BucketExists(context.Background(), "proviant-test")
+	assert.NoError(t, err)
+
+	if !exists {
+		assert.NoError(t, client.MakeBucket(context.Background(), "proviant-test", minio.MakeBucketOptions{Region: "us-east-1"}))
+	}
+
+	base64, err := ioutil.ReadFile("./test-assets/1/base64.txt")
+	assert.NoError(t, err)
+
+	filePath, err := saver.SaveBase64(string(base64))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", path.Dir(filePath))
+
+	fileName := path.Base(filePath)
+
+	buf, mime, err := saver.GetImage(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", mime)
+	assert.Greater(t, buf.Len(), 0)
+
+	exists, err = saver.Exists(fileName)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	size, err := saver.StoredSize(fileName)
+	assert.NoError(t, err)
+	assert.Greater(t, size, int64(buf.Len()))
+
+	signedURL, err := s3Saver.SignedURL(fileName)
+	assert.NoError(t, err)
+
+	resp, err := http.Get(signedURL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	assert.NoError(t, saver.DeleteFile(fileName))
+
+	_, _, err = saver.GetImage(fileName)
+	assert.Error(t, err)
+
+	exists, err = saver.Exists(fileName)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,75 @@
+package image
+
+import (
+	"fmt"
+	"io"
+)
+
+// ErrTooLarge is returned by Upload once more than its limit was read
+var ErrTooLarge = fmt.Errorf("image is too large")
+
+// Upload is image streamed from client, it counts read bytes and stops reading over limit,
+// so oversized upload is rejected without being read completely
+type Upload struct {
+	r     io.Reader
+	Mime  string
+	limit int64
+	read  int64
+}
+
+func NewUpload(r io.Reader, mimeType string, limit int64) *Upload {
+	return &Upload{
+		r:     r,
+		Mime:  mimeType,
+		limit: limit,
+	}
+}
+
+func (u *Upload) Read(p []byte) (int, error) {
+
+	if u.read > u.limit {
+		return 0, ErrTooLarge
+	}
+
+	// one byte over limit is enough to tell upload is too large
+	if left := u.limit + 1 - u.read; int64(len(p)) > left {
+		p = p[:left]
+	}
+
+	n, err := u.r.Read(p)
+	u.read += int64(n)
+
+	if u.read > u.limit {
+		return n, ErrTooLarge
+	}
+
+	return n, err
+}
+
+// Size is number of bytes read so far
+func (u *Upload) Size() int64 {
+	return u.read
+}
+
+// Exceeded tells upload was stopped because of limit
+func (u *Upload) Exceeded() bool {
+	return u.read > u.limit
+}
+
+// NewBase64Upload reads image from data url, e.g. data:image/png;base64,...
+func NewBase64Upload(b64 string) (*Upload, error) {
+
+	err := isBase64ImageValidSize(b64)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, mimeType, err := fromBase64(b64)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUpload(r, mimeType, MaxSize), nil
+}
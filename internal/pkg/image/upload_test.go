@@ -0,0 +1,27 @@
+package image
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestUpload(t *testing.T) {
+
+	u := NewUpload(bytes.NewReader(make([]byte, 10)), "image/png", 10)
+	data, err := ioutil.ReadAll(u)
+
+	assert.Nil(t, err)
+	assert.Len(t, data, 10)
+	assert.Equal(t, int64(10), u.Size())
+	assert.False(t, u.Exceeded())
+
+	u = NewUpload(bytes.NewReader(make([]byte, 100)), "image/png", 10)
+	_, err = ioutil.ReadAll(u)
+
+	assert.Equal(t, ErrTooLarge, err)
+	assert.True(t, u.Exceeded())
+	// reading stops right after limit
+	assert.Equal(t, int64(11), u.Size())
+}
@@ -0,0 +1,195 @@
+package media
+
+import (
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"gorm.io/gorm"
+	"path"
+	"strings"
+	"time"
+)
+
+// UrlPrefix is path stored files are served under
+const UrlPrefix = "/uc/img/"
+
+// DefaultGrace is how long unreferenced media is kept, upload should be attached to product in the meantime
+const DefaultGrace = 24 * time.Hour
+
+// Media is stored file, Url is path it is served under. Refs counts products which refer to it,
+// media nobody refers to is removed by garbage collection.
+type Media struct {
+	gorm.Model
+	Id   int    `json:"id" gorm:"primaryKey;autoIncrement;"`
+	Url  string `json:"url" gorm:"size:255;index"`
+	Mime string `json:"mime"`
+	Size int64  `json:"size"`
+	// StoredSize is what upload takes in storage with its variants, 0 for media stored before it was recorded
+	StoredSize int64 `json:"stored_size"`
+	// Hash is sha256 of uploaded content, identical uploads of account share the same media. For images saved
+	// before media was tracked it is sha256 of stored content.
+	Hash      string `json:"hash" gorm:"size:64;index"`
+	Refs      int    `json:"refs" gorm:"default:0;index"`
+	AccountId int    `json:"account_id" gorm:"default:0;index"`
+}
+
+// Url returns path file is served under
+func Url(fileName string) string {
+	return UrlPrefix + path.Base(fileName)
+}
+
+// FileName returns name file is stored under, url can be empty
+func FileName(url string) string {
+	return strings.TrimPrefix(url, UrlPrefix)
+}
+
+func (Media) TableName() string {
+	return "media"
+}
+
+type DTO struct {
+	Id   int    `json:"id"`
+	Url  string `json:"url"`
+	Mime string `json:"mime"`
+	// Size is number of uploaded bytes
+	Size int64 `json:"size"`
+	// SignedUrl gives access to media without account till ExpiresAt, it is empty when signing is not configured
+	SignedUrl string `json:"signed_url"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type Repository struct {
+	db db.DB
+}
+
+func (r *Repository) Get(id int, accountId int) (Media, *errors.CustomError) {
+
+	model := &Media{}
+
+	r.db.Connection().First(model, "id = ? and account_id = ?", id, accountId)
+
+	if (*model).Id == 0 {
+		return Media{}, errors.NewErrNotFound(i18n.NewMessage("media with id %d not found", id))
+	}
+
+	return *model, nil
+}
+
+func (r *Repository) GetAll(accountId int) []Media {
+
+	var models []Media
+	r.db.Connection().Where("account_id = ?", accountId).Find(&models)
+
+	return models
+}
+
+// GetByUrl returns media served under url regardless of account, caller checks who may access it
+func (r *Repository) GetByUrl(url string) (Media, bool) {
+
+	model := &Media{}
+
+	r.db.Connection().Where("url = ?", url).Order("id").Limit(1).Find(model)
+
+	return *model, (*model).Id != 0
+}
+
+func (r *Repository) GetByHash(hash string, accountId int) (Media, bool) {
+
+	model := &Media{}
+
+	r.db.Connection().Where("hash = ? and account_id = ?", hash, accountId).Order("id").Limit(1).Find(model)
+
+	return *model, (*model).Id != 0
+}
+
+func (r *Repository) Create(m Media) Media {
+	r.db.Connection().Create(&m)
+	return m
+}
+
+// Touch starts grace period of media over
+func (r *Repository) Touch(id int) {
+	r.db.Connection().Model(&Media{}).Where("id = ?", id).Update("updated_at", time.Now())
+}
+
+// Acquire adds reference to media
+func (r *Repository) Acquire(id int, accountId int) {
+	r.db.Connection().Model(&Media{}).Where("id = ? and account_id = ?", id, accountId).
+		Update("refs", gorm.Expr("refs + 1"))
+}
+
+// Release removes reference to media, grace period of media without references starts over
+func (r *Repository) Release(id int, accountId int) {
+	r.db.Connection().Model(&Media{}).Where("id = ? and account_id = ? and refs > 0", id, accountId).
+		Update("refs", gorm.Expr("refs - 1"))
+}
+
+// GetOrphans returns media of all accounts which nobody refers to since before
+func (r *Repository) GetOrphans(before time.Time, limit int) []Media {
+
+	var models []Media
+	r.db.Connection().Where("refs = 0 and updated_at < ?", before).Order("id").Limit(limit).Find(&models)
+
+	return models
+}
+
+// DeleteOrphan deletes media unless it got referenced in the meantime
+func (r *Repository) DeleteOrphan(id int) bool {
+	return r.db.Connection().Where("id = ? and refs = 0", id).Unscoped().Delete(&Media{}).RowsAffected == 1
+}
+
+// TotalStoredSize returns bytes media of all accounts take in storage, size of upload stands in
+// for media whose stored size is not known
+func (r *Repository) TotalStoredSize() int64 {
+	var size int64
+	r.db.Connection().Model(&Media{}).Select("coalesce(sum(case when stored_size > 0 then stored_size else size end), 0)").Row().Scan(&size)
+	return size
+}
+
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Media{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Media{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+func ModelToDTO(m Media) DTO {
+	return DTO{
+		Id:   m.Id,
+		Url:  m.Url,
+		Mime: m.Mime,
+		Size: m.Size,
+	}
+}
+
+func (r *Repository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Media{})
+	if err != nil {
+		return fmt.Errorf("migration of Media table failed: %v", err)
+	}
+	return nil
+}
+
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
+func Setup(d db.DB) (*Repository, error) {
+
+	repo := &Repository{}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,14 @@
+package media
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUrl(t *testing.T) {
+
+	assert.Equal(t, "/uc/img/milk.png", Url("milk.png"))
+	assert.Equal(t, "/uc/img/milk.png", Url("/app/user_content/milk.png"))
+	assert.Equal(t, "milk.png", FileName(Url("/app/user_content/milk.png")))
+	assert.Equal(t, "", FileName(""))
+}
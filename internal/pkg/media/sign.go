@@ -0,0 +1,75 @@
+package media
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	ExpiresParam   = "expires"
+	SignatureParam = "signature"
+
+	// DefaultSignedUrlExpiry is how long signed url is valid when config does not tell otherwise
+	DefaultSignedUrlExpiry = time.Hour
+)
+
+// Signer issues urls which give access to media without account of owner, e.g. for img tags, for limited time
+type Signer struct {
+	key    []byte
+	expiry time.Duration
+}
+
+// NewSigner returns signer, urls are not signed while key is empty
+func NewSigner(key string, expiry time.Duration) *Signer {
+
+	if expiry <= 0 {
+		expiry = DefaultSignedUrlExpiry
+	}
+
+	return &Signer{
+		key:    []byte(key),
+		expiry: expiry,
+	}
+}
+
+func (s *Signer) Enabled() bool {
+	return len(s.key) > 0
+}
+
+// Sign returns url with expiry and signature, it is empty when signing is disabled
+func (s *Signer) Sign(url string, now time.Time) (string, time.Time) {
+
+	if !s.Enabled() {
+		return "", time.Time{}
+	}
+
+	expires := now.Add(s.expiry).Truncate(time.Second)
+
+	return fmt.Sprintf("%s?%s=%d&%s=%s", url, ExpiresParam, expires.Unix(), SignatureParam, s.signature(url, expires.Unix())), expires
+}
+
+// Verify tells signature is issued for url and it is not expired yet
+func (s *Signer) Verify(url, expires, signature string, now time.Time) bool {
+
+	if !s.Enabled() {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+
+	if err != nil || now.Unix() > expiresAt {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(s.signature(url, expiresAt)))
+}
+
+func (s *Signer) signature(url string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(fmt.Sprintf("%s\n%d", url, expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,33 @@
+package media
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+
+	now := time.Unix(1600000000, 0)
+	signer := NewSigner("secret", time.Minute)
+
+	signed, expiresAt := signer.Sign("/uc/img/milk.png", now)
+	assert.Equal(t, now.Add(time.Minute), expiresAt)
+
+	u, err := url.Parse(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, "/uc/img/milk.png", u.Path)
+
+	expires, signature := u.Query().Get(ExpiresParam), u.Query().Get(SignatureParam)
+
+	assert.True(t, signer.Verify("/uc/img/milk.png", expires, signature, now))
+	assert.False(t, signer.Verify("/uc/img/milk.png", expires, signature, now.Add(2*time.Minute)))
+	assert.False(t, signer.Verify("/uc/img/bread.png", expires, signature, now))
+	assert.False(t, NewSigner("other", time.Minute).Verify("/uc/img/milk.png", expires, signature, now))
+
+	disabled := NewSigner("", time.Minute)
+	signed, _ = disabled.Sign("/uc/img/milk.png", now)
+	assert.Empty(t, signed)
+	assert.False(t, disabled.Verify("/uc/img/milk.png", expires, signature, now))
+}
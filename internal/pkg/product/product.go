@@ -16,46 +16,59 @@ type Product struct {
 	Description string          `json:"description"`
 	Link        string          `json:"link"`
 	Image       string          `json:"image"`
+	MediaId     int             `json:"media_id" gorm:"default:0;index"`
 	Barcode     string          `json:"barcode"`
 	ListId      int             `json:"list_id"`
 	Stock       uint            `json:"stock" gorm:"type:UINT"`
 	Price       decimal.Decimal `json:"price" gorm:"type:decimal(20,2);"`
 	AccountId   int             `json:"account_id" gorm:"default:0;index"`
+	Version     int             `json:"version" gorm:"default:1;not null"`
 }
 
 type CreateDTO struct {
-	Title       string          `json:"title"`
+	Title       string          `json:"title" validate:"required,max=255"`
 	Description string          `json:"description"`
 	Link        string          `json:"link"`
 	Image       string          `json:"image"`
-	ImageBase64 string          `json:"image_base64"`
+	ImageBase64 string          `json:"image_base64" validate:"omitempty,data_url"`
+	MediaId     int             `json:"media_id" validate:"omitempty,media"`
 	Barcode     string          `json:"barcode"`
-	CategoryIds []int           `json:"category_ids"`
-	ListId      int             `json:"list_id"`
+	CategoryIds []int           `json:"category_ids" validate:"categories"`
+	ListId      int             `json:"list_id" validate:"list"`
 	Stock       uint            `json:"stock"`
-	Price       decimal.Decimal `json:"price"`
+	Price       decimal.Decimal `json:"price" validate:"nonnegative"`
 }
 
 type UpdateDTO struct {
 	Id          int             `json:"id"`
-	Title       string          `json:"title"`
+	Version     int             `json:"-"`
+	Title       string          `json:"title" validate:"required,max=255"`
 	Description string          `json:"description"`
 	Link        string          `json:"link"`
 	Image       string          `json:"image"`
-	ImageBase64 string          `json:"image_base64"`
+	ImageBase64 string          `json:"image_base64" validate:"omitempty,data_url"`
+	MediaId     int             `json:"media_id" validate:"omitempty,media"`
 	Barcode     string          `json:"barcode"`
-	CategoryIds []int           `json:"category_ids"`
-	ListId      int             `json:"list_id"`
+	CategoryIds []int           `json:"category_ids" validate:"categories"`
+	ListId      int             `json:"list_id" validate:"list"`
 	Stock       uint            `json:"stock"`
-	Price       decimal.Decimal `json:"price"`
+	Price       decimal.Decimal `json:"price" validate:"nonnegative"`
+}
+
+// GalleryDTO orders photos of product, the first one is primary
+type GalleryDTO struct {
+	MediaIds []int `json:"media_ids" validate:"media_list"`
 }
 
 type DTO struct {
 	Id          int             `json:"id"`
+	Version     int             `json:"-"`
 	Title       string          `json:"title"`
 	Description string          `json:"description"`
 	Link        string          `json:"link"`
 	Image       string          `json:"image"`
+	MediaId     int             `json:"media_id"`
+	MediaIds    []int           `json:"media_ids"`
 	Barcode     string          `json:"barcode"`
 	CategoryIds []int           `json:"category_ids"`
 	Categories  interface{}     `json:"categories"`
@@ -87,6 +100,22 @@ func (r *Repository) Get(id int, accountId int) (Product, *errors.CustomError) {
 	return *p, nil
 }
 
+func (r *Repository) GetByIds(ids []int, accountId int) []Product {
+
+	var products []Product
+	r.db.Connection().Where("id IN (?) and account_id = ?", ids, accountId).Find(&products)
+
+	return products
+}
+
+func (r *Repository) GetByListIds(ids []int, accountId int) []Product {
+
+	var products []Product
+	r.db.Connection().Where("list_id IN (?) and account_id = ?", ids, accountId).Find(&products)
+
+	return products
+}
+
 func (r *Repository) GetAll(query *Query, accountId int) []Product {
 
 	var products []Product
@@ -106,16 +135,47 @@ func (r *Repository) GetAll(query *Query, accountId int) []Product {
 	return products
 }
 
-func (r *Repository) Delete(id int, accountId int) *errors.CustomError {
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Product{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Product{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+// CountPerAccount returns number of products of every account
+func (r *Repository) CountPerAccount() map[int]int64 {
+	return db.CountPerAccount(r.db.Connection().Model(&Product{}))
+}
+
+// GetWithUntrackedImage returns products of all accounts whose image under prefix was saved before media was tracked
+func (r *Repository) GetWithUntrackedImage(prefix string, limit int) []Product {
+
+	var products []Product
+	r.db.Connection().Where("image like ? and media_id = 0", prefix+"%").Order("id").Limit(limit).Find(&products)
+
+	return products
+}
 
-	model, err := r.Get(id, accountId)
+// SetMediaId links product to media of its image, it is bookkeeping so version is kept
+func (r *Repository) SetMediaId(id, mediaId int, accountId int) {
+	r.db.Connection().Model(&Product{}).Where("id = ? and account_id = ?", id, accountId).UpdateColumn("media_id", mediaId)
+}
+
+func (r *Repository) Delete(id, version int, accountId int) *errors.CustomError {
+
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return err
 	}
 
-	//db.Unscoped().Delete(&order) to delete permanently
-	r.db.Connection().Unscoped().Delete(model, id)
+	if !db.DeleteVersioned(r.db.Connection(), &Product{}, id, accountId, version) {
+		return errors.NewErrPreconditionFailed(i18n.NewMessage("product with id %d was changed by someone else", id))
+	}
+
 	return nil
 }
 
@@ -126,11 +186,13 @@ func (r *Repository) Create(dto CreateDTO, accountId int) Product {
 		Description: dto.Description,
 		Link:        dto.Link,
 		Image:       dto.Image,
+		MediaId:     dto.MediaId,
 		Barcode:     dto.Barcode,
 		ListId:      dto.ListId,
 		Stock:       0,
 		AccountId:   accountId,
 		Price:       dto.Price,
+		Version:     1,
 	}
 
 	r.db.Connection().Create(p)
@@ -146,48 +208,53 @@ func (r *Repository) Save(model Product, accountId int) (Product, *errors.Custom
 		return Product{}, err
 	}
 
-	r.db.Connection().Model(&Product{Id: model.Id}).Updates(model)
+	r.db.Connection().Model(&Product{Id: model.Id}).Omit("Version").Updates(model)
 
 	if model.Stock == 0 {
 		r.db.Connection().Model(&Product{Id: model.Id}).Select("Stock").Updates(Product{Stock: 0})
 	}
 
-	return model, nil
+	r.db.Connection().Model(&Product{Id: model.Id}).UpdateColumn(db.VersionColumn, gorm.Expr(db.VersionColumn+" + 1"))
+
+	return r.Get(model.Id, accountId)
 }
 
 func (r *Repository) UpdateFromDTO(dto UpdateDTO, accountId int) (Product, *errors.CustomError) {
 
-	model, err := r.Get(dto.Id, accountId)
+	_, err := r.Get(dto.Id, accountId)
 
 	if err != nil {
 		return Product{}, err
 	}
 
-	model.Title = dto.Title
-	model.Description = dto.Description
-	model.Link = dto.Link
-	model.Image = dto.Image
-	model.Barcode = dto.Barcode
-	model.ListId = dto.ListId
-	model.Stock = dto.Stock
-	model.Price = dto.Price
-
-	r.db.Connection().Model(&Product{Id: dto.Id}).Updates(model)
-
-	if model.Stock == 0 {
-		r.db.Connection().Model(&Product{Id: dto.Id}).Select("Stock").Updates(Product{Stock: 0})
+	updated := db.UpdateVersioned(r.db.Connection(), &Product{}, dto.Id, accountId, dto.Version, map[string]interface{}{
+		"title":       dto.Title,
+		"description": dto.Description,
+		"link":        dto.Link,
+		"image":       dto.Image,
+		"media_id":    dto.MediaId,
+		"barcode":     dto.Barcode,
+		"list_id":     dto.ListId,
+		"stock":       dto.Stock,
+		"price":       dto.Price,
+	})
+
+	if !updated {
+		return Product{}, errors.NewErrPreconditionFailed(i18n.NewMessage("product with id %d was changed by someone else", dto.Id))
 	}
 
-	return model, nil
+	return r.Get(dto.Id, accountId)
 }
 
 func ModelToDTO(m Product) DTO {
 	return DTO{
 		Id:          m.Id,
+		Version:     m.Version,
 		Title:       m.Title,
 		Description: m.Description,
 		Link:        m.Link,
 		Image:       m.Image,
+		MediaId:     m.MediaId,
 		Barcode:     m.Barcode,
 		ListId:      m.ListId,
 		Stock:       m.Stock,
@@ -197,13 +264,18 @@ func ModelToDTO(m Product) DTO {
 
 func (r *Repository) Migrate() error {
 	// Migrate the schema
-	err := r.db.Connection().AutoMigrate(&Product{})
+	err := db.AutoMigrate(r.db, &Product{})
 	if err != nil {
 		return fmt.Errorf("migration of Product table failed: %v", err)
 	}
 	return nil
 }
 
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
 func Setup(d db.DB) (*Repository, error) {
 
 	repo := &Repository{}
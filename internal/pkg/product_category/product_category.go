@@ -26,6 +26,33 @@ func (r *Repository) GetByProductId(id int, accountId int) []ProductCategory {
 	return models
 }
 
+func (r *Repository) GetByProductIds(ids []int, accountId int) []ProductCategory {
+
+	var models []ProductCategory
+
+	r.db.Connection().Where("product_id IN (?) and account_id = ?", ids, accountId).Find(&models)
+
+	return models
+}
+
+func (r *Repository) GetByCategoryId(id int, accountId int) []ProductCategory {
+
+	var models []ProductCategory
+
+	r.db.Connection().Where("category_id = ? and account_id = ?", id, accountId).Find(&models)
+
+	return models
+}
+
+func (r *Repository) GetAll(accountId int) []ProductCategory {
+
+	var models []ProductCategory
+
+	r.db.Connection().Where("account_id = ?", accountId).Find(&models)
+
+	return models
+}
+
 func (r *Repository) DeleteByProductId(id int, accountId int) {
 	r.db.Connection().Where("product_id = ? and account_id = ?", id, accountId).Unscoped().Delete(&ProductCategory{})
 }
@@ -34,6 +61,16 @@ func (r *Repository) DeleteByCategory(id int, accountId int) {
 	r.db.Connection().Where("category_id = ? and account_id = ?", id, accountId).Unscoped().Delete(&ProductCategory{})
 }
 
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&ProductCategory{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&ProductCategory{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
 func (r *Repository) Link(productId int, categories []int, accountId int) {
 
 	r.DeleteByProductId(productId, accountId)
@@ -45,13 +82,18 @@ func (r *Repository) Link(productId int, categories []int, accountId int) {
 
 func (r *Repository) Migrate() error {
 	// Migrate the schema
-	err := r.db.Connection().AutoMigrate(&ProductCategory{})
+	err := db.AutoMigrate(r.db, &ProductCategory{})
 	if err != nil {
 		return fmt.Errorf("migration of Product table failed: %v", err)
 	}
 	return nil
 }
 
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
 func Setup(d db.DB) (*Repository, error) {
 
 	repo := &Repository{}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"encoding/base64"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/pkg/attachment"
+	"github.com/proviant-io/core/internal/pkg/audit"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/consumption"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/media"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/product_category"
+	"github.com/proviant-io/core/internal/pkg/shopping"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/proviant-io/core/internal/pkg/webhook"
+	"time"
+)
+
+type ProductCategoryDTO struct {
+	ProductId  int `json:"product_id"`
+	CategoryId int `json:"category_id"`
+}
+
+type ImageExport struct {
+	Path   string `json:"path"`
+	Mime   string `json:"mime"`
+	Base64 string `json:"base64"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AttachmentExport is document of product with its content, its type is the one sniffed on upload
+type AttachmentExport struct {
+	attachment.DTO
+	Base64 string `json:"base64"`
+	Error  string `json:"error,omitempty"`
+}
+
+type AccountExport struct {
+	AccountId         int                       `json:"account_id"`
+	ExportedAt        int64                     `json:"exported_at"`
+	Products          []product.DTO             `json:"products"`
+	Stock             []stock.DTO               `json:"stock"`
+	Categories        []category.DTO            `json:"categories"`
+	Lists             []list.DTO                `json:"lists"`
+	ProductCategories []ProductCategoryDTO      `json:"product_categories"`
+	ConsumptionLog    []consumption.DTO         `json:"consumption_log"`
+	ShoppingLists     []shopping.ListDTO        `json:"shopping_lists"`
+	ShoppingListItems []shopping.ItemDTO        `json:"shopping_list_items"`
+	Audit             []audit.DTO               `json:"audit"`
+	Webhooks          []webhook.SubscriptionDTO `json:"webhooks"`
+	Attachments       []AttachmentExport        `json:"attachments"`
+	Images            []ImageExport             `json:"images"`
+}
+
+type TableErasure struct {
+	Table     string `json:"table"`
+	Before    int64  `json:"before"`
+	Remaining int64  `json:"remaining"`
+}
+
+type ImageErasure struct {
+	Total     int      `json:"total"`
+	Deleted   int      `json:"deleted"`
+	Remaining []string `json:"remaining"`
+}
+
+type ErasureReport struct {
+	AccountId int            `json:"account_id"`
+	ErasedAt  int64          `json:"erased_at"`
+	Tables    []TableErasure `json:"tables"`
+	Images    ImageErasure   `json:"images"`
+	Verified  bool           `json:"verified"`
+}
+
+// accountTable is a single storage which keeps data scoped by account_id
+type accountTable struct {
+	name   string
+	count  func(accountId int) int64
+	delete func(accountId int)
+}
+
+type AccountService struct {
+	productRepository         *product.Repository
+	listRepository            *list.Repository
+	categoryRepository        *category.Repository
+	stockRepository           *stock.Repository
+	productCategoryRepository *product_category.Repository
+	di                        *di.DI
+}
+
+func (s *AccountService) tables() []accountTable {
+	return []accountTable{
+		{"product_categories", s.productCategoryRepository.CountByAccountId, s.productCategoryRepository.DeleteByAccountId},
+		{"stocks", s.stockRepository.CountByAccountId, s.stockRepository.DeleteByAccountId},
+		{"consumption_logs", s.di.ConsumptionLog.CountByAccountId, s.di.ConsumptionLog.DeleteByAccountId},
+		{"shopping_list_items", s.di.ShoppingListItem.CountByAccountId, s.di.ShoppingListItem.DeleteByAccountId},
+		{"shopping_list_events", s.di.ShoppingListEvent.CountByAccountId, s.di.ShoppingListEvent.DeleteByAccountId},
+		{"shopping_lists", s.di.ShoppingList.CountByAccountId, s.di.ShoppingList.DeleteByAccountId},
+		{"gallery_items", s.di.Gallery.CountByAccountId, s.di.Gallery.DeleteByAccountId},
+		{"attachments", s.di.Attachment.CountByAccountId, s.di.Attachment.DeleteByAccountId},
+		{"products", s.productRepository.CountByAccountId, s.productRepository.DeleteByAccountId},
+		{"media", s.di.Media.CountByAccountId, s.di.Media.DeleteByAccountId},
+		{"categories", s.categoryRepository.CountByAccountId, s.categoryRepository.DeleteByAccountId},
+		{"lists", s.listRepository.CountByAccountId, s.listRepository.DeleteByAccountId},
+		{"audit_entries", s.di.Audit.CountByAccountId, s.di.Audit.DeleteByAccountId},
+		{"webhook_deliveries", s.di.Webhook.Deliveries().CountByAccountId, s.di.Webhook.Deliveries().DeleteByAccountId},
+		{"webhook_subscriptions", s.di.Webhook.Subscriptions().CountByAccountId, s.di.Webhook.Subscriptions().DeleteByAccountId},
+		{"idempotency_keys", s.di.Idempotency.CountByAccountId, s.di.Idempotency.DeleteByAccountId},
+	}
+}
+
+func (s *AccountService) images(accountId int) []string {
+
+	var images []string
+	seen := map[string]bool{}
+
+	for _, p := range s.productRepository.GetAll(nil, accountId) {
+		if p.Image != "" && !seen[p.Image] {
+			seen[p.Image] = true
+			images = append(images, p.Image)
+		}
+	}
+
+	// uploaded media belongs to account even when no product refers to it
+	for _, m := range s.di.Media.GetAll(accountId) {
+		if !seen[m.Url] {
+			seen[m.Url] = true
+			images = append(images, m.Url)
+		}
+	}
+
+	return images
+}
+
+// files returns every user content file of account, images and attachments
+func (s *AccountService) files(accountId int) []string {
+
+	files := s.images(accountId)
+
+	for _, a := range s.di.Attachment.GetAll(accountId) {
+		files = append(files, a.File)
+	}
+
+	return files
+}
+
+func (s *AccountService) Export(accountId int) AccountExport {
+
+	export := AccountExport{
+		AccountId:         accountId,
+		ExportedAt:        time.Now().Unix(),
+		Products:          []product.DTO{},
+		Stock:             []stock.DTO{},
+		Categories:        []category.DTO{},
+		Lists:             []list.DTO{},
+		ProductCategories: []ProductCategoryDTO{},
+		ConsumptionLog:    []consumption.DTO{},
+		ShoppingLists:     []shopping.ListDTO{},
+		ShoppingListItems: []shopping.ItemDTO{},
+		Audit:             []audit.DTO{},
+		Webhooks:          []webhook.SubscriptionDTO{},
+		Attachments:       []AttachmentExport{},
+		Images:            []ImageExport{},
+	}
+
+	for _, m := range s.productRepository.GetAll(nil, accountId) {
+		dto := product.ModelToDTO(m)
+		dto.MediaIds = s.di.Gallery.GetMediaIds(m.Id, accountId)
+		export.Products = append(export.Products, dto)
+	}
+
+	for _, m := range s.stockRepository.GetAll(accountId) {
+		export.Stock = append(export.Stock, stock.ModelToDTO(m))
+	}
+
+	for _, m := range s.categoryRepository.GetAll(accountId) {
+		export.Categories = append(export.Categories, category.ModelToDTO(m))
+	}
+
+	for _, m := range s.listRepository.GetAll(accountId) {
+		export.Lists = append(export.Lists, list.ModelToDTO(m))
+	}
+
+	for _, m := range s.productCategoryRepository.GetAll(accountId) {
+		export.ProductCategories = append(export.ProductCategories, ProductCategoryDTO{
+			ProductId:  m.ProductId,
+			CategoryId: m.CategoryId,
+		})
+	}
+
+	for _, m := range s.di.ConsumptionLog.GetAll(accountId) {
+		export.ConsumptionLog = append(export.ConsumptionLog, consumption.ModelToDTO(m))
+	}
+
+	for _, m := range s.di.ShoppingList.GetAll(accountId) {
+		export.ShoppingLists = append(export.ShoppingLists, shopping.ListToDTO(m))
+	}
+
+	for _, m := range s.di.ShoppingListItem.GetAll(accountId) {
+		export.ShoppingListItems = append(export.ShoppingListItems, shopping.ItemToDTO(m))
+	}
+
+	for _, m := range s.di.Audit.GetAllByAccountId(accountId) {
+		export.Audit = append(export.Audit, audit.ModelToDTO(m))
+	}
+
+	for _, m := range s.di.Webhook.Subscriptions().GetAll(accountId) {
+		export.Webhooks = append(export.Webhooks, webhook.SubscriptionToDTO(m))
+	}
+
+	for _, m := range s.di.Attachment.GetAll(accountId) {
+		file := AttachmentExport{DTO: attachment.ModelToDTO(m)}
+
+		// saver makes type up from extension, the sniffed one is kept by attachment
+		buf, _, err := s.di.ImageSaver.GetImage(m.File)
+
+		if err != nil {
+			file.Error = err.Error()
+		} else {
+			file.Base64 = base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+
+		export.Attachments = append(export.Attachments, file)
+	}
+
+	for _, imagePath := range s.images(accountId) {
+		img := ImageExport{Path: imagePath}
+
+		buf, mime, err := s.di.ImageSaver.GetImage(media.FileName(imagePath))
+
+		if err != nil {
+			img.Error = err.Error()
+		} else {
+			img.Mime = mime
+			img.Base64 = base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+
+		export.Images = append(export.Images, img)
+	}
+
+	return export
+}
+
+// Erase removes every row and user content file which belongs to account and verifies nothing is left behind
+func (s *AccountService) Erase(accountId int) ErasureReport {
+
+	report := ErasureReport{
+		AccountId: accountId,
+		ErasedAt:  time.Now().Unix(),
+		Tables:    []TableErasure{},
+		Images: ImageErasure{
+			Remaining: []string{},
+		},
+		Verified: true,
+	}
+
+	// images are referenced from products, so collect them before rows are gone. Attachments are
+	// stored next to images, so they are erased the same way.
+	files := s.files(accountId)
+	report.Images.Total = len(files)
+
+	for _, imagePath := range files {
+		fileName := media.FileName(imagePath)
+
+		err := s.di.ImageSaver.DeleteFile(fileName)
+		if err != nil {
+			s.di.Logger.Warn("cannot delete image of erased account", "account_id", accountId, "file", fileName, "error", err)
+		}
+
+		if _, _, err := s.di.ImageSaver.GetImage(fileName); err == nil {
+			report.Images.Remaining = append(report.Images.Remaining, imagePath)
+			report.Verified = false
+			continue
+		}
+
+		report.Images.Deleted++
+	}
+
+	for _, table := range s.tables() {
+		before := table.count(accountId)
+
+		table.delete(accountId)
+
+		remaining := table.count(accountId)
+
+		if remaining != 0 {
+			report.Verified = false
+		}
+
+		report.Tables = append(report.Tables, TableErasure{
+			Table:     table.name,
+			Before:    before,
+			Remaining: remaining,
+		})
+	}
+
+	return report
+}
+
+func NewAccountService(productRepository *product.Repository,
+	listRepository *list.Repository,
+	categoryRepository *category.Repository,
+	stockRepository *stock.Repository,
+	productCategoryRepository *product_category.Repository,
+	i *di.DI,
+) *AccountService {
+	return &AccountService{
+		productRepository:         productRepository,
+		listRepository:            listRepository,
+		categoryRepository:        categoryRepository,
+		stockRepository:           stockRepository,
+		productCategoryRepository: productCategoryRepository,
+		di:                        i,
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"encoding/base64"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestExportAttachments(t *testing.T) {
+
+	s := newTestService(t)
+	accounts := NewAccountService(s.productRepository, s.listRepository, s.categoryRepository, s.stockRepository, s.productCategoryRepository, s.di)
+
+	fridge := s.CreateList(list.DTO{Title: "Fridge"}, 1, 1)
+
+	milk, err := s.CreateProduct(product.CreateDTO{Title: "Milk", ListId: fridge.Id}, 1, 1)
+	assert.Nil(t, err)
+
+	content := "%PDF-1.4 warranty"
+
+	_, err = s.AddAttachment(milk.Id, "warranty.pdf", strings.NewReader(content), 1)
+	assert.Nil(t, err)
+
+	export := accounts.Export(1)
+
+	assert.Empty(t, export.Images)
+	assert.Len(t, export.Attachments, 1)
+	assert.Equal(t, "warranty.pdf", export.Attachments[0].Name)
+	assert.Equal(t, "application/pdf", export.Attachments[0].Mime)
+	assert.Empty(t, export.Attachments[0].Error)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(content)), export.Attachments[0].Base64)
+
+	report := accounts.Erase(1)
+
+	assert.True(t, report.Verified)
+	assert.Equal(t, 1, report.Images.Deleted)
+}
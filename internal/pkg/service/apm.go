@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/pkg/webhook"
+	"time"
+)
+
+// Stats samples storage for apm vendors which collect metrics. Lots are expiring within default threshold
+// of stock.expiring webhook, already expired ones included. Storage holds images with their variants and attachments.
+func (s *RelationService) Stats() apm.Stats {
+
+	until := time.Now().Add(time.Duration(webhook.DefaultExpiryThresholdDays) * 24 * time.Hour).Unix()
+
+	return apm.Stats{
+		ProductsPerAccount:     s.productRepository.CountPerAccount(),
+		ExpiringLotsPerAccount: s.stockRepository.CountExpiringPerAccount(until),
+		StorageBytes:           s.di.Media.TotalStoredSize() + s.di.Attachment.TotalSize(),
+	}
+}
+
+// count reports domain event to apm vendors which collect metrics, once it is committed
+func (s *RelationService) count(metric string, value float64) {
+	s.di.Counts.Count(metric, value)
+}
+
+// WithContext returns service which works for request of ctx, so its spans belong to trace of request
+// and its log records carry id of request
+func (s *RelationService) WithContext(ctx context.Context) *RelationService {
+
+	service := *s
+	service.ctx = ctx
+
+	return &service
+}
+
+// trace starts span of method when apm vendor traces, returned service runs queries and storage calls inside of it
+func (s *RelationService) trace(method string) (*RelationService, func()) {
+
+	tracer, ok := s.di.Apm.(apm.Tracer)
+
+	if !ok || s.ctx == nil {
+		return s, func() {}
+	}
+
+	ctx, end := tracer.Start(s.ctx, "RelationService."+method)
+
+	d := db.WithContext(s.di.DB, ctx)
+
+	service := *s
+	service.ctx = ctx
+	service.productRepository = s.productRepository.WithDB(d)
+	service.listRepository = s.listRepository.WithDB(d)
+	service.categoryRepository = s.categoryRepository.WithDB(d)
+	service.stockRepository = s.stockRepository.WithDB(d)
+	service.productCategoryRepository = s.productCategoryRepository.WithDB(d)
+	service.di = s.di.WithContext(ctx)
+
+	return &service, end
+}
+
+// log returns logger of request service works for
+func (s *RelationService) log() *logger.Logger {
+
+	if s.ctx == nil {
+		return s.di.Logger
+	}
+
+	return logger.FromContext(s.ctx)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/attachment"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"io"
+	"path"
+)
+
+// AddAttachment streams document of product to storage. Type of document is sniffed from its content,
+// name and type declared by client are not trusted.
+func (s *RelationService) AddAttachment(productId int, name string, r io.Reader, accountId int) (attachment.DTO, *errors.CustomError) {
+
+	s, end := s.trace("AddAttachment")
+	defer end()
+
+	_, err := s.productRepository.Get(productId, accountId)
+
+	if err != nil {
+		return attachment.DTO{}, err
+	}
+
+	upload := image.NewUpload(r, "", attachment.MaxSize)
+	br := bufio.NewReaderSize(upload, attachment.SniffLength)
+
+	// peeked bytes stay in buffer, so they are stored as well
+	head, _ := br.Peek(attachment.SniffLength)
+
+	mimeType, ext := attachment.Sniff(head)
+
+	if ext == "" {
+		return attachment.DTO{}, errors.NewErrUnsupportedMediaType(i18n.NewMessage("attachment of type %s is not supported", mimeType))
+	}
+
+	filePath, pureErr := s.di.ImageSaver.SaveFile(br, ext, mimeType)
+
+	if upload.Exceeded() {
+		return attachment.DTO{}, errors.NewErrPayloadTooLarge(i18n.NewMessage("attachment should not be bigger than %d bytes", attachment.MaxSize))
+	}
+
+	if pureErr != nil {
+		return attachment.DTO{}, errors.NewInternalServer(i18n.NewMessage(pureErr.Error()))
+	}
+
+	name = attachment.CleanName(name)
+
+	if name == "" {
+		name = path.Base(filePath)
+	}
+
+	model := s.di.Attachment.Create(attachment.Attachment{
+		ProductId: productId,
+		Name:      name,
+		Mime:      mimeType,
+		Size:      upload.Size(),
+		File:      path.Base(filePath),
+		AccountId: accountId,
+	})
+
+	return attachment.ModelToDTO(model), nil
+}
+
+func (s *RelationService) GetAttachments(productId int, accountId int) ([]attachment.DTO, *errors.CustomError) {
+
+	s, end := s.trace("GetAttachments")
+	defer end()
+
+	_, err := s.productRepository.Get(productId, accountId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := []attachment.DTO{}
+
+	for _, model := range s.di.Attachment.GetByProductId(productId, accountId) {
+		dtos = append(dtos, attachment.ModelToDTO(model))
+	}
+
+	return dtos, nil
+}
+
+// OpenAttachment returns document of product with its content
+func (s *RelationService) OpenAttachment(productId, id int, accountId int) (attachment.Attachment, *bytes.Buffer, *errors.CustomError) {
+
+	s, end := s.trace("OpenAttachment")
+	defer end()
+
+	model, err := s.di.Attachment.Get(id, productId, accountId)
+
+	if err != nil {
+		return attachment.Attachment{}, nil, err
+	}
+
+	buf, _, pureErr := s.di.ImageSaver.GetImage(model.File)
+
+	if pureErr != nil {
+		return attachment.Attachment{}, nil, errors.NewInternalServer(i18n.NewMessage("Cannot fetch file, : %s", pureErr.Error()))
+	}
+
+	return model, buf, nil
+}
+
+func (s *RelationService) DeleteAttachment(productId, id int, accountId int) *errors.CustomError {
+
+	s, end := s.trace("DeleteAttachment")
+	defer end()
+
+	model, err := s.di.Attachment.Get(id, productId, accountId)
+
+	if err != nil {
+		return err
+	}
+
+	s.di.Attachment.Delete(id, accountId)
+	s.deleteFile(model.File)
+
+	return nil
+}
+
+func (s *RelationService) deleteAttachments(productId int, accountId int) {
+
+	for _, model := range s.di.Attachment.GetByProductId(productId, accountId) {
+		s.deleteFile(model.File)
+	}
+
+	s.di.Attachment.DeleteByProductId(productId, accountId)
+}
+
+func (s *RelationService) deleteFile(fileName string) {
+	err := s.di.ImageSaver.DeleteFile(fileName)
+	if err != nil {
+		s.log().Warn("cannot delete attachment file", "file", fileName, "error", err)
+	}
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/audit"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"github.com/proviant-io/core/internal/pkg/media"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/webhook"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// mediaCollectInterval is how often media nobody refers to is looked for
+const mediaCollectInterval = time.Hour
+
+const mediaCollectBatch = 100
+
+// MediaCollection is result of media garbage collection
+type MediaCollection struct {
+	// Adopted is number of product images saved before media was tracked which got registered
+	Adopted int `json:"adopted"`
+	Deleted int `json:"deleted"`
+	// Failed is number of files which could not be deleted from storage
+	Failed int `json:"failed"`
+}
+
+// SaveMedia streams uploaded image to storage, upload identical to already stored one of account gets existing media
+func (s *RelationService) SaveMedia(upload *image.Upload, accountId int) (media.DTO, error) {
+
+	s, end := s.trace("SaveMedia")
+	defer end()
+
+	m, err := s.saveMedia(upload, accountId)
+
+	if err != nil {
+		return media.DTO{}, err
+	}
+
+	return media.ModelToDTO(m), nil
+}
+
+func (s *RelationService) saveBase64(b64 string, accountId int) (media.Media, error) {
+
+	upload, err := image.NewBase64Upload(b64)
+
+	if err != nil {
+		return media.Media{}, err
+	}
+
+	return s.saveMedia(upload, accountId)
+}
+
+// imageError tells client about image it sent which cannot be processed, the rest is server fault
+func imageError(err error) *errors.CustomError {
+
+	if _, ok := err.(*image.ParseError); ok {
+		return errors.NewErrBadRequest(i18n.NewMessage(err.Error()))
+	}
+
+	return errors.NewInternalServer(i18n.NewMessage(err.Error()))
+}
+
+func (s *RelationService) saveMedia(upload *image.Upload, accountId int) (media.Media, error) {
+
+	hash := sha256.New()
+
+	imgPath, err := s.di.ImageSaver.Save(io.TeeReader(upload, hash), upload.Mime)
+
+	if err != nil {
+		return media.Media{}, err
+	}
+
+	fileName := path.Base(imgPath)
+
+	// decoders stop at the end of image, the rest is hashed as well so identical uploads get identical hash
+	_, err = io.Copy(hash, upload)
+
+	if err != nil {
+		s.deleteImage(fileName)
+		return media.Media{}, err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	// content is known only once it is stored, so the copy is removed afterwards
+	if existing, ok := s.di.Media.GetByHash(sum, accountId); ok {
+		s.deleteImage(fileName)
+		// upload of unreferenced media gets time to be attached to product once again
+		s.di.Media.Touch(existing.Id)
+		return existing, nil
+	}
+
+	storedSize, err := s.di.ImageSaver.StoredSize(fileName)
+
+	if err != nil {
+		s.log().Warn("cannot get stored size of image", "file", fileName, "error", err)
+	}
+
+	return s.di.Media.Create(media.Media{
+		Url:        media.Url(fileName),
+		Mime:       upload.Mime,
+		Size:       upload.Size(),
+		StoredSize: storedSize,
+		Hash:       sum,
+		AccountId:  accountId,
+	}), nil
+}
+
+// SetProductGallery replaces photos of product with media in given order, the first one becomes image of product
+func (s *RelationService) SetProductGallery(id, version int, mediaIds []int, accountId, userId int) (product.DTO, *errors.CustomError) {
+
+	s, end := s.trace("SetProductGallery")
+	defer end()
+
+	oldModel, err := s.productRepository.Get(id, accountId)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	err = checkVersion("product", id, version, oldModel.Version)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	// every photo is checked before anything is written, callers other than api skip its validation
+	photos := []media.Media{}
+	seen := map[int]bool{}
+
+	for _, mediaId := range mediaIds {
+
+		if seen[mediaId] {
+			return product.DTO{}, errors.NewErrBadRequest(i18n.NewMessage("media %d is listed more than once", mediaId))
+		}
+
+		seen[mediaId] = true
+
+		m, err := s.di.Media.Get(mediaId, accountId)
+
+		if err != nil {
+			return product.DTO{}, err
+		}
+
+		photos = append(photos, m)
+	}
+
+	before, err := s.GetProduct(id, accountId)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	dto := product.UpdateDTO{
+		Id:          id,
+		Version:     version,
+		Title:       oldModel.Title,
+		Description: oldModel.Description,
+		Link:        oldModel.Link,
+		Barcode:     oldModel.Barcode,
+		ListId:      oldModel.ListId,
+		Stock:       oldModel.Stock,
+		Price:       oldModel.Price,
+	}
+
+	if len(photos) > 0 {
+		dto.MediaId = photos[0].Id
+		dto.Image = photos[0].Url
+	}
+
+	p, err := s.productRepository.UpdateFromDTO(dto, accountId)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	s.relinkMedia(id, oldModel, p, s.di.Gallery.GetMediaIds(id, accountId), mediaIds, accountId)
+
+	after, err := s.GetProduct(id, accountId)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	s.di.Audit.Record(audit.ActionUpdate, audit.EntityProduct, after.Id, before, after, accountId, userId)
+	s.di.Webhook.Emit(webhook.EventProductUpdated, after, accountId)
+
+	return after, nil
+}
+
+// relinkMedia moves references of product from media it referred to before to media it refers to now.
+// Product refers to its primary photo, every photo of its gallery is referred to by gallery item as well.
+func (s *RelationService) relinkMedia(productId int, before, after product.Product, previousGallery, gallery []int, accountId int) {
+
+	if after.MediaId != before.MediaId {
+		if after.MediaId != 0 {
+			s.di.Media.Acquire(after.MediaId, accountId)
+		}
+
+		if before.MediaId != 0 {
+			s.di.Media.Release(before.MediaId, accountId)
+		}
+	}
+
+	if before.MediaId == 0 && before.Image != after.Image {
+		s.deleteUntrackedImage(before.Image)
+	}
+
+	if sameIds(previousGallery, gallery) {
+		return
+	}
+
+	for _, id := range gallery {
+		s.di.Media.Acquire(id, accountId)
+	}
+
+	for _, id := range previousGallery {
+		s.di.Media.Release(id, accountId)
+	}
+
+	s.di.Gallery.Set(productId, gallery, accountId)
+}
+
+func sameIds(a, b []int) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deleteUntrackedImage deletes image of product saved before media was tracked
+func (s *RelationService) deleteUntrackedImage(url string) {
+	if url != "" {
+		s.deleteImage(media.FileName(url))
+	}
+}
+
+func (s *RelationService) deleteImage(fileName string) {
+	err := s.di.ImageSaver.DeleteFile(fileName)
+	if err != nil {
+		s.log().Warn("cannot delete image file", "file", fileName, "error", err)
+	}
+}
+
+// adoptedMedia describes image of product saved before media was tracked by its stored file,
+// original upload is gone so size and hash are of stored content
+func (s *RelationService) adoptedMedia(p product.Product) media.Media {
+
+	m := media.Media{
+		Url:       p.Image,
+		Mime:      "image/" + strings.TrimPrefix(path.Ext(p.Image), "."),
+		Refs:      1,
+		AccountId: p.AccountId,
+	}
+
+	fileName := media.FileName(p.Image)
+
+	content, _, err := s.di.ImageSaver.GetImage(fileName)
+
+	// product keeps its image even if file cannot be read now, the rest stays unknown
+	if err != nil {
+		s.log().Warn("media collection cannot read image file", "url", p.Image, "error", err)
+		return m
+	}
+
+	sum := sha256.Sum256(content.Bytes())
+
+	m.Mime = http.DetectContentType(content.Bytes())
+	m.Size = int64(content.Len())
+	m.Hash = hex.EncodeToString(sum[:])
+
+	m.StoredSize, err = s.di.ImageSaver.StoredSize(fileName)
+
+	if err != nil {
+		s.log().Warn("cannot get stored size of image", "file", fileName, "error", err)
+	}
+
+	return m
+}
+
+// StartMediaCollector runs media garbage collection in background
+func (s *RelationService) StartMediaCollector() {
+
+	grace := time.Duration(s.config.UserContent.MediaGraceHours) * time.Hour
+
+	if grace <= 0 {
+		grace = media.DefaultGrace
+	}
+
+	worker := s.di.Health.Worker("media_collector", 3*mediaCollectInterval)
+
+	go func() {
+		ticker := time.NewTicker(mediaCollectInterval)
+		defer ticker.Stop()
+
+		for {
+			worker.Beat()
+
+			result := s.CollectMedia(grace)
+
+			if result.Adopted+result.Deleted+result.Failed > 0 {
+				s.log().Info("media collected", "adopted", result.Adopted, "deleted", result.Deleted, "failed", result.Failed)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
+
+// CollectMedia registers product images saved before media was tracked and deletes media nobody refers to
+// for longer than grace. Files are deleted through image saver, so it works with every storage.
+func (s *RelationService) CollectMedia(grace time.Duration) MediaCollection {
+
+	result := MediaCollection{}
+
+	for {
+		products := s.productRepository.GetWithUntrackedImage(media.UrlPrefix, mediaCollectBatch)
+
+		for _, p := range products {
+			m := s.di.Media.Create(s.adoptedMedia(p))
+
+			s.productRepository.SetMediaId(p.Id, m.Id, p.AccountId)
+			result.Adopted++
+		}
+
+		if len(products) < mediaCollectBatch {
+			break
+		}
+	}
+
+	before := time.Now().Add(-grace)
+
+	for {
+		orphans := s.di.Media.GetOrphans(before, mediaCollectBatch)
+
+		for _, m := range orphans {
+			// row goes first, so media referenced in the meantime keeps its file
+			if !s.di.Media.DeleteOrphan(m.Id) {
+				continue
+			}
+
+			err := s.di.ImageSaver.DeleteFile(media.FileName(m.Url))
+
+			if err != nil {
+				s.log().Warn("media collection cannot delete image file", "url", m.Url, "error", err)
+				result.Failed++
+				continue
+			}
+
+			result.Deleted++
+		}
+
+		if len(orphans) < mediaCollectBatch {
+			break
+		}
+	}
+
+	return result
+}
+
+// GetProductGallery returns photos of product in order, the first one is image of product
+func (s *RelationService) GetProductGallery(id int, accountId int) ([]media.DTO, *errors.CustomError) {
+
+	s, end := s.trace("GetProductGallery")
+	defer end()
+
+	_, err := s.productRepository.Get(id, accountId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := []media.DTO{}
+
+	for _, mediaId := range s.di.Gallery.GetMediaIds(id, accountId) {
+		m, err := s.di.Media.Get(mediaId, accountId)
+
+		if err != nil {
+			continue
+		}
+
+		dtos = append(dtos, media.ModelToDTO(m))
+	}
+
+	return dtos, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/media"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetProductGalleryChecksEveryPhoto(t *testing.T) {
+
+	s := newTestService(t)
+
+	fridge := s.CreateList(list.DTO{Title: "Fridge"}, 1, 1)
+
+	milk, err := s.CreateProduct(product.CreateDTO{Title: "Milk", ListId: fridge.Id}, 1, 1)
+	assert.Nil(t, err)
+
+	front := s.di.Media.Create(media.Media{Url: media.Url("front.png"), AccountId: 1})
+	back := s.di.Media.Create(media.Media{Url: media.Url("back.png"), AccountId: 1})
+	foreign := s.di.Media.Create(media.Media{Url: media.Url("foreign.png"), AccountId: 2})
+
+	_, err = s.SetProductGallery(milk.Id, 0, []int{front.Id, foreign.Id}, 1, 1)
+	assert.Equal(t, 404, err.Code())
+
+	_, err = s.SetProductGallery(milk.Id, 0, []int{front.Id, back.Id, front.Id}, 1, 1)
+	assert.Equal(t, 400, err.Code())
+
+	// nothing was written by rejected requests
+	unchanged, err := s.GetProduct(milk.Id, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, milk.Version, unchanged.Version)
+	assert.Empty(t, s.di.Gallery.GetMediaIds(milk.Id, 1))
+
+	updated, err := s.SetProductGallery(milk.Id, 0, []int{back.Id, front.Id}, 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, back.Id, updated.MediaId)
+	assert.Equal(t, []int{back.Id, front.Id}, s.di.Gallery.GetMediaIds(milk.Id, 1))
+}
+
+func TestCollectMediaAdoptsStoredImage(t *testing.T) {
+
+	s := newTestService(t)
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+
+	content := buf.Bytes()
+	sum := sha256.Sum256(content)
+
+	// saved before media was tracked under name telling nothing of its type
+	assert.NoError(t, os.WriteFile(filepath.Join(s.config.UserContent.Location, "legacy.jpeg"), content, 0644))
+
+	legacy := s.productRepository.Create(product.CreateDTO{Title: "Milk", Image: media.Url("legacy.jpeg")}, 1)
+	missing := s.productRepository.Create(product.CreateDTO{Title: "Bread", Image: media.Url("missing.png")}, 1)
+
+	result := s.CollectMedia(time.Hour)
+	assert.Equal(t, 2, result.Adopted)
+
+	adopted, ok := s.di.Media.GetByUrl(legacy.Image)
+	assert.True(t, ok)
+	assert.Equal(t, "image/png", adopted.Mime)
+	assert.Equal(t, int64(len(content)), adopted.Size)
+	assert.Equal(t, int64(len(content)), adopted.StoredSize)
+	assert.Equal(t, hex.EncodeToString(sum[:]), adopted.Hash)
+	assert.Equal(t, 1, adopted.Refs)
+
+	// product whose file cannot be read keeps its image
+	unknown, ok := s.di.Media.GetByUrl(missing.Image)
+	assert.True(t, ok)
+	assert.Equal(t, "image/png", unknown.Mime)
+	assert.Empty(t, unknown.Hash)
+	assert.Equal(t, 1, unknown.Refs)
+}
@@ -1,22 +1,25 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"github.com/proviant-io/core/internal/apm"
 	"github.com/proviant-io/core/internal/config"
 	"github.com/proviant-io/core/internal/di"
 	"github.com/proviant-io/core/internal/errors"
 	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/audit"
 	"github.com/proviant-io/core/internal/pkg/category"
 	"github.com/proviant-io/core/internal/pkg/consumption"
+	"github.com/proviant-io/core/internal/pkg/gallery"
 	"github.com/proviant-io/core/internal/pkg/list"
 	"github.com/proviant-io/core/internal/pkg/product"
 	"github.com/proviant-io/core/internal/pkg/product_category"
 	"github.com/proviant-io/core/internal/pkg/shopping"
 	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/proviant-io/core/internal/pkg/webhook"
 	"github.com/proviant-io/core/internal/utils"
-	"log"
-	"path"
-	"strings"
+	"time"
 )
 
 type RelationService struct {
@@ -27,10 +30,15 @@ type RelationService struct {
 	productCategoryRepository *product_category.Repository
 	di                        *di.DI
 	config                    config.Config
+	// ctx is context of request service works for, spans of its methods are children of span in it
+	ctx context.Context
 }
 
 func (s *RelationService) GetProduct(id int, accountId int) (product.DTO, *errors.CustomError) {
 
+	s, end := s.trace("GetProduct")
+	defer end()
+
 	p, err := s.productRepository.Get(id, accountId)
 
 	if err != nil {
@@ -65,11 +73,16 @@ func (s *RelationService) GetProduct(id int, accountId int) (product.DTO, *error
 
 	productDTO.Categories = categoriesDTOs
 
+	productDTO.MediaIds = s.di.Gallery.GetMediaIds(id, accountId)
+
 	return productDTO, nil
 }
 
 func (s *RelationService) GetAllProducts(query *product.Query, accountId int) []product.DTO {
 
+	s, end := s.trace("GetAllProducts")
+	defer end()
+
 	models := s.productRepository.GetAll(query, accountId)
 
 	dtos := []product.DTO{}
@@ -84,6 +97,7 @@ func (s *RelationService) GetAllProducts(query *product.Query, accountId int) []
 
 		dtos[idx].CategoryIds = []int{}
 		dtos[idx].Categories = []interface{}{}
+		dtos[idx].MediaIds = s.di.Gallery.GetMediaIds(dtos[idx].Id, accountId)
 
 		productCategories := s.productCategoryRepository.GetByProductId(dtos[idx].Id, accountId)
 
@@ -105,7 +119,10 @@ func (s *RelationService) GetAllProducts(query *product.Query, accountId int) []
 	return filteredDTOs
 }
 
-func (s *RelationService) CreateProduct(dto product.CreateDTO, accountId int) (product.DTO, *errors.CustomError) {
+func (s *RelationService) CreateProduct(dto product.CreateDTO, accountId, userId int) (product.DTO, *errors.CustomError) {
+
+	s, end := s.trace("CreateProduct")
+	defer end()
 
 	_, err := s.listRepository.Get(dto.ListId, accountId)
 
@@ -124,27 +141,50 @@ func (s *RelationService) CreateProduct(dto product.CreateDTO, accountId int) (p
 	}
 
 	if dto.ImageBase64 != "" {
-		imgPath, pureErr := s.di.ImageSaver.SaveBase64(dto.ImageBase64)
+		m, pureErr := s.saveBase64(dto.ImageBase64, accountId)
 		if pureErr != nil {
-			return product.DTO{}, errors.NewInternalServer(i18n.NewMessage(pureErr.Error()))
+			return product.DTO{}, imageError(pureErr)
 		}
 
-		// convert imgPath into server accessable one
-		imgPath = strings.Replace(imgPath, s.config.UserContent.Location, "", 1)
-		imgPath = path.Join("/uc/img", imgPath)
-		dto.Image = imgPath
+		dto.MediaId = m.Id
+	}
+
+	if dto.MediaId != 0 {
+		m, err := s.di.Media.Get(dto.MediaId, accountId)
+
+		if err != nil {
+			return product.DTO{}, err
+		}
+
+		dto.Image = m.Url
 	}
 
 	p := s.productRepository.Create(dto, accountId)
 
+	if p.MediaId != 0 {
+		s.relinkMedia(p.Id, product.Product{}, p, []int{}, []int{p.MediaId}, accountId)
+	}
+
 	if len(dto.CategoryIds) != 0 {
 		s.productCategoryRepository.Link(p.Id, dto.CategoryIds, accountId)
 	}
 
-	return s.GetProduct(p.Id, accountId)
+	created, err := s.GetProduct(p.Id, accountId)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	s.di.Audit.Record(audit.ActionCreate, audit.EntityProduct, created.Id, nil, created, accountId, userId)
+	s.di.Webhook.Emit(webhook.EventProductCreated, created, accountId)
+
+	return created, nil
 }
 
-func (s *RelationService) UpdateProduct(dto product.UpdateDTO, accountId int) (product.DTO, *errors.CustomError) {
+func (s *RelationService) UpdateProduct(dto product.UpdateDTO, accountId, userId int) (product.DTO, *errors.CustomError) {
+
+	s, end := s.trace("UpdateProduct")
+	defer end()
 
 	_, err := s.listRepository.Get(dto.ListId, accountId)
 
@@ -168,31 +208,58 @@ func (s *RelationService) UpdateProduct(dto product.UpdateDTO, accountId int) (p
 		return product.DTO{}, err
 	}
 
+	// check version before image is replaced, repository checks it once again atomically
+	err = checkVersion("product", dto.Id, dto.Version, oldModel.Version)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	before, err := s.GetProduct(dto.Id, accountId)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
 	// stock should not be change via model update
 	dto.Stock = oldModel.Stock
 
 	// sanitize from custom urls
 	if oldModel.Image != dto.Image {
 		dto.Image = ""
+	} else if dto.MediaId == 0 {
+		dto.MediaId = oldModel.MediaId
 	}
 
 	if dto.ImageBase64 != "" {
-		imgPath, pureErr := s.di.ImageSaver.SaveBase64(dto.ImageBase64)
+		m, pureErr := s.saveBase64(dto.ImageBase64, accountId)
 		if pureErr != nil {
-			return product.DTO{}, errors.NewInternalServer(i18n.NewMessage(pureErr.Error()))
+			return product.DTO{}, imageError(pureErr)
 		}
 
-		// convert imgPath into server accessable
-		imgPath = strings.Replace(imgPath, s.config.UserContent.Location, "", 1)
-		imgPath = path.Join("/uc/img", imgPath)
-		dto.Image = imgPath
+		dto.MediaId = m.Id
+	}
+
+	previousGallery := s.di.Gallery.GetMediaIds(dto.Id, accountId)
+	newGallery := previousGallery
 
-		fileToRemove := strings.Replace(oldModel.Image, "/uc/img", "", 1)
+	// image of product is primary photo of its gallery
+	if dto.MediaId != oldModel.MediaId {
+		newGallery = gallery.WithPrimary(previousGallery, oldModel.MediaId, dto.MediaId)
 
-		pureErr = s.di.ImageSaver.DeleteFile(fileToRemove)
-		if pureErr != nil {
-			log.Printf("cannot delete product image file: %s, %v\n", fileToRemove, pureErr)
+		if dto.MediaId == 0 && len(newGallery) > 0 {
+			dto.MediaId = newGallery[0]
+		}
+	}
+
+	if dto.MediaId != 0 {
+		m, err := s.di.Media.Get(dto.MediaId, accountId)
+
+		if err != nil {
+			return product.DTO{}, err
 		}
+
+		dto.Image = m.Url
 	}
 
 	p, err := s.productRepository.UpdateFromDTO(dto, accountId)
@@ -201,6 +268,8 @@ func (s *RelationService) UpdateProduct(dto product.UpdateDTO, accountId int) (p
 		return product.DTO{}, err
 	}
 
+	s.relinkMedia(p.Id, oldModel, p, previousGallery, newGallery, accountId)
+
 	// NOTE: here could be performance bottle neck
 	s.productCategoryRepository.DeleteByProductId(p.Id, accountId)
 
@@ -208,10 +277,22 @@ func (s *RelationService) UpdateProduct(dto product.UpdateDTO, accountId int) (p
 		s.productCategoryRepository.Link(p.Id, dto.CategoryIds, accountId)
 	}
 
-	return s.GetProduct(p.Id, accountId)
+	after, err := s.GetProduct(p.Id, accountId)
+
+	if err != nil {
+		return product.DTO{}, err
+	}
+
+	s.di.Audit.Record(audit.ActionUpdate, audit.EntityProduct, after.Id, before, after, accountId, userId)
+	s.di.Webhook.Emit(webhook.EventProductUpdated, after, accountId)
+
+	return after, nil
 }
 
-func (s *RelationService) AddStock(dto stock.DTO, accountId int) (stock.Stock, *errors.CustomError) {
+func (s *RelationService) AddStock(dto stock.DTO, accountId, userId int) (stock.Stock, *errors.CustomError) {
+
+	s, end := s.trace("AddStock")
+	defer end()
 
 	p, err := s.productRepository.Get(dto.ProductId, accountId)
 
@@ -221,23 +302,47 @@ func (s *RelationService) AddStock(dto stock.DTO, accountId int) (stock.Stock, *
 
 	model := s.stockRepository.Add(dto, accountId)
 
+	s.di.Audit.Record(audit.ActionCreate, audit.EntityStock, model.Id, nil, stock.ModelToDTO(model), accountId, userId)
+	s.di.Webhook.Emit(webhook.EventStockAdded, stock.ModelToDTO(model), accountId)
+	s.count(apm.MetricStockAdded, float64(dto.Quantity))
+
 	p.Stock += dto.Quantity
 
 	_, err = s.productRepository.Save(p, accountId)
 
-	return model, err
+	if err != nil {
+		return model, err
+	}
+
+	s.di.StockWatcher.Publish(stock.Change{
+		Type:      stock.ChangeAdded,
+		ProductId: p.Id,
+		LotId:     model.Id,
+		Quantity:  dto.Quantity,
+		Stock:     p.Stock,
+		AccountId: accountId,
+	})
+
+	return model, nil
 }
 
 func (s *RelationService) ConsumeStock(dto stock.ConsumeDTO, accountId int, userId int) (*errors.CustomError, consumption.DTO) {
 
+	s, end := s.trace("ConsumeStock")
+	defer end()
+
 	p, err := s.productRepository.Get(dto.ProductId, accountId)
 
 	if err != nil {
 		return err, consumption.DTO{}
 	}
 
+	lotsBefore := s.stockRepository.GetAllByProductId(dto.ProductId, accountId)
+
 	consumed := s.stockRepository.Consume(dto, accountId)
 
+	s.recordStockChanges(lotsBefore, s.stockRepository.GetAllByProductId(dto.ProductId, accountId), accountId, userId)
+
 	if dto.Quantity >= p.Stock {
 		p.Stock = 0
 	} else {
@@ -254,10 +359,99 @@ func (s *RelationService) ConsumeStock(dto stock.ConsumeDTO, accountId int, user
 		ProductId: dto.ProductId,
 		Quantity:  consumed,
 	}, accountId, userId)
+
+	s.di.Webhook.Emit(webhook.EventStockConsumed, consumption.ModelToDTO(consumedLog), accountId)
+	s.count(apm.MetricStockConsumed, float64(consumed))
+	s.di.StockWatcher.Publish(stock.Change{
+		Type:      stock.ChangeConsumed,
+		ProductId: p.Id,
+		Quantity:  consumed,
+		Stock:     p.Stock,
+		AccountId: accountId,
+	})
+
 	return nil, consumption.ModelToDTO(consumedLog)
 }
 
-func (s *RelationService) DeleteStock(id int, accountId int) *errors.CustomError {
+func (s *RelationService) UpdateStock(id int, dto stock.DTO, accountId, userId int) (stock.DTO, *errors.CustomError) {
+
+	s, end := s.trace("UpdateStock")
+	defer end()
+
+	before, err := s.stockRepository.Get(id, accountId)
+
+	if err != nil {
+		return stock.DTO{}, err
+	}
+
+	p, err := s.productRepository.Get(before.ProductId, accountId)
+
+	if err != nil {
+		return stock.DTO{}, err
+	}
+
+	model, err := s.stockRepository.Update(id, dto, accountId)
+
+	if err != nil {
+		return stock.DTO{}, err
+	}
+
+	after := stock.ModelToDTO(model)
+
+	s.di.Audit.Record(audit.ActionUpdate, audit.EntityStock, id, stock.ModelToDTO(before), after, accountId, userId)
+
+	// product stock is a sum of its lots
+	if p.Stock+model.Quantity >= before.Quantity {
+		p.Stock = p.Stock + model.Quantity - before.Quantity
+	} else {
+		p.Stock = 0
+	}
+
+	_, err = s.productRepository.Save(p, accountId)
+
+	if err != nil {
+		return after, err
+	}
+
+	s.di.StockWatcher.Publish(stock.Change{
+		Type:      stock.ChangeUpdated,
+		ProductId: p.Id,
+		LotId:     id,
+		Quantity:  model.Quantity,
+		Stock:     p.Stock,
+		AccountId: accountId,
+	})
+
+	return after, nil
+}
+
+// recordStockChanges writes audit entries for stock lots touched by consumption
+func (s *RelationService) recordStockChanges(before, after []stock.Stock, accountId, userId int) {
+
+	left := map[int]stock.Stock{}
+
+	for _, lot := range after {
+		left[lot.Id] = lot
+	}
+
+	for _, lot := range before {
+		current, ok := left[lot.Id]
+
+		if !ok {
+			s.di.Audit.Record(audit.ActionDelete, audit.EntityStock, lot.Id, stock.ModelToDTO(lot), nil, accountId, userId)
+			continue
+		}
+
+		if current.Quantity != lot.Quantity {
+			s.di.Audit.Record(audit.ActionUpdate, audit.EntityStock, lot.Id, stock.ModelToDTO(lot), stock.ModelToDTO(current), accountId, userId)
+		}
+	}
+}
+
+func (s *RelationService) DeleteStock(id, version int, accountId, userId int) *errors.CustomError {
+
+	s, end := s.trace("DeleteStock")
+	defer end()
 
 	st, err := s.stockRepository.Get(id, accountId)
 
@@ -271,24 +465,44 @@ func (s *RelationService) DeleteStock(id int, accountId int) *errors.CustomError
 		return err
 	}
 
-	err = s.stockRepository.Delete(id, accountId)
+	err = s.stockRepository.Delete(id, version, accountId)
 
 	if err != nil {
 		return err
 	}
 
-	p.Stock -= st.Quantity
+	s.di.Audit.Record(audit.ActionDelete, audit.EntityStock, st.Id, stock.ModelToDTO(st), nil, accountId, userId)
+	s.di.Webhook.Emit(webhook.EventStockDeleted, stock.ModelToDTO(st), accountId)
 
-	if p.Stock < 0 {
+	// stock is unsigned, so it is clamped before subtraction
+	if st.Quantity >= p.Stock {
 		p.Stock = 0
+	} else {
+		p.Stock -= st.Quantity
 	}
 
 	_, err = s.productRepository.Save(p, accountId)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	s.di.StockWatcher.Publish(stock.Change{
+		Type:      stock.ChangeDeleted,
+		ProductId: p.Id,
+		LotId:     st.Id,
+		Quantity:  st.Quantity,
+		Stock:     p.Stock,
+		AccountId: accountId,
+	})
+
+	return nil
 }
 
-func (s *RelationService) DeleteProduct(id int, accountId int) *errors.CustomError {
+func (s *RelationService) DeleteProduct(id, version int, accountId, userId int) *errors.CustomError {
+
+	s, end := s.trace("DeleteProduct")
+	defer end()
 
 	oldModel, err := s.productRepository.Get(id, accountId)
 
@@ -296,32 +510,163 @@ func (s *RelationService) DeleteProduct(id int, accountId int) *errors.CustomErr
 		return err
 	}
 
-	fileToRemove := strings.Replace(oldModel.Image, "/content", "", 1)
+	err = checkVersion("product", id, version, oldModel.Version)
+
+	if err != nil {
+		return err
+	}
+
+	// product is deleted at version it was read at before anything else, so nothing is cleaned up
+	// when it was changed in the meantime
+	err = s.productRepository.Delete(id, oldModel.Version, accountId)
 
-	pureErr := s.di.ImageSaver.DeleteFile(fileToRemove)
-	if pureErr != nil {
-		fmt.Printf("cannot delete product image file: %s, %v", fileToRemove, pureErr)
+	if err != nil {
+		return err
 	}
 
+	s.relinkMedia(id, oldModel, product.Product{}, s.di.Gallery.GetMediaIds(id, accountId), []int{}, accountId)
+
+	s.deleteAttachments(id, accountId)
+
+	lots := s.stockRepository.GetAllByProductId(id, accountId)
+
 	s.stockRepository.DeleteByProductId(id, accountId)
 
 	s.di.ConsumptionLog.DeleteByProductId(id, accountId)
 
 	s.productCategoryRepository.DeleteByProductId(id, accountId)
 
-	err = s.productRepository.Delete(id, accountId)
+	for _, lot := range lots {
+		s.di.Audit.Record(audit.ActionDelete, audit.EntityStock, lot.Id, stock.ModelToDTO(lot), nil, accountId, userId)
+	}
+
+	s.di.Audit.Record(audit.ActionDelete, audit.EntityProduct, oldModel.Id, product.ModelToDTO(oldModel), nil, accountId, userId)
+
+	if oldModel.Stock > 0 {
+		s.di.StockWatcher.Publish(stock.Change{
+			Type:      stock.ChangeDeleted,
+			ProductId: oldModel.Id,
+			Quantity:  oldModel.Stock,
+			AccountId: accountId,
+		})
+	}
+
+	return nil
+}
+
+func (s *RelationService) CreateCategory(dto category.DTO, accountId, userId int) category.DTO {
+
+	s, end := s.trace("CreateCategory")
+	defer end()
+
+	created := category.ModelToDTO(s.categoryRepository.Create(dto, accountId))
+
+	s.di.Audit.Record(audit.ActionCreate, audit.EntityCategory, created.Id, nil, created, accountId, userId)
+
+	return created
+}
+
+func (s *RelationService) UpdateCategory(id int, dto category.DTO, accountId, userId int) (category.DTO, *errors.CustomError) {
+
+	s, end := s.trace("UpdateCategory")
+	defer end()
+
+	before, err := s.categoryRepository.Get(id, accountId)
+
+	if err != nil {
+		return category.DTO{}, err
+	}
+
+	model, err := s.categoryRepository.Update(id, dto, accountId)
+
+	if err != nil {
+		return category.DTO{}, err
+	}
+
+	after := category.ModelToDTO(model)
 
-	return err
+	s.di.Audit.Record(audit.ActionUpdate, audit.EntityCategory, id, category.ModelToDTO(before), after, accountId, userId)
+
+	return after, nil
 }
 
-func (s *RelationService) DeleteCategory(id int, accountId int) *errors.CustomError {
+func (s *RelationService) DeleteCategory(id, version int, accountId, userId int) *errors.CustomError {
+
+	s, end := s.trace("DeleteCategory")
+	defer end()
+
+	before, err := s.categoryRepository.Get(id, accountId)
+
+	if err != nil {
+		return err
+	}
+
+	err = checkVersion("category", id, version, before.Version)
+
+	if err != nil {
+		return err
+	}
+
+	// links of products are kept when category was changed in the meantime
+	err = s.categoryRepository.Delete(id, before.Version, accountId)
+
+	if err != nil {
+		return err
+	}
 
 	s.productCategoryRepository.DeleteByCategory(id, accountId)
 
-	return s.categoryRepository.Delete(id, accountId)
+	s.di.Audit.Record(audit.ActionDelete, audit.EntityCategory, id, category.ModelToDTO(before), nil, accountId, userId)
+
+	return nil
+}
+
+func (s *RelationService) CreateList(dto list.DTO, accountId, userId int) list.DTO {
+
+	s, end := s.trace("CreateList")
+	defer end()
+
+	created := list.ModelToDTO(s.listRepository.Create(dto, accountId))
+
+	s.di.Audit.Record(audit.ActionCreate, audit.EntityList, created.Id, nil, created, accountId, userId)
+
+	return created
+}
+
+func (s *RelationService) UpdateList(id int, dto list.DTO, accountId, userId int) (list.DTO, *errors.CustomError) {
+
+	s, end := s.trace("UpdateList")
+	defer end()
+
+	before, err := s.listRepository.Get(id, accountId)
+
+	if err != nil {
+		return list.DTO{}, err
+	}
+
+	model, err := s.listRepository.Update(id, dto, accountId)
+
+	if err != nil {
+		return list.DTO{}, err
+	}
+
+	after := list.ModelToDTO(model)
+
+	s.di.Audit.Record(audit.ActionUpdate, audit.EntityList, id, list.ModelToDTO(before), after, accountId, userId)
+
+	return after, nil
 }
 
-func (s *RelationService) DeleteList(id int, accountId int) *errors.CustomError {
+func (s *RelationService) DeleteList(id, version int, accountId, userId int) *errors.CustomError {
+
+	s, end := s.trace("DeleteList")
+	defer end()
+
+	before, err := s.listRepository.Get(id, accountId)
+
+	if err != nil {
+		return err
+	}
 
 	q := &product.Query{
 		List: id,
@@ -330,14 +675,25 @@ func (s *RelationService) DeleteList(id int, accountId int) *errors.CustomError
 	models := s.productRepository.GetAll(q, accountId)
 
 	if len(models) > 0 {
-		return errors.NewErrBadRequest(i18n.NewMessage("You can't remove list with products. Clean products first."))
+		return errors.NewErrConflict(i18n.NewMessage("You can't remove list with products. Clean products first.")).WithV1Code(400)
+	}
+
+	err = s.listRepository.Delete(id, version, accountId)
+
+	if err != nil {
+		return err
 	}
 
-	return s.listRepository.Delete(id, accountId)
+	s.di.Audit.Record(audit.ActionDelete, audit.EntityList, id, list.ModelToDTO(before), nil, accountId, userId)
+
+	return nil
 }
 
 func (s *RelationService) GetShoppingList(id, accountId int) (shopping.ListFilledDTO, *errors.CustomError) {
 
+	s, end := s.trace("GetShoppingList")
+	defer end()
+
 	listModel, err := s.di.ShoppingList.Get(id, accountId)
 
 	if err != nil {
@@ -361,7 +717,10 @@ func (s *RelationService) GetShoppingList(id, accountId int) (shopping.ListFille
 	return dto, nil
 }
 
-func (s *RelationService) AddShoppingListItem(id int, dto shopping.ItemDTO, accountId int) (shopping.ItemDTO, *errors.CustomError) {
+func (s *RelationService) AddShoppingListItem(id int, dto shopping.ItemDTO, accountId, userId int) (shopping.ItemDTO, *errors.CustomError) {
+
+	s, end := s.trace("AddShoppingListItem")
+	defer end()
 
 	listModel, err := s.di.ShoppingList.Get(id, accountId)
 
@@ -378,12 +737,18 @@ func (s *RelationService) AddShoppingListItem(id int, dto shopping.ItemDTO, acco
 		}
 	}
 
-	item := s.di.ShoppingListItem.Create(dto, accountId)
+	created := shopping.ItemToDTO(s.di.ShoppingListItem.Create(dto, accountId))
+
+	s.di.Audit.Record(audit.ActionCreate, audit.EntityShoppingItem, created.Id, nil, created, accountId, userId)
+	s.di.ShoppingListEvent.Publish(shopping.EventItemAdded, created, accountId)
 
-	return shopping.ItemToDTO(item), nil
+	return created, nil
 }
 
-func (s *RelationService) UpdateShoppingListItem(id int, dto shopping.ItemDTO, accountId int) (shopping.ItemDTO, *errors.CustomError) {
+func (s *RelationService) UpdateShoppingListItem(id int, dto shopping.ItemDTO, accountId, userId int) (shopping.ItemDTO, *errors.CustomError) {
+
+	s, end := s.trace("UpdateShoppingListItem")
+	defer end()
 
 	listModel, err := s.di.ShoppingList.Get(id, accountId)
 
@@ -400,43 +765,118 @@ func (s *RelationService) UpdateShoppingListItem(id int, dto shopping.ItemDTO, a
 		}
 	}
 
+	before, err := s.di.ShoppingListItem.Get(dto.Id, accountId)
+
+	if err != nil {
+		return shopping.ItemDTO{}, err
+	}
+
 	item, err := s.di.ShoppingListItem.Update(dto.Id, dto, accountId)
 
 	if err != nil {
 		return shopping.ItemDTO{}, err
 	}
 
-	return shopping.ItemToDTO(item), nil
+	after := shopping.ItemToDTO(item)
+
+	s.di.Audit.Record(audit.ActionUpdate, audit.EntityShoppingItem, after.Id, shopping.ItemToDTO(before), after, accountId, userId)
+	s.di.ShoppingListEvent.Publish(shopping.EventItemUpdated, after, accountId)
+
+	return after, nil
+}
+
+func (s *RelationService) DeleteShoppingListItem(id, version int, accountId, userId int) *errors.CustomError {
+
+	s, end := s.trace("DeleteShoppingListItem")
+	defer end()
+
+	before, err := s.di.ShoppingListItem.Get(id, accountId)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.di.ShoppingListItem.Delete(id, version, accountId)
+
+	if err != nil {
+		return err
+	}
+
+	s.di.Audit.Record(audit.ActionDelete, audit.EntityShoppingItem, id, shopping.ItemToDTO(before), nil, accountId, userId)
+	s.di.ShoppingListEvent.Publish(shopping.EventItemDeleted, shopping.ItemToDTO(before), accountId)
+
+	return nil
 }
 
-func (s *RelationService) UpdateCheckedShoppingListItem(id int, checked bool, accountId int) (shopping.ItemDTO, *errors.CustomError) {
+func (s *RelationService) UpdateCheckedShoppingListItem(id, version int, checked bool, accountId, userId int) (shopping.ItemDTO, *errors.CustomError) {
+
+	s, end := s.trace("UpdateCheckedShoppingListItem")
+	defer end()
+
+	before, err := s.di.ShoppingListItem.Get(id, accountId)
+
+	if err != nil {
+		return shopping.ItemDTO{}, err
+	}
 
 	var item shopping.Item
-	var err *errors.CustomError
 
 	if checked {
-		item, err = s.di.ShoppingListItem.Check(id, accountId)
+		item, err = s.di.ShoppingListItem.Check(id, version, accountId)
 	} else {
-		item, err = s.di.ShoppingListItem.Uncheck(id, accountId)
+		item, err = s.di.ShoppingListItem.Uncheck(id, version, accountId)
 	}
 
 	if err != nil {
 		return shopping.ItemDTO{}, err
 	}
 
+	after := shopping.ItemToDTO(item)
+
+	s.di.Audit.Record(audit.ActionUpdate, audit.EntityShoppingItem, after.Id, shopping.ItemToDTO(before), after, accountId, userId)
+
+	if checked {
+		s.di.ShoppingListEvent.Publish(shopping.EventItemChecked, after, accountId)
+		s.di.Webhook.Emit(webhook.EventShoppingItemChecked, after, accountId)
+	} else {
+		s.di.ShoppingListEvent.Publish(shopping.EventItemUnchecked, after, accountId)
+	}
+
 	if checked && item.ProductId > 0 {
 		_, err = s.AddStock(stock.DTO{
 			ProductId: item.ProductId,
 			Quantity:  uint(item.Quantity),
 			Expire:    0,
-		}, accountId)
+		}, accountId, userId)
 
 		if err != nil {
 			// TODO log errors here
 		}
 	}
 
-	return shopping.ItemToDTO(item), nil
+	return after, nil
+}
+
+// EmitExpiringStock notifies webhook subscribers about lots which expire within subscription threshold.
+// Every lot is reported once per subscription.
+func (s *RelationService) EmitExpiringStock() {
+
+	now := time.Now()
+
+	for _, sub := range s.di.Webhook.Subscriptions().GetActiveByEvent(webhook.EventStockExpiring) {
+
+		days := sub.ExpiryThresholdDays
+		if days <= 0 {
+			days = webhook.DefaultExpiryThresholdDays
+		}
+
+		until := now.Add(time.Duration(days) * 24 * time.Hour).Unix()
+
+		for _, lot := range s.stockRepository.GetExpiring(sub.AccountId, until) {
+			dedupeKey := fmt.Sprintf("%s:%d:%d", webhook.EventStockExpiring, lot.Id, lot.Expire)
+			s.di.Webhook.EmitTo(sub, webhook.EventStockExpiring, dedupeKey, stock.ModelToDTO(lot))
+		}
+	}
 }
 
 func NewRelationService(productRepository *product.Repository,
@@ -457,3 +897,13 @@ func NewRelationService(productRepository *product.Repository,
 		config:                    config,
 	}
 }
+
+// checkVersion compares version sent by client (0 means client doesn't care) with the stored one
+func checkVersion(entity string, id, expected, actual int) *errors.CustomError {
+
+	if expected != 0 && expected != actual {
+		return errors.NewErrPreconditionFailed(i18n.NewMessage(entity+" with id %d was changed by someone else", id))
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeleteStockClampsProductStock(t *testing.T) {
+
+	s := newTestService(t)
+
+	fridge := s.CreateList(list.DTO{Title: "Fridge"}, 1, 1)
+
+	milk, err := s.CreateProduct(product.CreateDTO{Title: "Milk", ListId: fridge.Id}, 1, 1)
+	assert.Nil(t, err)
+
+	small, err := s.AddStock(stock.DTO{ProductId: milk.Id, Quantity: 2}, 1, 1)
+	assert.Nil(t, err)
+
+	large, err := s.AddStock(stock.DTO{ProductId: milk.Id, Quantity: 5}, 1, 1)
+	assert.Nil(t, err)
+
+	err = s.DeleteStock(small.Id, 0, 1, 1)
+	assert.Nil(t, err)
+
+	p, err := s.GetProduct(milk.Id, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(5), p.Stock)
+
+	// product stock drifted below its lots, it must not wrap around
+	model, err := s.productRepository.Get(milk.Id, 1)
+	assert.Nil(t, err)
+	model.Stock = 3
+	_, err = s.productRepository.Save(model, 1)
+	assert.Nil(t, err)
+
+	err = s.DeleteStock(large.Id, 0, 1, 1)
+	assert.Nil(t, err)
+
+	// column is read raw, wrapped value would not even scan into product
+	var stored []int64
+	assert.NoError(t, s.di.DB.Connection().Model(&product.Product{}).Where("id = ?", milk.Id).Pluck("stock", &stored).Error)
+	assert.Equal(t, []int64{0}, stored)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"github.com/proviant-io/core/internal/pkg/audit"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/shopping"
+	"github.com/proviant-io/core/internal/pkg/stock"
+)
+
+// SyncChange is the current state of changed entity, deleted entity is reported as tombstone without data
+type SyncChange struct {
+	Entity  string      `json:"entity"`
+	Id      int         `json:"id"`
+	Deleted bool        `json:"deleted"`
+	Data    interface{} `json:"data"`
+}
+
+// SyncFeed holds changes made after cursor. Client keeps returned cursor and sends it with the next request.
+type SyncFeed struct {
+	Cursor int `json:"cursor"`
+	// HasMore tells client to request the next page right away
+	HasMore bool `json:"has_more"`
+	// Reset tells client to replace local data with changes instead of merging them into it
+	Reset   bool         `json:"reset"`
+	Changes []SyncChange `json:"changes"`
+}
+
+// Changes returns entities changed after cursor, the audit trail serves as change log and cursor is sequence of its
+// entry, which follows order of commits.
+// Zero cursor gets snapshot of all entities. Deletion of product removes its stock and product category links,
+// deletion of category removes its links only, client applies the same to local data.
+func (s *RelationService) Changes(cursor, limit, accountId int) SyncFeed {
+
+	s, end := s.trace("Changes")
+	defer end()
+
+	if cursor == 0 {
+		return s.syncSnapshot(accountId)
+	}
+
+	if limit <= 0 {
+		limit = audit.DefaultLimit
+	}
+
+	if limit > audit.MaxLimit {
+		limit = audit.MaxLimit
+	}
+
+	entries := s.di.Audit.GetSince(cursor, limit+1, accountId)
+
+	feed := SyncFeed{
+		Cursor:  cursor,
+		HasMore: len(entries) > limit,
+		Changes: []SyncChange{},
+	}
+
+	if feed.HasMore {
+		entries = entries[:limit]
+	}
+
+	if len(entries) > 0 {
+		feed.Cursor = *entries[len(entries)-1].Sequence
+	}
+
+	// state is loaded instead of taken from entries, so entity changed several times is sent once
+	for _, ref := range audit.Changed(entries) {
+		feed.Changes = append(feed.Changes, s.syncChange(ref, accountId))
+	}
+
+	return feed
+}
+
+func (s *RelationService) syncChange(ref audit.Ref, accountId int) SyncChange {
+
+	change := SyncChange{
+		Entity: ref.Entity,
+		Id:     ref.Id,
+	}
+
+	var data interface{}
+	found := false
+
+	switch ref.Entity {
+	case audit.EntityProduct:
+		if dto, err := s.GetProduct(ref.Id, accountId); err == nil {
+			data, found = dto, true
+		}
+	case audit.EntityStock:
+		if model, err := s.stockRepository.Get(ref.Id, accountId); err == nil {
+			data, found = stock.ModelToDTO(model), true
+		}
+	case audit.EntityCategory:
+		if model, err := s.categoryRepository.Get(ref.Id, accountId); err == nil {
+			data, found = category.ModelToDTO(model), true
+		}
+	case audit.EntityList:
+		if model, err := s.listRepository.Get(ref.Id, accountId); err == nil {
+			data, found = list.ModelToDTO(model), true
+		}
+	case audit.EntityShoppingItem:
+		if model, err := s.di.ShoppingListItem.Get(ref.Id, accountId); err == nil {
+			data, found = shopping.ItemToDTO(model), true
+		}
+	}
+
+	if !found {
+		change.Deleted = true
+		return change
+	}
+
+	change.Data = data
+
+	return change
+}
+
+func (s *RelationService) syncSnapshot(accountId int) SyncFeed {
+
+	// cursor is taken first, changes made while snapshot is loaded are sent again with the next request
+	feed := SyncFeed{
+		Cursor:  s.di.Audit.LastSequence(),
+		Reset:   true,
+		Changes: []SyncChange{},
+	}
+
+	add := func(entity string, id int, data interface{}) {
+		feed.Changes = append(feed.Changes, SyncChange{Entity: entity, Id: id, Data: data})
+	}
+
+	for _, model := range s.listRepository.GetAll(accountId) {
+		add(audit.EntityList, model.Id, list.ModelToDTO(model))
+	}
+
+	for _, model := range s.categoryRepository.GetAll(accountId) {
+		add(audit.EntityCategory, model.Id, category.ModelToDTO(model))
+	}
+
+	for _, dto := range s.GetAllProducts(nil, accountId) {
+		add(audit.EntityProduct, dto.Id, dto)
+	}
+
+	for _, model := range s.stockRepository.GetAll(accountId) {
+		add(audit.EntityStock, model.Id, stock.ModelToDTO(model))
+	}
+
+	for _, model := range s.di.ShoppingListItem.GetAll(accountId) {
+		add(audit.EntityShoppingItem, model.Id, shopping.ItemToDTO(model))
+	}
+
+	return feed
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"github.com/proviant-io/core/internal/apm"
+	"github.com/proviant-io/core/internal/config"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/di"
+	"github.com/proviant-io/core/internal/logger"
+	"github.com/proviant-io/core/internal/pkg/audit"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"github.com/proviant-io/core/internal/pkg/product_category"
+	"github.com/proviant-io/core/internal/pkg/stock"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func newTestService(t *testing.T) *RelationService {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "service.sqlite"))
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		UserContent: config.UserContent{Mode: config.UserContentModeLocal, Location: t.TempDir()},
+	}
+
+	productRepo, err := product.Setup(d)
+	assert.NoError(t, err)
+
+	stockRepo, err := stock.Setup(d)
+	assert.NoError(t, err)
+
+	categoryRepo, err := category.Setup(d)
+	assert.NoError(t, err)
+
+	listRepo, err := list.Setup(d)
+	assert.NoError(t, err)
+
+	productCategoryRepo, err := product_category.Setup(d)
+	assert.NoError(t, err)
+
+	i, err := di.NewDI(d, &cfg, &apm.NoopApm{}, logger.Default(), "test")
+	assert.NoError(t, err)
+
+	return NewRelationService(productRepo, listRepo, categoryRepo, stockRepo, productCategoryRepo, i, cfg)
+}
+
+func changedIds(feed SyncFeed) []int {
+
+	ids := []int{}
+
+	for _, change := range feed.Changes {
+		ids = append(ids, change.Id)
+	}
+
+	return ids
+}
+
+func TestChanges(t *testing.T) {
+
+	s := newTestService(t)
+
+	fridge := s.CreateList(list.DTO{Title: "Fridge"}, 1, 1)
+	pantry := s.CreateList(list.DTO{Title: "Pantry"}, 1, 1)
+	s.CreateList(list.DTO{Title: "Garage"}, 2, 1)
+
+	feed := s.Changes(0, 0, 1)
+	assert.True(t, feed.Reset)
+	assert.Equal(t, []int{fridge.Id, pantry.Id}, changedIds(feed))
+
+	cursor := feed.Cursor
+
+	feed = s.Changes(cursor, 0, 1)
+	assert.False(t, feed.Reset)
+	assert.Empty(t, feed.Changes)
+	assert.Equal(t, cursor, feed.Cursor)
+
+	cellar := s.CreateList(list.DTO{Title: "Cellar"}, 1, 1)
+	_, err := s.UpdateList(fridge.Id, list.DTO{Title: "Freezer"}, 1, 1)
+	assert.Nil(t, err)
+
+	// changes of another account are skipped
+	s.CreateList(list.DTO{Title: "Attic"}, 2, 1)
+
+	feed = s.Changes(cursor, 1, 1)
+	assert.True(t, feed.HasMore)
+	assert.Equal(t, []int{cellar.Id}, changedIds(feed))
+
+	feed = s.Changes(feed.Cursor, 1, 1)
+	assert.False(t, feed.HasMore)
+	assert.Equal(t, []int{fridge.Id}, changedIds(feed))
+	assert.Equal(t, "Freezer", feed.Changes[0].Data.(list.DTO).Title)
+
+	cursor = feed.Cursor
+
+	assert.Nil(t, s.DeleteList(pantry.Id, 0, 1, 1))
+
+	feed = s.Changes(cursor, 0, 1)
+	assert.Equal(t, []SyncChange{{Entity: audit.EntityList, Id: pantry.Id, Deleted: true}}, feed.Changes)
+
+	feed = s.Changes(feed.Cursor, 0, 2)
+	assert.Empty(t, feed.Changes)
+}
+
+func TestChangesCommittedLate(t *testing.T) {
+
+	s := newTestService(t)
+
+	c := s.di.DB.Connection()
+
+	// entry of transaction which is still open has lower id than the entry committed after it
+	late := audit.Entry{Id: 50, Action: audit.ActionCreate, Entity: audit.EntityList, EntityId: 5, AccountId: 1}
+	early := audit.Entry{Id: 100, Action: audit.ActionCreate, Entity: audit.EntityList, EntityId: 10, AccountId: 1}
+
+	assert.NoError(t, c.Create(&early).Error)
+
+	feed := s.Changes(0, 0, 1)
+	cursor := feed.Cursor
+
+	assert.NoError(t, c.Create(&late).Error)
+
+	feed = s.Changes(cursor, 0, 1)
+	assert.Equal(t, []SyncChange{{Entity: audit.EntityList, Id: 5, Deleted: true}}, feed.Changes)
+	assert.Greater(t, feed.Cursor, cursor)
+}
@@ -0,0 +1,212 @@
+package shopping
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/logger"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+const (
+	EventItemAdded     = "item.added"
+	EventItemUpdated   = "item.updated"
+	EventItemChecked   = "item.checked"
+	EventItemUnchecked = "item.unchecked"
+	EventItemDeleted   = "item.deleted"
+)
+
+// events are kept only for a while, clients which were offline longer should reload whole list
+const EventRetention = 24 * time.Hour
+const pruneEveryPublishes = 100
+
+// Event is persisted in database, so every server instance can stream events published by others
+type Event struct {
+	gorm.Model
+	Id        int    `json:"id" gorm:"primaryKey;autoIncrement;"`
+	ListId    int    `json:"list_id" gorm:"index"`
+	Type      string `json:"type"`
+	Payload   string `json:"payload" gorm:"type:text"`
+	AccountId int    `json:"account_id" gorm:"default:0;index"`
+	// Sequence orders events by commit, it is assigned by Seal once event is committed
+	Sequence *int `json:"sequence" gorm:"uniqueIndex"`
+}
+
+func (Event) TableName() string {
+	return "shopping_list_events"
+}
+
+type EventRepository struct {
+	db db.DB
+
+	mu          sync.Mutex
+	subscribers map[int]map[chan struct{}]struct{}
+	published   int
+}
+
+func (r *EventRepository) Publish(eventType string, item ItemDTO, accountId int) Event {
+
+	payload, err := json.Marshal(item)
+	if err != nil {
+		logger.Default().Error("shopping list event: cannot marshal item", "item_id", item.Id, "error", err)
+	}
+
+	model := Event{
+		ListId:    item.ListId,
+		Type:      eventType,
+		Payload:   string(payload),
+		AccountId: accountId,
+	}
+
+	r.db.Connection().Create(&model)
+
+	r.mu.Lock()
+	for ch := range r.subscribers[item.ListId] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	r.published++
+	prune := r.published%pruneEveryPublishes == 0
+	r.mu.Unlock()
+
+	if prune {
+		r.Prune(time.Now().Add(-EventRetention))
+	}
+
+	return model
+}
+
+// Seal assigns sequence to committed events. Events published inside of transaction, e.g. by batch or sync push,
+// could be committed after events with higher id, so id could not serve as stream cursor, sequence is given
+// in order events become visible instead. Concurrent seals which pick the same sequence are rolled back
+// by unique index, events left without sequence are sealed by the next call.
+func (r *EventRepository) Seal() {
+
+	var ids []int
+	r.db.Connection().Model(&Event{}).Where("sequence is null").Order("id ASC").Pluck("id", &ids)
+
+	if len(ids) == 0 {
+		return
+	}
+
+	err := r.db.Connection().Transaction(func(tx *gorm.DB) error {
+
+		var last int
+		err := tx.Model(&Event{}).Select("coalesce(max(sequence), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			last++
+			err := tx.Model(&Event{}).Where("id = ? and sequence is null", id).UpdateColumn("sequence", last).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logger.Default().Warn("shopping list event: cannot seal events", "error", err)
+	}
+}
+
+// GetAfter returns sealed events of list with sequence after cursor, the oldest first
+func (r *EventRepository) GetAfter(listId, accountId, cursor int, limit int) []Event {
+
+	r.Seal()
+
+	var models []Event
+	r.db.Connection().Where("list_id = ? and account_id = ? and sequence > ?", listId, accountId, cursor).Order("sequence ASC").Limit(limit).Find(&models)
+
+	return models
+}
+
+// LastSequence returns sequence of the latest sealed event, 0 when nothing was published yet. Sequence is shared
+// by lists, so sequence of the latest event overall serves every list.
+func (r *EventRepository) LastSequence() int {
+
+	r.Seal()
+
+	var last int
+	r.db.Connection().Model(&Event{}).Select("coalesce(max(sequence), 0)").Scan(&last)
+
+	return last
+}
+
+// Subscribe returns channel which is notified when this instance publishes into list,
+// events from other instances are discovered by polling GetAfter
+func (r *EventRepository) Subscribe(listId int) (<-chan struct{}, func()) {
+
+	ch := make(chan struct{}, 1)
+
+	r.mu.Lock()
+	if r.subscribers[listId] == nil {
+		r.subscribers[listId] = map[chan struct{}]struct{}{}
+	}
+	r.subscribers[listId][ch] = struct{}{}
+	r.mu.Unlock()
+
+	return ch, func() {
+		r.mu.Lock()
+		delete(r.subscribers[listId], ch)
+		if len(r.subscribers[listId]) == 0 {
+			delete(r.subscribers, listId)
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *EventRepository) Prune(olderThan time.Time) {
+	r.db.Connection().Where("created_at < ?", olderThan).Unscoped().Delete(&Event{})
+}
+
+func (r *EventRepository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Event{})
+}
+
+func (r *EventRepository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Event{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+func (r *EventRepository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Event{})
+	if err != nil {
+		return fmt.Errorf("migration of ShoppingListEvent table failed: %v", err)
+	}
+	return nil
+}
+
+// WithDB returns repository which works through d, e.g. inside of transaction. Its events wake nobody up,
+// streams find them by polling once transaction is committed.
+func (r *EventRepository) WithDB(d db.DB) *EventRepository {
+	return &EventRepository{
+		db:          d,
+		subscribers: map[int]map[chan struct{}]struct{}{},
+	}
+}
+
+func EventSetup(d db.DB) (*EventRepository, error) {
+
+	repo := &EventRepository{
+		subscribers: map[int]map[chan struct{}]struct{}{},
+	}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
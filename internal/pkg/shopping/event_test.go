@@ -0,0 +1,42 @@
+package shopping
+
+import (
+	"github.com/proviant-io/core/internal/db"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetAfterCommittedLate(t *testing.T) {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "events.sqlite"))
+	assert.NoError(t, err)
+
+	r, err := EventSetup(d)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, r.LastSequence())
+
+	// event of transaction which is still open has lower id than the event committed after it
+	late := Event{Id: 50, ListId: 1, Type: EventItemAdded, AccountId: 1}
+	early := Event{Id: 100, ListId: 1, Type: EventItemChecked, AccountId: 1}
+
+	assert.NoError(t, d.Connection().Create(&early).Error)
+
+	events := r.GetAfter(1, 1, 0, 10)
+	assert.Len(t, events, 1)
+	assert.Equal(t, 100, events[0].Id)
+
+	cursor := *events[0].Sequence
+	assert.Equal(t, cursor, r.LastSequence())
+
+	assert.NoError(t, d.Connection().Create(&late).Error)
+
+	events = r.GetAfter(1, 1, cursor, 10)
+	assert.Len(t, events, 1)
+	assert.Equal(t, 50, events[0].Id)
+	assert.Greater(t, *events[0].Sequence, cursor)
+
+	// other accounts do not see events of the list
+	assert.Empty(t, r.GetAfter(1, 2, 0, 10))
+}
@@ -21,7 +21,7 @@ func (List) TableName() string {
 
 type ListDTO struct {
 	Id    int    `json:"id"`
-	Title string `json:"title"`
+	Title string `json:"title" validate:"required,max=255"`
 }
 
 type ListFilledDTO struct {
@@ -55,6 +55,16 @@ func (r *ListRepository) GetAll(accountId int) []List {
 	return models
 }
 
+func (r *ListRepository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&List{})
+}
+
+func (r *ListRepository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&List{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
 func (r *ListRepository) Delete(id int, accountId int) *errors.CustomError {
 
 	model, err := r.Get(id, accountId)
@@ -101,13 +111,18 @@ func ListToDTO(m List) ListDTO {
 
 func (r *ListRepository) Migrate() error {
 	// Migrate the schema
-	err := r.db.Connection().AutoMigrate(&List{})
+	err := db.AutoMigrate(r.db, &List{})
 	if err != nil {
 		return fmt.Errorf("migration of ShoppingList table failed: %v", err)
 	}
 	return nil
 }
 
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *ListRepository) WithDB(d db.DB) *ListRepository {
+	return &ListRepository{db: d}
+}
+
 func ListSetup(d db.DB) (*ListRepository, error) {
 
 	repo := &ListRepository{}
@@ -6,6 +6,7 @@ import (
 	"github.com/proviant-io/core/internal/db"
 	"github.com/proviant-io/core/internal/errors"
 	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/logger"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 	"time"
@@ -24,6 +25,7 @@ type Item struct {
 	Price     decimal.Decimal `json:"price" gorm:"type:decimal(20,2);"`
 	AccountId int             `json:"account_id" gorm:"default:0;index"`
 	ProductId int             `json:"product_id" gorm:"default:0;index"`
+	Version   int             `json:"version" gorm:"default:1;not null"`
 }
 
 func (Item) TableName() string {
@@ -33,15 +35,16 @@ func (Item) TableName() string {
 type ItemDTO struct {
 	ListId    int             `json:"list_id" gorm:"index"`
 	Id        int             `json:"id"`
-	Title     string          `json:"title"`
-	Comment   string          `json:"comment"`
-	Quantity  int             `json:"quantity"`
+	Title     string          `json:"title" validate:"required,max=255"`
+	Comment   string          `json:"comment" validate:"max=1000"`
+	Quantity  int             `json:"quantity" validate:"required,min=1"`
 	Checked   bool            `json:"checked"`
-	DueDate   int             `json:"due_date"`
+	DueDate   int             `json:"due_date" validate:"nonnegative"`
 	CheckedAt int             `json:"checked_at"`
 	UpdatedAt int             `json:"updated_at"`
-	Price     decimal.Decimal `json:"price"`
-	ProductId int             `json:"product_id"`
+	Price     decimal.Decimal `json:"price" validate:"nonnegative"`
+	ProductId int             `json:"product_id" validate:"omitempty,min=1,product"`
+	Version   int             `json:"-"`
 }
 
 type ItemRepository struct {
@@ -71,6 +74,16 @@ func (r *ItemRepository) GetAll(accountId int) []Item {
 	return models
 }
 
+func (r *ItemRepository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Item{})
+}
+
+func (r *ItemRepository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Item{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
 func (r *ItemRepository) GetAllByList(listId int, accountId int) []Item {
 
 	var models []Item
@@ -80,15 +93,27 @@ func (r *ItemRepository) GetAllByList(listId int, accountId int) []Item {
 	return models
 }
 
-func (r *ItemRepository) Delete(id int, accountId int) *errors.CustomError {
+func (r *ItemRepository) GetAllByLists(listIds []int, accountId int) []Item {
 
-	model, err := r.Get(id, accountId)
+	var models []Item
+	query := fmt.Sprintf("list_id IN (?) and account_id = ? and (checked_at is null or UNIX_TIMESTAMP(checked_at) > (UNIX_TIMESTAMP() - 3600*%d))", CheckedShowHours)
+
+	r.db.Connection().Where(query, listIds, accountId).Find(&models)
+	return models
+}
+
+func (r *ItemRepository) Delete(id, version int, accountId int) *errors.CustomError {
+
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return err
 	}
 
-	r.db.Connection().Unscoped().Delete(model, id)
+	if !db.DeleteVersioned(r.db.Connection(), &Item{}, id, accountId, version) {
+		return errors.NewErrPreconditionFailed(i18n.NewMessage("shopping list item with id %d was changed by someone else", id))
+	}
+
 	return nil
 }
 
@@ -105,6 +130,7 @@ func (r *ItemRepository) Create(dto ItemDTO, accountId int) Item {
 		CheckedAt: sql.NullTime{},
 		Price:     dto.Price,
 		ProductId: dto.ProductId,
+		Version:   1,
 	}
 
 	r.db.Connection().Create(&model)
@@ -113,70 +139,58 @@ func (r *ItemRepository) Create(dto ItemDTO, accountId int) Item {
 
 func (r *ItemRepository) Update(id int, dto ItemDTO, accountId int) (Item, *errors.CustomError) {
 
-	model, err := r.Get(id, accountId)
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return Item{}, err
 	}
 
-	model.Title = dto.Title
-	model.Comment = dto.Comment
-	model.Quantity = dto.Quantity
-	model.DueDate = dto.DueDate
-	model.Price = dto.Price
-	model.ProductId = dto.ProductId
-	if dto.Checked {
-		model.Checked = true
-		model.CheckedAt = sql.NullTime{
-			time.Now(),
-			true,
-		}
-	} else {
-		model.Checked = false
-		// should be cleaning
-		model.CheckedAt = sql.NullTime{}
+	values := map[string]interface{}{
+		"title":      dto.Title,
+		"comment":    dto.Comment,
+		"quantity":   dto.Quantity,
+		"due_date":   dto.DueDate,
+		"price":      dto.Price,
+		"product_id": dto.ProductId,
 	}
 
-	r.db.Connection().Model(&Item{Id: id}).Updates(&model)
+	return r.update(id, dto.Version, dto.Checked, values, accountId)
+}
+
+// update writes values together with checked state in a single versioned statement
+func (r *ItemRepository) update(id, version int, checked bool, values map[string]interface{}, accountId int) (Item, *errors.CustomError) {
+
+	values["checked"] = checked
+	values["checked_at"] = nil
+
+	if checked {
+		values["checked_at"] = sql.NullTime{Time: time.Now(), Valid: true}
+	}
 
-	if !model.Checked {
-		r.db.Connection().Model(&model).Select("Checked").Updates(map[string]interface{}{"checked": false})
-		r.db.Connection().Model(&model).Select("CheckedAt").Updates(map[string]interface{}{"checked_at": nil})
+	if !db.UpdateVersioned(r.db.Connection(), &Item{}, id, accountId, version, values) {
+		return Item{}, errors.NewErrPreconditionFailed(i18n.NewMessage("shopping list item with id %d was changed by someone else", id))
 	}
 
-	return model, nil
+	return r.Get(id, accountId)
 }
 
-func (r *ItemRepository) updateChecked(id int, checked bool, accountId int) (Item, *errors.CustomError) {
+func (r *ItemRepository) updateChecked(id, version int, checked bool, accountId int) (Item, *errors.CustomError) {
 
-	model, err := r.Get(id, accountId)
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return Item{}, err
 	}
 
-	model.Checked = checked
-
-	r.db.Connection().Model(&model).Select("Checked").Updates(map[string]interface{}{"checked": model.Checked})
-
-	if checked {
-		r.db.Connection().Model(&model).Select("CheckedAt").Updates(map[string]interface{}{"checked_at": sql.NullTime{
-			time.Now(),
-			true,
-		}})
-	} else {
-		r.db.Connection().Model(&model).Select("CheckedAt").Updates(map[string]interface{}{"checked_at": nil})
-	}
-
-	return model, nil
+	return r.update(id, version, checked, map[string]interface{}{}, accountId)
 }
 
-func (r *ItemRepository) Check(id int, accountId int) (Item, *errors.CustomError) {
-	return r.updateChecked(id, true, accountId)
+func (r *ItemRepository) Check(id, version int, accountId int) (Item, *errors.CustomError) {
+	return r.updateChecked(id, version, true, accountId)
 }
 
-func (r *ItemRepository) Uncheck(id int, accountId int) (Item, *errors.CustomError) {
-	return r.updateChecked(id, false, accountId)
+func (r *ItemRepository) Uncheck(id, version int, accountId int) (Item, *errors.CustomError) {
+	return r.updateChecked(id, version, false, accountId)
 }
 
 func ItemToDTO(m Item) ItemDTO {
@@ -196,19 +210,25 @@ func ItemToDTO(m Item) ItemDTO {
 		UpdatedAt: int(updatedAt.Time.Unix()),
 		Price:     m.Price,
 		ProductId: m.ProductId,
+		Version:   m.Version,
 	}
 }
 
 func (r *ItemRepository) Migrate() error {
 	// Migrate the schema
-	err := r.db.Connection().AutoMigrate(&Item{})
-	fmt.Println("Migrate: shopping.ItemRepository")
+	err := db.AutoMigrate(r.db, &Item{})
+	logger.Default().Debug("migrate", "repository", "shopping.ItemRepository")
 	if err != nil {
 		return fmt.Errorf("migration of ShoppingList table failed: %v", err)
 	}
 	return nil
 }
 
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *ItemRepository) WithDB(d db.DB) *ItemRepository {
+	return &ItemRepository{db: d}
+}
+
 func ItemSetup(d db.DB) (*ItemRepository, error) {
 
 	repo := &ItemRepository{}
@@ -15,18 +15,20 @@ type Stock struct {
 	Quantity  uint `json:"quantity"`
 	Expire    int  `json:"expire"`
 	AccountId int  `json:"account_id" gorm:"default:0;index"`
+	Version   int  `json:"version" gorm:"default:1;not null"`
 }
 
 type DTO struct {
 	Id        int  `json:"id"`
+	Version   int  `json:"-"`
 	ProductId int  `json:"product_id"`
-	Quantity  uint `json:"quantity"`
-	Expire    int  `json:"expire"`
+	Quantity  uint `json:"quantity" validate:"required"`
+	Expire    int  `json:"expire" validate:"nonnegative"`
 }
 
 type ConsumeDTO struct {
 	ProductId int  `json:"product_id"`
-	Quantity  uint `json:"quantity"`
+	Quantity  uint `json:"quantity" validate:"required"`
 }
 
 type Repository struct {
@@ -53,19 +55,62 @@ func (r *Repository) GetAllByProductId(id int, accountId int) []Stock {
 	return s
 }
 
+func (r *Repository) GetAllByProductIds(ids []int, accountId int) []Stock {
+
+	var s []Stock
+	r.db.Connection().Where("product_id IN (?) and account_id = ?", ids, accountId).Order("expire ASC").Find(&s)
+
+	return s
+}
+
+// GetExpiring returns lots with expiration date set and not later than until
+func (r *Repository) GetExpiring(accountId int, until int64) []Stock {
+
+	var s []Stock
+	r.db.Connection().Where("account_id = ? and expire > 0 and expire <= ?", accountId, until).Order("expire ASC").Find(&s)
+
+	return s
+}
+
+// CountExpiringPerAccount returns number of lots of every account with expiration date set and not later than until
+func (r *Repository) CountExpiringPerAccount(until int64) map[int]int64 {
+	return db.CountPerAccount(r.db.Connection().Model(&Stock{}).Where("expire > 0 and expire <= ?", until))
+}
+
+func (r *Repository) GetAll(accountId int) []Stock {
+
+	var s []Stock
+	r.db.Connection().Where("account_id = ?", accountId).Find(&s)
+
+	return s
+}
+
 func (r *Repository) DeleteByProductId(id int, accountId int) {
 	r.db.Connection().Where("product_id = ? and account_id = ?", id, accountId).Unscoped().Delete(&Stock{})
 }
 
-func (r *Repository) Delete(id int, accountId int) *errors.CustomError {
+func (r *Repository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Stock{})
+}
+
+func (r *Repository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Stock{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
 
-	model, err := r.Get(id, accountId)
+func (r *Repository) Delete(id, version int, accountId int) *errors.CustomError {
+
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return errors.NewErrNotFound(i18n.NewMessage("stock with id %d not found", id))
 	}
 
-	r.db.Connection().Unscoped().Delete(model, id)
+	if !db.DeleteVersioned(r.db.Connection(), &Stock{}, id, accountId, version) {
+		return errors.NewErrPreconditionFailed(i18n.NewMessage("stock with id %d was changed by someone else", id))
+	}
+
 	return nil
 }
 
@@ -82,7 +127,7 @@ func (r *Repository) Consume(dto ConsumeDTO, accountId int) uint {
 		if model.Quantity <= quantityLeftToConsume {
 			quantityLeftToConsume -= model.Quantity
 			consumed += model.Quantity
-			r.Delete(model.Id, accountId)
+			r.Delete(model.Id, 0, accountId)
 		} else {
 			model.Quantity -= quantityLeftToConsume
 			consumed += quantityLeftToConsume
@@ -113,6 +158,7 @@ func (r *Repository) Create(dto DTO, accountId int) Stock {
 		ProductId: dto.ProductId,
 		Expire:    dto.Expire,
 		AccountId: accountId,
+		Version:   1,
 	}
 
 	r.db.Connection().Create(&model)
@@ -120,24 +166,29 @@ func (r *Repository) Create(dto DTO, accountId int) Stock {
 	return model
 }
 
-func (r *Repository) Update(id int, dto DTO, accountId int) (Stock, error) {
+func (r *Repository) Update(id int, dto DTO, accountId int) (Stock, *errors.CustomError) {
 
-	model, err := r.Get(id, accountId)
+	_, err := r.Get(id, accountId)
 
 	if err != nil {
 		return Stock{}, err
 	}
 
-	model.Quantity = dto.Quantity
-	model.Expire = dto.Expire
+	updated := db.UpdateVersioned(r.db.Connection(), &Stock{}, id, accountId, dto.Version, map[string]interface{}{
+		"quantity": dto.Quantity,
+		"expire":   dto.Expire,
+	})
 
-	r.db.Connection().Model(&Stock{Id: id}).Updates(&model)
-	return model, nil
+	if !updated {
+		return Stock{}, errors.NewErrPreconditionFailed(i18n.NewMessage("stock with id %d was changed by someone else", id))
+	}
+
+	return r.Get(id, accountId)
 }
 
 func (r *Repository) Migrate() error {
 	// Migrate the schema
-	err := r.db.Connection().AutoMigrate(&Stock{})
+	err := db.AutoMigrate(r.db, &Stock{})
 	if err != nil {
 		return fmt.Errorf("migration of Stock table failed: %v", err)
 	}
@@ -147,12 +198,18 @@ func (r *Repository) Migrate() error {
 func ModelToDTO(m Stock) DTO {
 	return DTO{
 		Id:        m.Id,
+		Version:   m.Version,
 		Quantity:  m.Quantity,
 		ProductId: m.ProductId,
 		Expire:    m.Expire,
 	}
 }
 
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *Repository) WithDB(d db.DB) *Repository {
+	return &Repository{db: d}
+}
+
 func Setup(d db.DB) (*Repository, error) {
 
 	repo := &Repository{}
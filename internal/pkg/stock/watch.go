@@ -0,0 +1,116 @@
+package stock
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	ChangeAdded    = "added"
+	ChangeConsumed = "consumed"
+	ChangeUpdated  = "updated"
+	ChangeDeleted  = "deleted"
+)
+
+// subscribers which do not read changes in time are dropped instead of blocking the publisher
+const watchBuffer = 64
+
+// Change describes how stock of product changed, Stock is product stock after the change
+type Change struct {
+	Type      string
+	ProductId int
+	LotId     int
+	Quantity  uint
+	Stock     uint
+	AccountId int
+	ChangedAt int64
+}
+
+// Watcher fans stock changes out to subscribers of account. Changes are kept in memory,
+// so subscribers get changes made through this instance only.
+type Watcher struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan Change]struct{}
+
+	// buffered watcher keeps changes until they are flushed to parent
+	parent  *Watcher
+	pending []Change
+}
+
+func (w *Watcher) Publish(change Change) {
+
+	if change.ChangedAt == 0 {
+		change.ChangedAt = time.Now().Unix()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.parent != nil {
+		w.pending = append(w.pending, change)
+		return
+	}
+
+	for ch := range w.subscribers[change.AccountId] {
+		select {
+		case ch <- change:
+		default:
+			// closed channel tells subscriber it missed changes and should load stock again
+			w.remove(change.AccountId, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns channel of account changes and function which cancels subscription
+func (w *Watcher) Subscribe(accountId int) (<-chan Change, func()) {
+
+	ch := make(chan Change, watchBuffer)
+
+	w.mu.Lock()
+	if w.subscribers[accountId] == nil {
+		w.subscribers[accountId] = map[chan Change]struct{}{}
+	}
+	w.subscribers[accountId][ch] = struct{}{}
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		if _, ok := w.subscribers[accountId][ch]; ok {
+			w.remove(accountId, ch)
+			close(ch)
+		}
+		w.mu.Unlock()
+	}
+}
+
+// Buffered returns watcher which holds changes back until Flush, it is used for changes made in transaction
+func (w *Watcher) Buffered() *Watcher {
+	return &Watcher{parent: w}
+}
+
+// Flush publishes held changes to parent watcher, it is called once transaction is committed
+func (w *Watcher) Flush() {
+
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	for _, change := range pending {
+		w.parent.Publish(change)
+	}
+}
+
+func (w *Watcher) remove(accountId int, ch chan Change) {
+	delete(w.subscribers[accountId], ch)
+	if len(w.subscribers[accountId]) == 0 {
+		delete(w.subscribers, accountId)
+	}
+}
+
+func NewWatcher() *Watcher {
+	return &Watcher{
+		subscribers: map[int]map[chan Change]struct{}{},
+	}
+}
@@ -0,0 +1,61 @@
+package stock
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWatcherDeliversChangesOfAccount(t *testing.T) {
+
+	w := NewWatcher()
+
+	changes, cancel := w.Subscribe(1)
+	defer cancel()
+
+	w.Publish(Change{Type: ChangeAdded, ProductId: 5, Quantity: 2, Stock: 2, AccountId: 2})
+	w.Publish(Change{Type: ChangeAdded, ProductId: 3, Quantity: 2, Stock: 2, AccountId: 1})
+
+	change := <-changes
+
+	assert.Equal(t, 3, change.ProductId)
+	assert.NotZero(t, change.ChangedAt)
+	assert.Empty(t, changes)
+}
+
+func TestWatcherDropsSlowSubscriber(t *testing.T) {
+
+	w := NewWatcher()
+
+	changes, cancel := w.Subscribe(1)
+
+	for i := 0; i <= watchBuffer; i++ {
+		w.Publish(Change{Type: ChangeConsumed, ProductId: 1, AccountId: 1})
+	}
+
+	received := 0
+	for range changes {
+		received++
+	}
+
+	assert.Equal(t, watchBuffer, received)
+
+	// cancel of dropped subscription is safe
+	cancel()
+}
+
+func TestBufferedWatcherPublishesOnFlush(t *testing.T) {
+
+	w := NewWatcher()
+
+	changes, cancel := w.Subscribe(1)
+	defer cancel()
+
+	buffered := w.Buffered()
+	buffered.Publish(Change{Type: ChangeAdded, ProductId: 1, AccountId: 1})
+
+	assert.Empty(t, changes)
+
+	buffered.Flush()
+
+	assert.Len(t, changes, 1)
+}
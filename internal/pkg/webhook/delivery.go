@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"gorm.io/gorm"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+type Delivery struct {
+	gorm.Model
+	Id             int    `json:"id" gorm:"primaryKey;autoIncrement;"`
+	SubscriptionId int    `json:"subscription_id" gorm:"index"`
+	Event          string `json:"event"`
+	EventId        string `json:"event_id"`
+	DedupeKey      string `json:"dedupe_key" gorm:"index;size:191"`
+	Payload        string `json:"payload" gorm:"type:text"`
+	Status         string `json:"status" gorm:"index"`
+	Attempts       int    `json:"attempts"`
+	NextAttemptAt  int64  `json:"next_attempt_at" gorm:"index"`
+	ResponseCode   int    `json:"response_code"`
+	LastError      string `json:"last_error" gorm:"type:text"`
+	DeliveredAt    int64  `json:"delivered_at"`
+	AccountId      int    `json:"account_id" gorm:"default:0;index"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+type DeliveryDTO struct {
+	Id             int             `json:"id"`
+	SubscriptionId int             `json:"subscription_id"`
+	Event          string          `json:"event"`
+	EventId        string          `json:"event_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  int64           `json:"next_attempt_at"`
+	ResponseCode   int             `json:"response_code"`
+	LastError      string          `json:"last_error"`
+	DeliveredAt    int64           `json:"delivered_at"`
+}
+
+type DeliveryRepository struct {
+	db db.DB
+}
+
+func (r *DeliveryRepository) Get(id int, accountId int) (Delivery, *errors.CustomError) {
+
+	model := &Delivery{}
+
+	r.db.Connection().First(model, "id = ? and account_id = ?", id, accountId)
+
+	if (*model).Id == 0 {
+		return Delivery{}, errors.NewErrNotFound(i18n.NewMessage("webhook delivery with id %d not found", id))
+	}
+
+	return *model, nil
+}
+
+func (r *DeliveryRepository) GetAllBySubscriptionId(subscriptionId int, accountId int, limit int) []Delivery {
+
+	var models []Delivery
+	r.db.Connection().Where("subscription_id = ? and account_id = ?", subscriptionId, accountId).Order("id DESC").Limit(limit).Find(&models)
+
+	return models
+}
+
+// GetDue returns pending deliveries which should be attempted now
+func (r *DeliveryRepository) GetDue(now int64, limit int) []Delivery {
+
+	var models []Delivery
+	r.db.Connection().Where("status = ? and next_attempt_at <= ?", DeliveryStatusPending, now).Order("next_attempt_at ASC").Limit(limit).Find(&models)
+
+	return models
+}
+
+// Claim moves next attempt forward only if delivery is still due and nobody else did it yet,
+// so concurrent replicas don't send the same delivery
+func (r *DeliveryRepository) Claim(model Delivery, now, leaseUntil int64) bool {
+
+	result := r.db.Connection().Model(&Delivery{}).
+		Where("id = ? and status = ? and next_attempt_at = ? and next_attempt_at <= ?", model.Id, DeliveryStatusPending, model.NextAttemptAt, now).
+		Update("next_attempt_at", leaseUntil)
+
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func (r *DeliveryRepository) ExistsByDedupeKey(subscriptionId int, dedupeKey string) bool {
+
+	var count int64
+	r.db.Connection().Model(&Delivery{}).Where("subscription_id = ? and dedupe_key = ?", subscriptionId, dedupeKey).Count(&count)
+
+	return count > 0
+}
+
+func (r *DeliveryRepository) Create(model Delivery) Delivery {
+	r.db.Connection().Create(&model)
+	return model
+}
+
+func (r *DeliveryRepository) Save(model Delivery) Delivery {
+	r.db.Connection().Model(&Delivery{Id: model.Id}).
+		Select("Status", "Attempts", "NextAttemptAt", "ResponseCode", "LastError", "DeliveredAt").
+		Updates(&model)
+	return model
+}
+
+func (r *DeliveryRepository) DeleteBySubscriptionId(subscriptionId int, accountId int) {
+	r.db.Connection().Where("subscription_id = ? and account_id = ?", subscriptionId, accountId).Unscoped().Delete(&Delivery{})
+}
+
+func (r *DeliveryRepository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Delivery{})
+}
+
+func (r *DeliveryRepository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Delivery{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+func DeliveryToDTO(m Delivery) DeliveryDTO {
+
+	payload := json.RawMessage(m.Payload)
+	if m.Payload == "" {
+		payload = json.RawMessage("null")
+	}
+
+	return DeliveryDTO{
+		Id:             m.Id,
+		SubscriptionId: m.SubscriptionId,
+		Event:          m.Event,
+		EventId:        m.EventId,
+		Payload:        payload,
+		Status:         m.Status,
+		Attempts:       m.Attempts,
+		NextAttemptAt:  m.NextAttemptAt,
+		ResponseCode:   m.ResponseCode,
+		LastError:      m.LastError,
+		DeliveredAt:    m.DeliveredAt,
+	}
+}
+
+func (r *DeliveryRepository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Delivery{})
+	if err != nil {
+		return fmt.Errorf("migration of WebhookDelivery table failed: %v", err)
+	}
+	return nil
+}
+
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *DeliveryRepository) WithDB(d db.DB) *DeliveryRepository {
+	return &DeliveryRepository{db: d}
+}
+
+func DeliverySetup(d db.DB) (*DeliveryRepository, error) {
+
+	repo := &DeliveryRepository{}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// deniedNetworks are never called by webhooks, subscriber could point them to services of the host otherwise
+var deniedNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"224.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+
+	networks := []*net.IPNet{}
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// denied tells whether webhook must not be sent to ip
+func denied(ip net.IP) bool {
+
+	for _, network := range deniedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// denyPrivate is called with address host name was resolved to right before connection is made,
+// so name which resolves to private address is refused as well as the address itself
+func denyPrivate(_, address string, _ syscall.RawConn) error {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+
+	if ip == nil || denied(ip) {
+		return fmt.Errorf("webhook to address %s is not allowed", host)
+	}
+
+	return nil
+}
+
+// newClient returns client which refuses loopback, link-local and private addresses, redirects included.
+// Proxy is not used, it would be checked instead of the address webhook is sent to.
+func newClient() *http.Client {
+
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: denyPrivate,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+	}
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDenyPrivate(t *testing.T) {
+
+	for _, address := range []string{"127.0.0.1:80", "10.1.2.3:443", "172.20.0.5:80", "192.168.1.10:8123", "169.254.169.254:80", "[::1]:80", "[fe80::1]:80", "[::ffff:192.168.1.1]:80", "0.0.0.0:80"} {
+		assert.Error(t, denyPrivate("tcp", address, nil), address)
+	}
+
+	for _, address := range []string{"93.184.216.34:443", "[2606:2800:220:1:248:1893:25c8:1946]:443"} {
+		assert.NoError(t, denyPrivate("tcp", address, nil), address)
+	}
+}
+
+func TestClientRefusesLoopback(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := newClient().Post(server.URL, "application/json", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not allowed")
+}
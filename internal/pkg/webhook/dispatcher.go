@@ -0,0 +1,341 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/health"
+	"github.com/proviant-io/core/internal/logger"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const MaxAttempts = 8
+const backoffBase = 30 * time.Second
+const backoffMax = 6 * time.Hour
+
+const pollInterval = 5 * time.Second
+const expiryScanInterval = time.Hour
+const deliveryTimeout = 10 * time.Second
+const deliveryBatch = 50
+
+// stuckAfter is longer than round in which every delivery of batch times out
+const stuckAfter = pollInterval + 2*deliveryBatch*deliveryTimeout
+
+const (
+	HeaderEvent     = "X-Proviant-Event"
+	HeaderDelivery  = "X-Proviant-Delivery"
+	HeaderSignature = "X-Proviant-Signature"
+)
+
+// Envelope is the body of every webhook request
+type Envelope struct {
+	Id        string      `json:"id"`
+	Event     string      `json:"event"`
+	AccountId int         `json:"account_id"`
+	CreatedAt int64       `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type Dispatcher struct {
+	subscriptions *SubscriptionRepository
+	deliveries    *DeliveryRepository
+	client        *http.Client
+	wake          chan struct{}
+	now           func() time.Time
+
+	// parent is set for dispatcher working inside of transaction, it is woken up once transaction is committed
+	parent *Dispatcher
+	mu     sync.Mutex
+	woken  bool
+}
+
+func (d *Dispatcher) Subscriptions() *SubscriptionRepository {
+	return d.subscriptions
+}
+
+func (d *Dispatcher) Deliveries() *DeliveryRepository {
+	return d.deliveries
+}
+
+// Emit queues event for every active subscription of account
+func (d *Dispatcher) Emit(event string, data interface{}, accountId int) {
+	for _, sub := range d.subscriptions.GetActiveByAccountAndEvent(accountId, event) {
+		d.EmitTo(sub, event, "", data)
+	}
+}
+
+// EmitTo queues event for single subscription, non empty dedupeKey guarantees event is queued only once
+func (d *Dispatcher) EmitTo(sub Subscription, event, dedupeKey string, data interface{}) {
+
+	if dedupeKey != "" && d.deliveries.ExistsByDedupeKey(sub.Id, dedupeKey) {
+		return
+	}
+
+	now := d.now()
+
+	envelope := Envelope{
+		Id:        uuid.New().String(),
+		Event:     event,
+		AccountId: sub.AccountId,
+		CreatedAt: now.Unix(),
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		logger.Default().Error("webhook: cannot marshal payload", "event", event, "error", err)
+		return
+	}
+
+	d.deliveries.Create(Delivery{
+		SubscriptionId: sub.Id,
+		Event:          event,
+		EventId:        envelope.Id,
+		DedupeKey:      dedupeKey,
+		Payload:        string(payload),
+		Status:         DeliveryStatusPending,
+		NextAttemptAt:  now.Unix(),
+		AccountId:      sub.AccountId,
+	})
+
+	d.notify()
+}
+
+// Replay queues a copy of already existing delivery, original entry stays in the log untouched
+func (d *Dispatcher) Replay(deliveryId int, accountId int) (Delivery, *errors.CustomError) {
+
+	original, err := d.deliveries.Get(deliveryId, accountId)
+
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	_, err = d.subscriptions.Get(original.SubscriptionId, accountId)
+
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	replay := d.deliveries.Create(Delivery{
+		SubscriptionId: original.SubscriptionId,
+		Event:          original.Event,
+		EventId:        original.EventId,
+		Payload:        original.Payload,
+		Status:         DeliveryStatusPending,
+		NextAttemptAt:  d.now().Unix(),
+		AccountId:      original.AccountId,
+	})
+
+	d.notify()
+
+	return replay, nil
+}
+
+// Start runs delivery loop in background, expiryScan is called periodically to emit expiry events.
+// Loop beats into workers on every round, so readiness probe notices when it is stuck.
+func (d *Dispatcher) Start(expiryScan func(), workers *health.Registry) {
+
+	worker := workers.Worker("webhook", stuckAfter)
+
+	go func() {
+		poll := time.NewTicker(pollInterval)
+		expiry := time.NewTicker(expiryScanInterval)
+		defer poll.Stop()
+		defer expiry.Stop()
+
+		if expiryScan != nil {
+			expiryScan()
+		}
+
+		for {
+			worker.Beat()
+
+			select {
+			case <-poll.C:
+				d.deliverDue()
+			case <-d.wake:
+				d.deliverDue()
+			case <-expiry.C:
+				if expiryScan != nil {
+					expiryScan()
+				}
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) notify() {
+
+	if d.parent != nil {
+		d.mu.Lock()
+		d.woken = true
+		d.mu.Unlock()
+		return
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) deliverDue() {
+
+	for _, delivery := range d.deliveries.GetDue(d.now().Unix(), deliveryBatch) {
+
+		// lease delivery for the time of the request, previous requests of batch could take a while,
+		// so lease starts when delivery is claimed
+		now := d.now()
+
+		if !d.deliveries.Claim(delivery, now.Unix(), now.Add(deliveryTimeout*2).Unix()) {
+			continue
+		}
+
+		d.deliver(delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(delivery Delivery) {
+
+	sub, customErr := d.subscriptions.Get(delivery.SubscriptionId, delivery.AccountId)
+
+	if customErr != nil {
+		delivery.Status = DeliveryStatusFailed
+		delivery.LastError = customErr.Error()
+		d.deliveries.Save(delivery)
+		return
+	}
+
+	delivery.Attempts++
+
+	code, err := d.send(sub, delivery)
+
+	delivery.ResponseCode = code
+
+	if err == nil {
+		delivery.Status = DeliveryStatusDelivered
+		delivery.DeliveredAt = d.now().Unix()
+		delivery.LastError = ""
+		d.deliveries.Save(delivery)
+		return
+	}
+
+	delivery.LastError = err.Error()
+
+	if delivery.Attempts >= MaxAttempts {
+		delivery.Status = DeliveryStatusFailed
+	} else {
+		delivery.NextAttemptAt = d.now().Add(Backoff(delivery.Attempts)).Unix()
+	}
+
+	d.deliveries.Save(delivery)
+}
+
+func (d *Dispatcher) send(sub Subscription, delivery Delivery) (int, error) {
+
+	body := []byte(delivery.Payload)
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, sub.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "proviant-webhook")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.EventId)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign produces signature header value: receivers should compute HMAC-SHA256 of "<t>.<body>" with subscription secret
+func Sign(secret string, timestamp int64, body []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Backoff returns delay before next attempt, it doubles with every failed attempt
+func Backoff(attempt int) time.Duration {
+
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := backoffBase
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= backoffMax {
+			return backoffMax
+		}
+	}
+
+	return delay
+}
+
+// WithDB returns dispatcher which queues deliveries through d, e.g. inside of transaction.
+// Worker of the original dispatcher is not woken up until Flush, deliveries could not be seen before commit.
+func (d *Dispatcher) WithDB(db db.DB) *Dispatcher {
+	return &Dispatcher{
+		subscriptions: d.subscriptions.WithDB(db),
+		deliveries:    d.deliveries.WithDB(db),
+		client:        d.client,
+		wake:          d.wake,
+		now:           d.now,
+		parent:        d,
+	}
+}
+
+// Flush wakes up worker if deliveries were queued, it is called once transaction is committed
+func (d *Dispatcher) Flush() {
+
+	if d.parent == nil {
+		return
+	}
+
+	d.mu.Lock()
+	woken := d.woken
+	d.woken = false
+	d.mu.Unlock()
+
+	if woken {
+		d.parent.notify()
+	}
+}
+
+func NewDispatcher(subscriptions *SubscriptionRepository, deliveries *DeliveryRepository) *Dispatcher {
+	return &Dispatcher{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		client:        newClient(),
+		wake:          make(chan struct{}, 1),
+		now:           time.Now,
+	}
+}
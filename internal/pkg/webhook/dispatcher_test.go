@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"github.com/proviant-io/core/internal/db"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+
+	signature := Sign("secret", 1609458959, []byte(`{"event":"stock.added"}`))
+
+	assert.Equal(t, "t=1609458959,v1=38266533c2b7d2e60fa60146ee6520845297f06f41af8b69782b80d478d36645", signature)
+	assert.NotEqual(t, signature, Sign("other", 1609458959, []byte(`{"event":"stock.added"}`)))
+}
+
+func TestBackoff(t *testing.T) {
+
+	assert.Equal(t, 30*time.Second, Backoff(0))
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, 6*time.Hour, Backoff(20))
+}
+
+func TestSubscribed(t *testing.T) {
+
+	sub := Subscription{Events: "stock.added,stock.consumed"}
+
+	assert.True(t, sub.Subscribed(EventStockAdded))
+	assert.False(t, sub.Subscribed(EventStockDeleted))
+}
+
+func TestClaim(t *testing.T) {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "webhook.sqlite"))
+	assert.NoError(t, err)
+
+	deliveries, err := DeliverySetup(d)
+	assert.NoError(t, err)
+
+	delivery := deliveries.Create(Delivery{Status: DeliveryStatusPending, NextAttemptAt: 1000, AccountId: 1})
+
+	// delivery which is not due anymore, e.g. other replica claimed and retried it meanwhile, is skipped
+	assert.False(t, deliveries.Claim(delivery, 999, 1020))
+
+	assert.True(t, deliveries.Claim(delivery, 1000, 1020))
+	assert.False(t, deliveries.Claim(delivery, 1000, 1020))
+
+	claimed, customErr := deliveries.Get(delivery.Id, 1)
+	assert.Nil(t, customErr)
+	assert.Equal(t, int64(1020), claimed.NextAttemptAt)
+}
+
+func TestMaskSecret(t *testing.T) {
+
+	assert.Equal(t, "************************************************************e3f4", MaskSecret("0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e3f4"))
+	assert.Equal(t, "******", MaskSecret("secret"))
+	assert.Equal(t, "", MaskSecret(""))
+
+	sub := SubscriptionToDTO(Subscription{Secret: "0a1b2c3d4e5f60718293a4b5c6d7e8f9"})
+	assert.Equal(t, "****************************e8f9", sub.Secret)
+}
+
+func TestUpdateKeepsMaskedSecret(t *testing.T) {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "webhook.sqlite"))
+	assert.NoError(t, err)
+
+	subscriptions, err := SubscriptionSetup(d)
+	assert.NoError(t, err)
+
+	sub, customErr := subscriptions.Create(SubscriptionDTO{Url: "https://example.com/hook", Events: []string{EventStockAdded}}, 1)
+	assert.Nil(t, customErr)
+
+	dto := SubscriptionToDTO(sub)
+	dto.Active = true
+
+	updated, customErr := subscriptions.Update(sub.Id, dto, 1)
+	assert.Nil(t, customErr)
+	assert.Equal(t, sub.Secret, updated.Secret)
+
+	dto.Secret = "rotated"
+
+	updated, customErr = subscriptions.Update(sub.Id, dto, 1)
+	assert.Nil(t, customErr)
+	assert.Equal(t, "rotated", updated.Secret)
+}
+
+func TestWakeAfterCommit(t *testing.T) {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "webhook.sqlite"))
+	assert.NoError(t, err)
+
+	subscriptions, err := SubscriptionSetup(d)
+	assert.NoError(t, err)
+
+	deliveries, err := DeliverySetup(d)
+	assert.NoError(t, err)
+
+	dispatcher := NewDispatcher(subscriptions, deliveries)
+
+	_, customErr := subscriptions.Create(SubscriptionDTO{Url: "https://example.com/hook", Events: []string{EventStockAdded}, Active: true}, 1)
+	assert.Nil(t, customErr)
+
+	tx := dispatcher.WithDB(d)
+	tx.Emit(EventStockAdded, nil, 1)
+
+	select {
+	case <-dispatcher.wake:
+		t.Fatal("worker was woken up before commit")
+	default:
+	}
+
+	tx.Flush()
+
+	select {
+	case <-dispatcher.wake:
+	default:
+		t.Fatal("worker was not woken up after commit")
+	}
+}
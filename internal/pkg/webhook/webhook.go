@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"gorm.io/gorm"
+	"strings"
+)
+
+const (
+	EventStockAdded          = "stock.added"
+	EventStockConsumed       = "stock.consumed"
+	EventStockDeleted        = "stock.deleted"
+	EventStockExpiring       = "stock.expiring"
+	EventProductCreated      = "product.created"
+	EventProductUpdated      = "product.updated"
+	EventShoppingItemChecked = "shopping_item.checked"
+)
+
+var Events = []string{
+	EventStockAdded,
+	EventStockConsumed,
+	EventStockDeleted,
+	EventStockExpiring,
+	EventProductCreated,
+	EventProductUpdated,
+	EventShoppingItemChecked,
+}
+
+const DefaultExpiryThresholdDays = 3
+
+type Subscription struct {
+	gorm.Model
+	Id                  int    `json:"id" gorm:"primaryKey;autoIncrement;"`
+	Url                 string `json:"url"`
+	Secret              string `json:"secret"`
+	Events              string `json:"events"`
+	Active              bool   `json:"active"`
+	ExpiryThresholdDays int    `json:"expiry_threshold_days" gorm:"default:0"`
+	AccountId           int    `json:"account_id" gorm:"default:0;index"`
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (s Subscription) Subscribed(event string) bool {
+	for _, e := range strings.Split(s.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscriptionDTO carries secret in full only in response to creation, it is masked everywhere else
+type SubscriptionDTO struct {
+	Id                  int      `json:"id"`
+	Url                 string   `json:"url" validate:"required,url"`
+	Secret              string   `json:"secret"`
+	Events              []string `json:"events" validate:"required,webhook_events"`
+	Active              bool     `json:"active"`
+	ExpiryThresholdDays int      `json:"expiry_threshold_days" validate:"nonnegative"`
+}
+
+type SubscriptionRepository struct {
+	db db.DB
+}
+
+func (r *SubscriptionRepository) Get(id int, accountId int) (Subscription, *errors.CustomError) {
+
+	model := &Subscription{}
+
+	r.db.Connection().First(model, "id = ? and account_id = ?", id, accountId)
+
+	if (*model).Id == 0 {
+		return Subscription{}, errors.NewErrNotFound(i18n.NewMessage("webhook with id %d not found", id))
+	}
+
+	return *model, nil
+}
+
+func (r *SubscriptionRepository) GetAll(accountId int) []Subscription {
+
+	var models []Subscription
+	r.db.Connection().Where("account_id = ?", accountId).Find(&models)
+
+	return models
+}
+
+// GetActiveByEvent returns active subscriptions of every account which listen to event
+func (r *SubscriptionRepository) GetActiveByEvent(event string) []Subscription {
+
+	var models []Subscription
+	r.db.Connection().Where("active = ? and events like ?", true, "%"+event+"%").Find(&models)
+
+	var filtered []Subscription
+	for _, model := range models {
+		if model.Subscribed(event) {
+			filtered = append(filtered, model)
+		}
+	}
+
+	return filtered
+}
+
+func (r *SubscriptionRepository) GetActiveByAccountAndEvent(accountId int, event string) []Subscription {
+
+	var filtered []Subscription
+	for _, model := range r.GetActiveByEvent(event) {
+		if model.AccountId == accountId {
+			filtered = append(filtered, model)
+		}
+	}
+
+	return filtered
+}
+
+func (r *SubscriptionRepository) Delete(id int, accountId int) *errors.CustomError {
+
+	model, err := r.Get(id, accountId)
+
+	if err != nil {
+		return err
+	}
+
+	r.db.Connection().Unscoped().Delete(model, id)
+	return nil
+}
+
+func (r *SubscriptionRepository) Create(dto SubscriptionDTO, accountId int) (Subscription, *errors.CustomError) {
+
+	if dto.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return Subscription{}, errors.NewInternalServer(i18n.NewMessage(err.Error()))
+		}
+		dto.Secret = secret
+	}
+
+	model := Subscription{
+		Url:                 dto.Url,
+		Secret:              dto.Secret,
+		Events:              strings.Join(dto.Events, ","),
+		Active:              dto.Active,
+		ExpiryThresholdDays: dto.ExpiryThresholdDays,
+		AccountId:           accountId,
+	}
+
+	r.db.Connection().Create(&model)
+	return model, nil
+}
+
+func (r *SubscriptionRepository) Update(id int, dto SubscriptionDTO, accountId int) (Subscription, *errors.CustomError) {
+
+	model, err := r.Get(id, accountId)
+
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	model.Url = dto.Url
+	model.Events = strings.Join(dto.Events, ",")
+	model.Active = dto.Active
+	model.ExpiryThresholdDays = dto.ExpiryThresholdDays
+
+	// masked secret is sent back by clients which update what they got
+	if dto.Secret != "" && dto.Secret != MaskSecret(model.Secret) {
+		model.Secret = dto.Secret
+	}
+
+	r.db.Connection().Model(&Subscription{Id: id}).Select("Url", "Events", "Active", "ExpiryThresholdDays", "Secret").Updates(&model)
+	return model, nil
+}
+
+func (r *SubscriptionRepository) DeleteByAccountId(accountId int) {
+	r.db.Connection().Where("account_id = ?", accountId).Unscoped().Delete(&Subscription{})
+}
+
+func (r *SubscriptionRepository) CountByAccountId(accountId int) int64 {
+	var count int64
+	r.db.Connection().Unscoped().Model(&Subscription{}).Where("account_id = ?", accountId).Count(&count)
+	return count
+}
+
+func SubscriptionToDTO(m Subscription) SubscriptionDTO {
+
+	events := []string{}
+	if m.Events != "" {
+		events = strings.Split(m.Events, ",")
+	}
+
+	return SubscriptionDTO{
+		Id:                  m.Id,
+		Url:                 m.Url,
+		Secret:              MaskSecret(m.Secret),
+		Events:              events,
+		Active:              m.Active,
+		ExpiryThresholdDays: m.ExpiryThresholdDays,
+	}
+}
+
+// MaskSecret keeps the last characters of long secret only, so it could be told apart from another one
+func MaskSecret(secret string) string {
+
+	if len(secret) < 16 {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
+
+func IsKnownEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (r *SubscriptionRepository) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(r.db, &Subscription{})
+	if err != nil {
+		return fmt.Errorf("migration of WebhookSubscription table failed: %v", err)
+	}
+	return nil
+}
+
+// WithDB returns repository which works through d, e.g. inside of transaction
+func (r *SubscriptionRepository) WithDB(d db.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: d}
+}
+
+func SubscriptionSetup(d db.DB) (*SubscriptionRepository, error) {
+
+	repo := &SubscriptionRepository{}
+
+	repo.db = d
+
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"fmt"
+	"github.com/proviant-io/core/internal/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+type bucketModel struct {
+	Key        string  `gorm:"primaryKey;size:191"`
+	Tokens     float64 `gorm:"not null"`
+	RefilledAt int64   `gorm:"not null"`
+}
+
+func (bucketModel) TableName() string {
+	return "rate_limit_buckets"
+}
+
+// DbLimiter keeps buckets in shared database, so limits are applied across all replicas
+type DbLimiter struct {
+	db db.DB
+}
+
+func (l *DbLimiter) Allow(keys []string, limit Limit) (bool, time.Duration, error) {
+
+	if limit.Unlimited() {
+		return true, 0, nil
+	}
+
+	// rows are locked in same order by every replica, so concurrent requests cannot deadlock
+	keys = append([]string{}, keys...)
+	sort.Strings(keys)
+
+	var allowed bool
+	var wait time.Duration
+
+	err := l.db.Connection().Transaction(func(tx *gorm.DB) error {
+
+		now := time.Now()
+
+		buckets := make([]bucket, len(keys))
+		exists := make([]bool, len(keys))
+
+		for i, key := range keys {
+			model := bucketModel{}
+			result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", key).Limit(1).Find(&model)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			buckets[i] = newBucket(limit, now)
+			exists[i] = result.RowsAffected > 0
+
+			if exists[i] {
+				buckets[i] = bucket{
+					Tokens:     model.Tokens,
+					RefilledAt: time.Unix(0, model.RefilledAt),
+				}
+			}
+		}
+
+		buckets, allowed, wait = takeAll(buckets, limit, now)
+
+		for i, key := range keys {
+			model := bucketModel{
+				Key:        key,
+				Tokens:     buckets[i].Tokens,
+				RefilledAt: buckets[i].RefilledAt.UnixNano(),
+			}
+
+			if exists[i] {
+				err := tx.Model(&bucketModel{}).Where("`key` = ?", key).Updates(map[string]interface{}{
+					"tokens":      model.Tokens,
+					"refilled_at": model.RefilledAt,
+				}).Error
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			// concurrent replica could insert same key, in this case its bucket wins
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return true, 0, err
+	}
+
+	return allowed, wait, nil
+}
+
+func (l *DbLimiter) Migrate() error {
+	// Migrate the schema
+	err := db.AutoMigrate(l.db, &bucketModel{})
+	if err != nil {
+		return fmt.Errorf("migration of RateLimitBucket table failed: %v", err)
+	}
+	return nil
+}
+
+func NewDbLimiter(d db.DB) (Limiter, error) {
+
+	limiter := &DbLimiter{
+		db: d,
+	}
+
+	err := limiter.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return limiter, nil
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// buckets idle for longer than pruneAfter are full again and could be dropped
+const pruneAfter = time.Hour
+const pruneThreshold = 10000
+
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]bucket
+	now     func() time.Time
+}
+
+func (l *MemoryLimiter) Allow(keys []string, limit Limit) (bool, time.Duration, error) {
+
+	if limit.Unlimited() {
+		return true, 0, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	buckets := make([]bucket, len(keys))
+
+	for i, key := range keys {
+		b, ok := l.buckets[key]
+		if !ok {
+			b = newBucket(limit, now)
+		}
+		buckets[i] = b
+	}
+
+	buckets, allowed, wait := takeAll(buckets, limit, now)
+
+	for i, key := range keys {
+		l.buckets[key] = buckets[i]
+	}
+
+	if len(l.buckets) > pruneThreshold {
+		l.prune(now)
+	}
+
+	return allowed, wait, nil
+}
+
+func (l *MemoryLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.RefilledAt) > pruneAfter {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func NewMemoryLimiter() Limiter {
+	return &MemoryLimiter{
+		buckets: map[string]bucket{},
+		now:     time.Now,
+	}
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+const BackendMemory = "memory"
+const BackendDb = "db"
+
+// Limit describes token bucket: Rate tokens are added every second up to Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+type Limiter interface {
+	// Allow takes one token from bucket of every key, either from all of them or, when any bucket is empty,
+	// from none. Then it returns false and duration after which every bucket has token again.
+	Allow(keys []string, limit Limit) (bool, time.Duration, error)
+}
+
+type bucket struct {
+	Tokens     float64
+	RefilledAt time.Time
+}
+
+func newBucket(limit Limit, now time.Time) bucket {
+	return bucket{
+		Tokens:     float64(limit.Burst),
+		RefilledAt: now,
+	}
+}
+
+// refill adds tokens for time passed since last refill, up to burst
+func refill(b bucket, limit Limit, now time.Time) bucket {
+
+	elapsed := now.Sub(b.RefilledAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed*limit.Rate)
+	b.RefilledAt = now
+
+	return b
+}
+
+// untilToken is duration after which refilled bucket has single token
+func untilToken(b bucket, limit Limit) time.Duration {
+
+	if b.Tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.Tokens) / limit.Rate * float64(time.Second))
+}
+
+// takeAll refills buckets and consumes single token from each of them only when every bucket has one,
+// so request denied for one identity does not spend budget of others
+func takeAll(buckets []bucket, limit Limit, now time.Time) ([]bucket, bool, time.Duration) {
+
+	var longest time.Duration
+
+	for i := range buckets {
+		buckets[i] = refill(buckets[i], limit, now)
+
+		if w := untilToken(buckets[i], limit); w > longest {
+			longest = w
+		}
+	}
+
+	if longest > 0 {
+		return buckets, false, longest
+	}
+
+	for i := range buckets {
+		buckets[i].Tokens--
+	}
+
+	return buckets, true, 0
+}
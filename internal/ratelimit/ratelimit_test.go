@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"github.com/proviant-io/core/internal/db"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+
+	limit := Limit{Rate: 2, Burst: 2}
+	now := time.Unix(1000, 0)
+
+	buckets := []bucket{newBucket(limit, now)}
+
+	buckets, allowed, _ := takeAll(buckets, limit, now)
+	assert.True(t, allowed)
+
+	buckets, allowed, _ = takeAll(buckets, limit, now)
+	assert.True(t, allowed)
+
+	buckets, allowed, wait := takeAll(buckets, limit, now)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// half a second later one token is refilled
+	buckets, allowed, _ = takeAll(buckets, limit, now.Add(500*time.Millisecond))
+	assert.True(t, allowed)
+
+	// refill never exceeds burst
+	buckets, _, _ = takeAll(buckets, limit, now.Add(time.Hour))
+	assert.Equal(t, float64(1), buckets[0].Tokens)
+}
+
+func TestMemoryLimiter(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	l := &MemoryLimiter{
+		buckets: map[string]bucket{},
+		now: func() time.Time {
+			return now
+		},
+	}
+
+	limit := Limit{Rate: 1, Burst: 1}
+
+	allowed, _, err := l.Allow([]string{"account:1"}, limit)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, wait, err := l.Allow([]string{"account:1"}, limit)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, wait)
+
+	// buckets are independent per key
+	allowed, _, err = l.Allow([]string{"account:2"}, limit)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, err = l.Allow([]string{"account:1"}, Limit{})
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	// account:3 is not charged, as account:1 is out of budget
+	allowed, _, err = l.Allow([]string{"account:3", "account:1"}, limit)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, _, err = l.Allow([]string{"account:3"}, limit)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestTakeAll(t *testing.T) {
+
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Unix(1000, 0)
+
+	full := newBucket(limit, now)
+	empty := bucket{Tokens: 0.5, RefilledAt: now}
+
+	buckets, allowed, wait := takeAll([]bucket{full, empty}, limit, now)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, wait)
+	// bucket which has token keeps it, when other one denies
+	assert.Equal(t, float64(2), buckets[0].Tokens)
+
+	buckets, allowed, _ = takeAll(buckets, limit, now.Add(time.Second))
+	assert.True(t, allowed)
+	assert.Equal(t, float64(1), buckets[0].Tokens)
+	assert.Equal(t, 0.5, buckets[1].Tokens)
+}
+
+func TestDbLimiter(t *testing.T) {
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "rate_limit.sqlite"))
+	assert.NoError(t, err)
+
+	l, err := NewDbLimiter(d)
+	assert.NoError(t, err)
+
+	limit := Limit{Rate: 0.001, Burst: 1}
+
+	allowed, _, err := l.Allow([]string{"token:a", "ip:1"}, limit)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	// ip is out of budget, so token of other client is not spent
+	allowed, wait, err := l.Allow([]string{"token:b", "ip:1"}, limit)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.True(t, wait > 0)
+
+	allowed, _, err = l.Allow([]string{"token:b", "ip:2"}, limit)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatch applies JSON Merge Patch (RFC 7396) to target document:
+// members of patch replace members of target, null removes member, nested objects are merged recursively
+func MergePatch(target, patch []byte) ([]byte, error) {
+
+	patchValue, err := decodeJSON(patch)
+	if err != nil {
+		return nil, fmt.Errorf("patch is not valid json: %v", err)
+	}
+
+	if _, ok := patchValue.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("patch should be json object")
+	}
+
+	targetValue, err := decodeJSON(target)
+	if err != nil {
+		return nil, fmt.Errorf("target is not valid json: %v", err)
+	}
+
+	return json.Marshal(mergeValue(targetValue, patchValue))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+
+		targetObject[name] = mergeValue(targetObject[name], value)
+	}
+
+	return targetObject
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep numbers as they are, prices should not lose precision
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+
+	return value, err
+}
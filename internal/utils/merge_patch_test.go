@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+
+	// examples from RFC 7396 appendix A
+	cases := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"price":"10.25","stock":12345678901234567890}`, `{"title":"Milk"}`, `{"price":"10.25","stock":12345678901234567890,"title":"Milk"}`},
+	}
+
+	for _, c := range cases {
+		result, err := MergePatch([]byte(c.target), []byte(c.patch))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, c.result, string(result), c.patch)
+	}
+}
+
+func TestMergePatchRejectsNonObject(t *testing.T) {
+
+	_, err := MergePatch([]byte(`{"a":"b"}`), []byte(`["c"]`))
+	assert.Error(t, err)
+
+	_, err = MergePatch([]byte(`{"a":"b"}`), []byte(`{"a":`))
+	assert.Error(t, err)
+}
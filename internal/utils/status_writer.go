@@ -0,0 +1,29 @@
+package utils
+
+import "net/http"
+
+// StatusWriter remembers status code of response. Flush keeps event streams working through it
+// and Unwrap lets http.ResponseController reach writer it wraps.
+type StatusWriter struct {
+	http.ResponseWriter
+	Status int
+}
+
+func NewStatusWriter(w http.ResponseWriter) *StatusWriter {
+	return &StatusWriter{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (sw *StatusWriter) WriteHeader(status int) {
+	sw.Status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *StatusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sw *StatusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
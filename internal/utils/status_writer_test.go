@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusWriter(t *testing.T) {
+
+	rec := httptest.NewRecorder()
+
+	sw := NewStatusWriter(rec)
+	assert.Equal(t, http.StatusOK, sw.Status)
+
+	sw.WriteHeader(http.StatusCreated)
+	sw.Flush()
+
+	assert.Equal(t, http.StatusCreated, sw.Status)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, rec, sw.Unwrap())
+}
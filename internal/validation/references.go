@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/category"
+	"github.com/proviant-io/core/internal/pkg/list"
+	"github.com/proviant-io/core/internal/pkg/media"
+	"github.com/proviant-io/core/internal/pkg/product"
+	"net/http"
+	"reflect"
+)
+
+// registerReference adds rule of referenced entity, api v1 answered with not found when it was missing
+func (v *Validator) registerReference(name string, rule Rule) {
+	v.Register(name, rule)
+	v.v1Codes[name] = http.StatusNotFound
+}
+
+// RegisterReferences adds rules which need storage, they check referenced entities exist in account
+func (v *Validator) RegisterReferences(listRepo *list.Repository, categoryRepo *category.Repository, productRepo *product.Repository, mediaRepo *media.Repository) {
+
+	v.registerReference("list", func(field string, value reflect.Value, _ string, accountId int) *i18n.Message {
+		if _, err := listRepo.Get(int(value.Int()), accountId); err != nil {
+			m := err.Message()
+			return &m
+		}
+		return nil
+	})
+
+	v.registerReference("categories", func(field string, value reflect.Value, _ string, accountId int) *i18n.Message {
+		for i := 0; i < value.Len(); i++ {
+			if _, err := categoryRepo.Get(int(value.Index(i).Int()), accountId); err != nil {
+				m := err.Message()
+				return &m
+			}
+		}
+		return nil
+	})
+
+	v.registerReference("product", func(field string, value reflect.Value, _ string, accountId int) *i18n.Message {
+		if _, err := productRepo.Get(int(value.Int()), accountId); err != nil {
+			m := err.Message()
+			return &m
+		}
+		return nil
+	})
+
+	v.registerReference("media", func(field string, value reflect.Value, _ string, accountId int) *i18n.Message {
+		if _, err := mediaRepo.Get(int(value.Int()), accountId); err != nil {
+			m := err.Message()
+			return &m
+		}
+		return nil
+	})
+
+	v.registerReference("media_list", func(field string, value reflect.Value, _ string, accountId int) *i18n.Message {
+		seen := map[int]bool{}
+		for i := 0; i < value.Len(); i++ {
+			id := int(value.Index(i).Int())
+			if seen[id] {
+				m := i18n.NewMessage("media %d is listed more than once", id)
+				return &m
+			}
+			seen[id] = true
+			if _, err := mediaRepo.Get(id, accountId); err != nil {
+				m := err.Message()
+				return &m
+			}
+		}
+		return nil
+	})
+}
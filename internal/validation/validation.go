@@ -0,0 +1,228 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/proviant-io/core/internal/pkg/image"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tag holds comma separated rules of field, rule parameter follows "=", e.g. `validate:"required,max=255"`
+const Tag = "validate"
+
+// Rule checks value of single field, param is part of rule after "=". It returns nil when value is valid.
+type Rule func(field string, value reflect.Value, param string, accountId int) *i18n.Message
+
+type Validator struct {
+	rules map[string]Rule
+	// v1Codes are statuses api v1 answers with when rule fails, see errors.FieldError
+	v1Codes map[string]int
+}
+
+func (v *Validator) Register(name string, rule Rule) {
+	v.rules[name] = rule
+}
+
+// Validate checks every tagged field of dto, fields are reported by their json names
+func (v *Validator) Validate(dto interface{}, accountId int) []errors.FieldError {
+
+	value := reflect.Indirect(reflect.ValueOf(dto))
+
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: struct expected, got %s", value.Kind()))
+	}
+
+	var fieldErrors []errors.FieldError
+
+	for i := 0; i < value.NumField(); i++ {
+		fieldType := value.Type().Field(i)
+		tag := fieldType.Tag.Get(Tag)
+
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		field := fieldName(fieldType)
+		fieldValue := value.Field(i)
+
+		for _, rule := range strings.Split(tag, ",") {
+			name, param := rule, ""
+
+			if pos := strings.Index(rule, "="); pos != -1 {
+				name, param = rule[:pos], rule[pos+1:]
+			}
+
+			// optional fields are checked only when they are set
+			if name == "omitempty" {
+				if fieldValue.IsZero() {
+					break
+				}
+				continue
+			}
+
+			check, ok := v.rules[name]
+
+			if !ok {
+				panic(fmt.Sprintf("validation: unknown rule %s of field %s", name, fieldType.Name))
+			}
+
+			if message := check(field, fieldValue, param, accountId); message != nil {
+				fieldErrors = append(fieldErrors, errors.FieldError{Field: field, Message: *message, V1Code: v.v1Codes[name]})
+				// the first failed rule is enough, the following ones usually fail because of it
+				break
+			}
+		}
+	}
+
+	return fieldErrors
+}
+
+func fieldName(field reflect.StructField) string {
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func required(field string, value reflect.Value, _ string, _ int) *i18n.Message {
+
+	switch value.Kind() {
+	case reflect.String:
+		if strings.TrimSpace(value.String()) == "" {
+			return message("%s should not be empty", field)
+		}
+	case reflect.Slice, reflect.Map:
+		if value.Len() == 0 {
+			return message("%s should not be empty", field)
+		}
+	default:
+		if value.IsZero() {
+			return message("%s should not be 0", field)
+		}
+	}
+
+	return nil
+}
+
+func minRule(field string, value reflect.Value, param string, _ int) *i18n.Message {
+
+	limit, err := strconv.ParseInt(param, 10, 64)
+
+	if err != nil {
+		panic(fmt.Sprintf("validation: min of %s should be integer", field))
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Int() < limit {
+			return message("%s should be at least %d", field, limit)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if limit > 0 && value.Uint() < uint64(limit) {
+			return message("%s should be at least %d", field, limit)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			if m := minRule(field, value.Index(i), param, 0); m != nil {
+				return m
+			}
+		}
+	default:
+		panic(fmt.Sprintf("validation: min is not supported for %s", value.Kind()))
+	}
+
+	return nil
+}
+
+func maxRule(field string, value reflect.Value, param string, _ int) *i18n.Message {
+
+	limit, err := strconv.ParseInt(param, 10, 64)
+
+	if err != nil {
+		panic(fmt.Sprintf("validation: max of %s should be integer", field))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		if int64(utf8.RuneCountInString(value.String())) > limit {
+			return message("%s should not be longer than %d characters", field, limit)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Int() > limit {
+			return message("%s should be at most %d", field, limit)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > uint64(limit) {
+			return message("%s should be at most %d", field, limit)
+		}
+	default:
+		panic(fmt.Sprintf("validation: max is not supported for %s", value.Kind()))
+	}
+
+	return nil
+}
+
+// nonnegative supports numbers and types like decimal.Decimal which are able to tell their sign
+func nonnegative(field string, value reflect.Value, _ string, _ int) *i18n.Message {
+
+	if signed, ok := value.Interface().(interface{ IsNegative() bool }); ok {
+		if signed.IsNegative() {
+			return message("%s should not be negative", field)
+		}
+		return nil
+	}
+
+	return minRule(field, value, "0", 0)
+}
+
+func absoluteUrl(field string, value reflect.Value, _ string, _ int) *i18n.Message {
+
+	u, err := url.Parse(value.String())
+
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return message("%s should be absolute http(s) url", field)
+	}
+
+	return nil
+}
+
+// dataUrl accepts image encoded as base64 data url, e.g. data:image/png;base64,...
+func dataUrl(field string, value reflect.Value, _ string, _ int) *i18n.Message {
+
+	if _, _, err := image.ParseDataUrl(value.String()); err != nil {
+		return message("%s should be base64 data url, e.g. data:image/png;base64,...", field)
+	}
+
+	return nil
+}
+
+func message(template string, params ...interface{}) *i18n.Message {
+	m := i18n.NewMessage(template, params...)
+	return &m
+}
+
+func New() *Validator {
+
+	v := &Validator{
+		rules:   map[string]Rule{},
+		v1Codes: map[string]int{},
+	}
+
+	v.Register("required", required)
+	v.Register("min", minRule)
+	v.Register("max", maxRule)
+	v.Register("nonnegative", nonnegative)
+	v.Register("url", absoluteUrl)
+	v.Register("data_url", dataUrl)
+
+	return v
+}
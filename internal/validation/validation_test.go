@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"github.com/proviant-io/core/internal/errors"
+	"github.com/proviant-io/core/internal/i18n"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type testDTO struct {
+	Title     string          `json:"title" validate:"required,max=5"`
+	Quantity  uint            `json:"quantity" validate:"required"`
+	ListId    int             `json:"list_id" validate:"min=1,list"`
+	ProductId int             `json:"product_id" validate:"omitempty,min=1"`
+	Price     decimal.Decimal `json:"price" validate:"nonnegative"`
+	Link      string          `json:"link" validate:"omitempty,url"`
+	Ignored   int             `json:"ignored"`
+}
+
+func validator() *Validator {
+
+	v := New()
+
+	v.registerReference("list", func(field string, value reflect.Value, _ string, accountId int) *i18n.Message {
+		if value.Int() != 1 || accountId != 7 {
+			return message("list with id %d not found", value.Int())
+		}
+		return nil
+	})
+
+	return v
+}
+
+func TestValidateValid(t *testing.T) {
+
+	fields := validator().Validate(testDTO{
+		Title:    "Milk",
+		Quantity: 1,
+		ListId:   1,
+		Price:    decimal.NewFromFloat(1.5),
+		Link:     "https://example.com/milk",
+	}, 7)
+
+	assert.Empty(t, fields)
+}
+
+func TestValidateInvalid(t *testing.T) {
+
+	fields := validator().Validate(&testDTO{
+		Title:     "  ",
+		ListId:    2,
+		ProductId: -1,
+		Price:     decimal.NewFromFloat(-1),
+		Link:      "example.com",
+	}, 7)
+
+	assert.Equal(t, []errors.FieldError{
+		{Field: "title", Message: i18n.NewMessage("%s should not be empty", "title")},
+		{Field: "quantity", Message: i18n.NewMessage("%s should not be 0", "quantity")},
+		{Field: "list_id", Message: i18n.NewMessage("list with id %d not found", int64(2)), V1Code: 404},
+		{Field: "product_id", Message: i18n.NewMessage("%s should be at least %d", "product_id", int64(1))},
+		{Field: "price", Message: i18n.NewMessage("%s should not be negative", "price")},
+		{Field: "link", Message: i18n.NewMessage("%s should be absolute http(s) url", "link")},
+	}, fields)
+}
+
+func TestValidateStopsAtFirstFailedRule(t *testing.T) {
+
+	fields := validator().Validate(testDTO{Title: "Milk", Quantity: 1, ListId: 0}, 7)
+
+	assert.Equal(t, []errors.FieldError{
+		{Field: "list_id", Message: i18n.NewMessage("%s should be at least %d", "list_id", int64(1))},
+	}, fields)
+}
+
+func TestValidateMax(t *testing.T) {
+
+	fields := validator().Validate(testDTO{Title: "Молоко", Quantity: 1, ListId: 1}, 7)
+
+	assert.Equal(t, []errors.FieldError{
+		{Field: "title", Message: i18n.NewMessage("%s should not be longer than %d characters", "title", int64(5))},
+	}, fields)
+}
+
+func TestValidateDataUrl(t *testing.T) {
+
+	type imageDTO struct {
+		ImageBase64 string `json:"image_base64" validate:"omitempty,data_url"`
+	}
+
+	assert.Empty(t, validator().Validate(imageDTO{}, 7))
+	assert.Empty(t, validator().Validate(imageDTO{ImageBase64: "data:image/png;base64,iVBORw0KGgo="}, 7))
+
+	assert.Equal(t, []errors.FieldError{
+		{Field: "image_base64", Message: i18n.NewMessage("%s should be base64 data url, e.g. data:image/png;base64,...", "image_base64")},
+	}, validator().Validate(imageDTO{ImageBase64: "abc"}, 7))
+}